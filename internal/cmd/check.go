@@ -26,6 +26,9 @@ import (
 	"github.com/turbot/powerpipe/internal/controlstatus"
 	"github.com/turbot/powerpipe/internal/dashboardexecute"
 	"github.com/turbot/powerpipe/internal/display"
+	"github.com/turbot/powerpipe/internal/history"
+	"github.com/turbot/powerpipe/internal/notifier"
+	"github.com/turbot/powerpipe/internal/powerpipeconfig"
 	localqueryresult "github.com/turbot/powerpipe/internal/queryresult"
 	"github.com/turbot/powerpipe/internal/resources"
 	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
@@ -61,8 +64,10 @@ func checkCmd[T controlinit.CheckTarget]() *cobra.Command {
 		AddStringFlag(constants.ArgDatabase, "", "Turbot Pipes workspace database", localcmdconfig.Deprecated("see https://powerpipe.io/docs/run#selecting-a-database for the new syntax")).
 		AddBoolFlag(constants.ArgHeader, true, "Include column headers for csv and table output").
 		AddBoolFlag(constants.ArgHelp, false, "Help for run command", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddBoolFlag(localconstants.ArgHistory, false, "Record the run in the local run history").
 		AddBoolFlag(constants.ArgInput, true, "Enable interactive prompts").
 		AddBoolFlag(constants.ArgModInstall, true, "Specify whether to install mod dependencies before running").
+		AddStringSliceFlag(localconstants.ArgNotify, nil, "Notify the named webhook notifiers (defined in the config) when the run completes").
 		AddVarFlag(enumflag.New(&updateStrategy, constants.ArgPull, constants.ModUpdateStrategyIds, enumflag.EnumCaseInsensitive),
 			constants.ArgPull,
 			fmt.Sprintf("Update strategy; one of: %s", strings.Join(constants.FlagValues(constants.ModUpdateStrategyIds), ", "))).
@@ -81,9 +86,11 @@ func checkCmd[T controlinit.CheckTarget]() *cobra.Command {
 			constants.ArgOutput,
 			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.CheckOutputModeIds), ", "))).
 		AddStringFlag(constants.ArgSeparator, ",", "Separator string for csv output").
+		AddStringFlag(localconstants.ArgBaseline, "", "Compare the results with a previous run (a JSON export or snapshot) and show the drift").
+		AddStringFlag(localconstants.ArgExceptions, "", "An exceptions file (HCL or YAML) - alarms which match an unexpired exception are reported as suppressed").
 		AddStringFlag(constants.ArgSnapshotLocation, "", "The location to write snapshots - either a local file path or a Turbot Pipes workspace").
 		AddStringFlag(constants.ArgSnapshotTitle, "", "The title to give a snapshot").
-		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported formats: csv, html, json, md, nunit3, pps (snapshot), asff").
+		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported formats: csv, html, json, junit, md, nunit3, openmetrics, pps (snapshot), asff, sarif").
 		AddStringSliceFlag(constants.ArgSearchPath, nil, "Set a custom search_path (comma-separated)").
 		AddStringSliceFlag(constants.ArgSearchPathPrefix, nil, "Set a prefix to the current search path (comma-separated)").
 		AddIntFlag(constants.ArgBenchmarkTimeout, 0, "Set the benchmark execution timeout")
@@ -96,6 +103,7 @@ func checkCmd[T controlinit.CheckTarget]() *cobra.Command {
 		builder.
 			AddStringFlag(constants.ArgWhere, "", "SQL 'where' clause, or named query, used to filter controls (cannot be used with '--tag')").
 			AddBoolFlag(constants.ArgDryRun, false, "Show which controls will be run without running them").
+			AddStringFlag(localconstants.ArgSeverity, "", "Filter the detections of a detection benchmark based on their severity ('--severity >=high')").
 			AddStringSliceFlag(constants.ArgTag, nil, "Filter controls based on their tag values ('--tag key=value')").
 			AddIntFlag(constants.ArgMaxParallel, constants.DefaultMaxConnections, "The maximum number of concurrent database connections to open")
 	}
@@ -116,7 +124,8 @@ You may specify one or more %ss to run, separated by a space.`, typeName, typeNa
 }
 
 // exitCode=0 no runtime errors, no control alarms or errors
-// exitCode=1 no runtime errors, 1 or more control alarms, no control errors
+// exitCode=1 no runtime errors, 1 or more control alarms (or new alarms, if a baseline is used), no control errors
+// (alarms which are suppressed by an exception do not count towards the exit code)
 // exitCode=2 no runtime errors, 1 or more control errors
 // exitCode=3+ runtime errors
 
@@ -171,13 +180,27 @@ func runCheckCmd[T controlinit.CheckTarget](cmd *cobra.Command, args []string) {
 	// TODO TACTICAL
 	// ifd the target is a detection benchmark, we need to run the detection benchmark using detectionRunWithInitData
 	if _, ok := initData.Targets[0].(*resources.DetectionBenchmark); ok {
-		if !viper.IsSet(constants.ArgOutput) {
+		// detection benchmarks support a different set of flags to control benchmarks, so must be validated separately
+		if err := validateDetectionArgs(ctx); err != nil {
+			exitCode = constants.ExitCodeInsufficientOrWrongInputs
+			error_helpers.ShowError(ctx, err)
+			return
+		}
+		// default to snapshot output, unless this is a dry run (which only supports text output)
+		if !viper.IsSet(constants.ArgOutput) && !viper.GetBool(constants.ArgDryRun) {
 			viper.Set(constants.ArgOutput, constants.OutputFormatSnapshot)
 		}
 		detectionRunWithInitData[*resources.DetectionBenchmark](cmd, initData, args)
 		return
 	}
 
+	// controls do not support severity filtering
+	if viper.IsSet(localconstants.ArgSeverity) {
+		exitCode = constants.ExitCodeInsufficientOrWrongInputs
+		error_helpers.ShowError(ctx, fmt.Errorf("'--%s' is only supported for detection benchmarks", localconstants.ArgSeverity))
+		return
+	}
+
 	// hide the spinner so that warning messages can be shown
 	statushooks.Done(ctx)
 
@@ -198,7 +221,7 @@ func runCheckCmd[T controlinit.CheckTarget](cmd *cobra.Command, args []string) {
 
 	for _, namedTree := range trees {
 		// execute controls synchronously (execute returns the number of alarms and errors)
-		executionErr := executeTree(ctx, namedTree.tree, initData)
+		executionErr := executeTree(ctx, namedTree, initData)
 		if executionErr != nil {
 			totalErrors++
 			error_helpers.ShowError(ctx, executionErr)
@@ -212,6 +235,10 @@ func runCheckCmd[T controlinit.CheckTarget](cmd *cobra.Command, args []string) {
 		if namedTree.tree.Root != nil && namedTree.tree.Root.Summary != nil {
 			totalAlarms = namedTree.tree.Root.Summary.Status.Alarm
 			totalErrors = namedTree.tree.Root.Summary.Status.Error
+			// if comparing with a baseline, only new alarms count towards the exit code
+			if initData.Baseline != nil {
+				totalAlarms = controldisplay.NewDriftReport(namedTree.tree, initData.Baseline).Summary.NewAlarms
+			}
 		}
 
 		err = publishSnapshot(ctx, namedTree.tree, viper.GetBool(constants.ArgShare), viper.GetBool(constants.ArgSnapshot))
@@ -257,9 +284,10 @@ func exportExecutionTree(ctx context.Context, namedTree *namedExecutionTree, ini
 }
 
 // executeTree executes and displays the (table) results of an execution
-func executeTree(ctx context.Context, tree *controlexecute.ExecutionTree, initData *controlinit.InitData) error {
+func executeTree(ctx context.Context, namedTree *namedExecutionTree, initData *controlinit.InitData) error {
+	tree := namedTree.tree
 	// create a context with check status hooks
-	checkCtx, cancel := createCheckContext(ctx)
+	checkCtx, cancel := createCheckContext(ctx, namedTree.name, initData)
 	defer cancel()
 
 	err := tree.Execute(checkCtx)
@@ -308,6 +336,7 @@ func getExecutionTrees(ctx context.Context, initData *controlinit.InitData) ([]*
 		if err != nil {
 			return nil, sperr.WrapWithMessage(err, "could not create merged execution tree")
 		}
+		executionTree.Exceptions = initData.Exceptions
 		name := fmt.Sprintf("check.%s", initData.Workspace.Mod.ShortName)
 		trees = append(trees, newNamedExecutionTree(name, executionTree))
 	} else {
@@ -320,6 +349,7 @@ func getExecutionTrees(ctx context.Context, initData *controlinit.InitData) ([]*
 			if err != nil {
 				return nil, sperr.WrapWithMessage(err, "could not create execution tree for %s", target)
 			}
+			executionTree.Exceptions = initData.Exceptions
 
 			trees = append(trees, newNamedExecutionTree(target.Name(), executionTree))
 		}
@@ -343,7 +373,9 @@ func getExitCode(alarms int, errors int) int {
 }
 
 // create the context for the check run - add a control status renderer
-func createCheckContext(ctx context.Context) (context.Context, context.CancelFunc) {
+// (and, if history is enabled, hooks to record the run in the history store)
+// (and, if notifiers are specified, hooks to notify them when the run completes)
+func createCheckContext(ctx context.Context, target string, initData *controlinit.InitData) (context.Context, context.CancelFunc) {
 	var cancel context.CancelFunc
 	// if a dashboard timeout was specified, use that
 	if executionTimeout := viper.GetInt(constants.ArgBenchmarkTimeout); executionTimeout > 0 {
@@ -352,7 +384,16 @@ func createCheckContext(ctx context.Context) (context.Context, context.CancelFun
 		ctx, cancel = context.WithCancel(ctx)
 
 	}
-	ctx = controlstatus.AddControlHooksToContext(ctx, controlstatus.NewStatusControlHooks())
+	var hooks controlstatus.ControlHooks = controlstatus.NewStatusControlHooks()
+	if viper.GetBool(localconstants.ArgHistory) && !viper.GetBool(constants.ArgDryRun) {
+		hooks = controlstatus.NewMultiControlHooks(hooks, history.NewHistoryControlHooks(target, initData.Workspace.VariableValues))
+	}
+	if notifiers := viper.GetStringSlice(localconstants.ArgNotify); len(notifiers) > 0 && !viper.GetBool(constants.ArgDryRun) {
+		webhooks, err := notifier.GetWebhooks(powerpipeconfig.GlobalConfig, notifiers)
+		error_helpers.FailOnError(err)
+		hooks = controlstatus.NewMultiControlHooks(hooks, notifier.NewNotifierControlHooks(target, webhooks))
+	}
+	ctx = controlstatus.AddControlHooksToContext(ctx, hooks)
 	return ctx, cancel
 }
 
@@ -381,6 +422,10 @@ func validateCheckArgs(ctx context.Context) error {
 		return fmt.Errorf("only 1 of '--%s' and '--%s' may be set", constants.ArgWhere, constants.ArgTag)
 	}
 
+	if err := localcmdconfig.ValidateNotifyArg(); err != nil {
+		return err
+	}
+
 	return localcmdconfig.ValidateDatabaseArg()
 }
 
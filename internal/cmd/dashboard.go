@@ -27,8 +27,11 @@ import (
 	localconstants "github.com/turbot/powerpipe/internal/constants"
 	"github.com/turbot/powerpipe/internal/controlstatus"
 	"github.com/turbot/powerpipe/internal/dashboardexecute"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+	"github.com/turbot/powerpipe/internal/display"
 	"github.com/turbot/powerpipe/internal/initialisation"
 	"github.com/turbot/powerpipe/internal/resources"
+	"github.com/turbot/powerpipe/internal/snapshot"
 	"github.com/turbot/steampipe-plugin-sdk/v5/logging"
 )
 
@@ -54,7 +57,7 @@ The current mod is the working directory, or the directory specified by the --mo
 		AddCloudFlags().
 		AddModLocationFlag().
 		AddStringArrayFlag(constants.ArgArg, nil, "Specify the value of a dashboard argument").
-		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported format: pps (snapshot)").
+		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported formats: html, pps (snapshot)").
 		AddStringFlag(constants.ArgDatabase, "", "Turbot Pipes workspace database", localcmdconfig.Deprecated("see https://powerpipe.io/docs/run#selecting-a-database for the new syntax")).
 		AddIntFlag(constants.ArgDatabaseQueryTimeout, localconstants.DatabaseDefaultQueryTimeout, "The query timeout").
 		AddBoolFlag(constants.ArgHelp, false, "Help for dashboard", cmdconfig.FlagOptions.WithShortHand("h")).
@@ -77,11 +80,13 @@ The current mod is the working directory, or the directory specified by the --mo
 		// Cobra will interpret values passed to a StringSliceFlag as CSV, where args passed to StringArrayFlag are not parsed and used raw
 		AddStringArrayFlag(constants.ArgSnapshotTag, nil, "Specify tags to set on the snapshot").
 		AddStringFlag(constants.ArgSnapshotLocation, "", "The location to write snapshots - either a local file path or a Turbot Pipes workspace").
+		AddBoolFlag(constants.ArgTiming, false, "Display the execution time of each dashboard panel").
 		// NOTE: use StringArrayFlag for ArgVariable, not StringSliceFlag
 		// Cobra will interpret values passed to a StringSliceFlag as CSV, where args passed to StringArrayFlag are not parsed and used raw
 		AddStringArrayFlag(constants.ArgVariable, nil, "Specify the value of a variable").
 		AddStringSliceFlag(constants.ArgVarFile, nil, "Specify an .ppvar file containing variable values").
-		AddIntFlag(constants.ArgDashboardTimeout, 0, "Set the dashboard execution timeout")
+		AddIntFlag(constants.ArgDashboardTimeout, 0, "Set the dashboard execution timeout").
+		AddIntFlag(localconstants.ArgDashboardMaxRows, 0, "The maximum number of rows returned by a dashboard panel query - further rows are truncated (0 for no limit)")
 
 	return cmd
 }
@@ -146,7 +151,11 @@ func dashboardRun(cmd *cobra.Command, args []string) {
 	snap, err := dashboardexecute.GenerateSnapshot(ctx, initData.Workspace, target, inputs)
 	error_helpers.FailOnError(err)
 	// display the snapshot result (if needed)
-	displaySnapshot(snap)
+	displaySnapshot(ctx, snap)
+	// display the panel timing (if needed)
+	if viper.GetBool(constants.ArgTiming) {
+		error_helpers.FailOnError(displayDashboardTiming(snap))
+	}
 
 	// upload the snapshot (if needed)
 	err = publishSnapshotIfNeeded(ctx, snap)
@@ -188,19 +197,45 @@ func validateDashboardArgs(ctx context.Context) error {
 	return localcmdconfig.ValidateDatabaseArg()
 }
 
-func displaySnapshot(snapshot *steampipeconfig.SteampipeSnapshot) {
+func displaySnapshot(ctx context.Context, snap *steampipeconfig.SteampipeSnapshot) {
 	switch viper.GetString(constants.ArgOutput) {
 	case constants.OutputFormatSnapshot, constants.OutputFormatPowerpipeSnapshotShort:
 		// just display result
-		snapshotText, err := json.MarshalIndent(snapshot, "", "  ")
+		snapshotText, err := json.MarshalIndent(snap, "", "  ")
 		error_helpers.FailOnError(err)
 		//nolint:forbidigo // Intentional UI output
 		fmt.Println(string(snapshotText))
+	case constants.OutputFormatHTML:
+		err := snapshot.RenderHtml(os.Stdout, snap)
+		error_helpers.FailOnError(err)
+	case constants.OutputFormatText:
+		var b strings.Builder
+		err := snapshot.RenderText(&b, snap)
+		error_helpers.FailOnError(err)
+		display.ShowPaged(ctx, b.String())
+	}
+}
+
+// displayDashboardTiming prints the timing of the dashboard panels, slowest first
+// unless the output is text (or none) this is written to stderr, so the output may still be parsed
+func displayDashboardTiming(snap *steampipeconfig.SteampipeSnapshot) error {
+	timings := make(map[string]*dashboardtypes.PanelTiming)
+	for name, panel := range snap.Panels {
+		if leafRun, ok := panel.(*dashboardexecute.LeafRun); ok && leafRun.Timing != nil {
+			timings[name] = leafRun.Timing
+		}
+	}
+
+	w := os.Stderr
+	switch viper.GetString(constants.ArgOutput) {
+	case constants.OutputFormatText, constants.OutputFormatNone:
+		w = os.Stdout
 	}
+	return display.PrintDashboardTiming(w, timings)
 }
 
 func dashboardExporters() []export.Exporter {
-	return []export.Exporter{&export.SnapshotExporter{}}
+	return []export.Exporter{&export.SnapshotExporter{}, &snapshot.HtmlExporter{}}
 }
 
 func publishSnapshotIfNeeded(ctx context.Context, snapshot *steampipeconfig.SteampipeSnapshot) error {
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -16,12 +17,17 @@ import (
 	"github.com/turbot/pipe-fittings/v2/error_helpers"
 	"github.com/turbot/pipe-fittings/v2/modconfig"
 	"github.com/turbot/pipe-fittings/v2/statushooks"
+	"github.com/turbot/pipe-fittings/v2/utils"
 	"github.com/turbot/pipe-fittings/v2/workspace"
 	localcmdconfig "github.com/turbot/powerpipe/internal/cmdconfig"
 	localconstants "github.com/turbot/powerpipe/internal/constants"
 	"github.com/turbot/powerpipe/internal/controldisplay"
 	"github.com/turbot/powerpipe/internal/controlinit"
 	"github.com/turbot/powerpipe/internal/dashboardexecute"
+	"github.com/turbot/powerpipe/internal/detectionstate"
+	"github.com/turbot/powerpipe/internal/history"
+	"github.com/turbot/powerpipe/internal/notifier"
+	"github.com/turbot/powerpipe/internal/powerpipeconfig"
 	"github.com/turbot/powerpipe/internal/resources"
 	"github.com/turbot/steampipe-plugin-sdk/v5/logging"
 )
@@ -49,19 +55,26 @@ func detectionRunCmd[T DetectionTarget]() *cobra.Command {
 	// when running mod install before the detection execution, we use the minimal update strategy
 	var updateStrategy = constants.ModUpdateIdMinimal
 
-	cmdconfig.OnCmd(cmd).
+	builder := cmdconfig.OnCmd(cmd)
+	builder.
 		AddCloudFlags().
 		AddModLocationFlag().
 		AddStringArrayFlag(constants.ArgArg, nil, "Specify the value of a detection argument").
 		AddBoolFlag(constants.ArgHeader, true, "Include column headers for csv and table output").
 		AddStringFlag(constants.ArgSeparator, ",", "Separator string for csv output").
-		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported format: json").
+		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported formats: csv, html, json, md, pps (snapshot)").
 		AddStringFlag(constants.ArgDatabase, "", "Turbot Pipes workspace database", localcmdconfig.Deprecated("see https://powerpipe.io/docs/run#selecting-a-database for the new syntax")).
 		AddIntFlag(constants.ArgDatabaseQueryTimeout, localconstants.DatabaseDefaultQueryTimeout, "The query timeout").
+		AddBoolFlag(localconstants.ArgDedupe, false, "Only report results whose fingerprint has not been seen by a previous run").
+		AddStringFlag(constants.ArgFrom, "", "Start of the time range to evaluate: a timestamp, date or duration before now (e.g. 2024-05-01T12:00:00Z, 2024-05-01 or 24h)").
 		AddBoolFlag(constants.ArgHelp, false, "Help for detection", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddBoolFlag(localconstants.ArgHistory, false, "Record the run in the local run history").
+		AddBoolFlag(localconstants.ArgIncremental, false, "Only evaluate data since the previous incremental run of each detection").
+		AddStringFlag(localconstants.ArgIncrementalLag, "5m", "For an incremental run, the time allowed for events to arrive late - data more recent than this is evaluated by the next run").
 		AddBoolFlag(constants.ArgInput, true, "Enable interactive prompts").
 		AddIntFlag(constants.ArgMaxParallel, constants.DefaultMaxConnections, "The maximum number of concurrent database connections to open").
 		AddBoolFlag(constants.ArgModInstall, true, "Specify whether to install mod dependencies before running the detection").
+		AddStringSliceFlag(localconstants.ArgNotify, nil, "Notify the named webhook notifiers (defined in the config) when the run completes").
 		AddVarFlag(enumflag.New(&updateStrategy, constants.ArgPull, constants.ModUpdateStrategyIds, enumflag.EnumCaseInsensitive),
 			constants.ArgPull,
 			fmt.Sprintf("Update strategy; one of: %s", strings.Join(constants.FlagValues(constants.ModUpdateStrategyIds), ", "))).
@@ -69,9 +82,12 @@ func detectionRunCmd[T DetectionTarget]() *cobra.Command {
 			constants.ArgOutput,
 			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.DetectionOutputModeIds), ", "))).
 		AddBoolFlag(constants.ArgProgress, true, "Display detection execution progress respected when a detection name argument is passed").
+		AddStringFlag(localconstants.ArgSince, "", fmt.Sprintf("Evaluate the time range ending now with the given duration (e.g. 24h or 7d) or preset; one of: %s", strings.Join(localcmdconfig.TimeRangePresetNames(), ", "))).
+		AddBoolFlag(localconstants.ArgResetState, false, "Clear the incremental and dedupe state of the detections (requires --incremental or --dedupe)").
 		AddBoolFlag(constants.ArgSnapshot, false, "Create snapshot in Turbot Pipes with the default (workspace) visibility").
 		AddBoolFlag(constants.ArgShare, false, "Create snapshot in Turbot Pipes with 'anyone_with_link' visibility").
 		AddStringFlag(constants.ArgSnapshotTitle, "", "The title to give a snapshot").
+		AddStringFlag(constants.ArgTo, "", "End of the time range to evaluate: a timestamp, date or duration before now").
 		// NOTE: use StringArrayFlag for ArgDetectionInput, not StringSliceFlag
 		// Cobra will interpret values passed to a StringSliceFlag as CSV, where args passed to StringArrayFlag are not parsed and used raw
 		AddStringArrayFlag(constants.ArgSnapshotTag, nil, "Specify tags to set on the snapshot").
@@ -82,6 +98,16 @@ func detectionRunCmd[T DetectionTarget]() *cobra.Command {
 		AddStringSliceFlag(constants.ArgVarFile, nil, "Specify an .ppvar file containing variable values").
 		AddIntFlag(constants.ArgDetectionTimeout, 0, "Set the detection execution timeout")
 
+	// for detection benchmark command, add the detection filter flags
+	// (detection benchmarks run using the benchmark command are filtered using the equivalent check command flags)
+	var empty T
+	if _, ok := any(empty).(*resources.DetectionBenchmark); ok {
+		builder.
+			AddBoolFlag(constants.ArgDryRun, false, "Show which detections will be run without running them").
+			AddStringFlag(localconstants.ArgSeverity, "", fmt.Sprintf("Filter detections based on their severity, optionally preceded by a comparison operator ('--severity >=high'); one of: %s", strings.Join(localconstants.Severities, ", "))).
+			AddStringSliceFlag(constants.ArgTag, nil, "Filter detections based on their tag values ('--tag key=value')")
+	}
+
 	return cmd
 }
 
@@ -148,6 +174,35 @@ func detectionRunWithInitData[T DetectionTarget](cmd *cobra.Command, initData *c
 	error_helpers.FailOnError(err)
 
 	inputs := dashboardexecute.NewInputValues()
+	inputs.DateTimeRange, err = localcmdconfig.ResolveTimeRangeArgs(time.Now())
+	error_helpers.FailOnError(err)
+
+	// if a tag or severity filter has been passed, prune the detections which do not match
+	inputs.DetectionFilter, err = dashboardexecute.NewDetectionFilter(viper.GetStringSlice(constants.ArgTag), viper.GetString(localconstants.ArgSeverity))
+	error_helpers.FailOnError(err)
+	skippedDetections := inputs.DetectionFilter.SkippedDetections(target)
+
+	isDryRun := viper.GetBool(constants.ArgDryRun)
+	inputs.DryRun = isDryRun
+
+	// if this is an incremental or dedupe run, load the high-water marks and fingerprints of the detections
+	var state *detectionstate.State
+	// (a dry run does not run the detections, so the state is neither used nor updated)
+	if (viper.GetBool(localconstants.ArgIncremental) || viper.GetBool(localconstants.ArgDedupe)) && !isDryRun {
+		state, err = detectionstate.Load()
+		error_helpers.FailOnError(err)
+		if viper.GetBool(localconstants.ArgResetState) {
+			state.Reset(detectionNames(target)...)
+		}
+		if viper.GetBool(localconstants.ArgIncremental) {
+			inputs.DetectionState = state
+			inputs.IncrementalLag, err = localcmdconfig.ResolveIncrementalLagArg()
+			error_helpers.FailOnError(err)
+		}
+		if viper.GetBool(localconstants.ArgDedupe) {
+			inputs.FingerprintState = state
+		}
+	}
 
 	snap, err := dashboardexecute.GenerateSnapshot(ctx, initData.Workspace, target, inputs)
 	error_helpers.FailOnError(err)
@@ -155,9 +210,31 @@ func detectionRunWithInitData[T DetectionTarget](cmd *cobra.Command, initData *c
 	tree, err := controldisplay.SnapshotToExecutionTree(ctx, snap, initData.Workspace, target)
 	error_helpers.FailOnError(err)
 
+	// record the run in the history store (if needed)
+	if viper.GetBool(localconstants.ArgHistory) && !isDryRun {
+		run := history.NewDetectionRun(tree, target.Name(), inputs.Inputs, initData.Workspace.VariableValues)
+		if err := history.SaveRun(ctx, run); err != nil {
+			error_helpers.ShowWarning(fmt.Sprintf("failed to save run history: %s", err.Error()))
+		}
+	}
+
+	// notify any webhook notifiers (if needed)
+	notified := true
+	if notifiers := viper.GetStringSlice(localconstants.ArgNotify); len(notifiers) > 0 && !isDryRun {
+		webhooks, err := notifier.GetWebhooks(powerpipeconfig.GlobalConfig, notifiers)
+		error_helpers.FailOnError(err)
+		if err := notifier.NotifyDetection(ctx, webhooks, tree, target.Name()); err != nil {
+			error_helpers.ShowWarning(fmt.Sprintf("failed to send notification: %s", err.Error()))
+			notified = false
+		}
+	}
+
 	err = displayDetectionResults(ctx, tree, initData.OutputFormatter)
 	error_helpers.FailOnError(err)
 
+	// report the detections which were pruned by the filter
+	displaySkippedDetections(skippedDetections)
+
 	// display the snapshot result (if needed)
 	//displaySnapshot(snap)
 
@@ -173,6 +250,18 @@ func detectionRunWithInitData[T DetectionTarget](cmd *cobra.Command, initData *c
 	exportMsg, err := initData.ExportManager.DoExport(ctx, snap.FileNameRoot, tree, exportArgs)
 	error_helpers.FailOnErrorWithMessage(err, "failed to export snapshot")
 
+	// save the updated high-water marks and fingerprints
+	// this is only done once the results have been output, exported and notified,
+	// so if any of these fail the results are reported again by the next run
+	if state != nil {
+		if notified {
+			state.PruneFingerprints(time.Now().Add(-detectionstate.FingerprintRetention))
+			error_helpers.FailOnErrorWithMessage(state.Save(), "failed to save detection state")
+		} else {
+			error_helpers.ShowWarning("detection state was not saved as notification failed - the results will be reported again by the next run")
+		}
+	}
+
 	// print the location where the file is exported
 	if len(exportMsg) > 0 && viper.GetBool(constants.ArgProgress) {
 		//nolint:forbidigo // Intentional UI output
@@ -198,9 +287,75 @@ func validateDetectionArgs(ctx context.Context) error {
 		return fmt.Errorf("only one of --share or --snapshot may be set")
 	}
 
+	if _, err := localcmdconfig.ResolveTimeRangeArgs(time.Now()); err != nil {
+		return err
+	}
+
+	if _, err := localcmdconfig.ResolveIncrementalLagArg(); err != nil {
+		return err
+	}
+
+	if viper.GetBool(localconstants.ArgResetState) && !viper.GetBool(localconstants.ArgIncremental) && !viper.GetBool(localconstants.ArgDedupe) {
+		return fmt.Errorf("--%s may only be used with --%s or --%s", localconstants.ArgResetState, localconstants.ArgIncremental, localconstants.ArgDedupe)
+	}
+
+	// a dry run does not run the detections, so only the text output (which shows the detections which would be run)
+	// is supported - other output formats and exports would look like a run with no results
+	if viper.GetBool(constants.ArgDryRun) {
+		if output := viper.GetString(constants.ArgOutput); output != constants.OutputFormatText {
+			return fmt.Errorf("'--%s' only supports text output, got '--%s %s'", constants.ArgDryRun, constants.ArgOutput, output)
+		}
+		for _, arg := range []string{constants.ArgExport, constants.ArgSnapshot, constants.ArgShare} {
+			if viper.IsSet(arg) {
+				return fmt.Errorf("'--%s' cannot be used with '--%s'", arg, constants.ArgDryRun)
+			}
+		}
+	}
+
+	// detections can only be filtered by tag or severity
+	if viper.IsSet(constants.ArgWhere) {
+		return fmt.Errorf("'--%s' is not supported for detection benchmarks - use '--%s' or '--%s'", constants.ArgWhere, constants.ArgTag, localconstants.ArgSeverity)
+	}
+
+	if severity := viper.GetString(localconstants.ArgSeverity); severity != "" {
+		if _, err := dashboardexecute.ParseSeverityFilter(severity); err != nil {
+			return err
+		}
+	}
+
+	if err := localcmdconfig.ValidateNotifyArg(); err != nil {
+		return err
+	}
+
 	return localcmdconfig.ValidateDatabaseArg()
 }
 
+// displaySkippedDetections lists the detections which were not run as they did not match the --tag or --severity filter
+// unless the output is text, this is written to stderr, so the output may still be parsed
+func displaySkippedDetections(skipped []string) {
+	if len(skipped) == 0 {
+		return
+	}
+	w := os.Stderr
+	if viper.GetString(constants.ArgOutput) == constants.OutputFormatText {
+		w = os.Stdout
+	}
+	//nolint:forbidigo // Intentional UI output
+	fmt.Fprintf(w, "\nSkipped %d %s not matching the filter:\n  %s\n", len(skipped), utils.Pluralize("detection", len(skipped)), strings.Join(skipped, "\n  "))
+}
+
+// detectionNames returns the names of the target detection, or of all detections within the target benchmark
+func detectionNames(item modconfig.ModTreeItem) []string {
+	if detection, ok := item.(*resources.Detection); ok {
+		return []string{detection.Name()}
+	}
+	var res []string
+	for _, child := range item.GetChildren() {
+		res = append(res, detectionNames(child)...)
+	}
+	return res
+}
+
 func setExitCodeForDetectionError(err error) {
 	// if exit code already set, leave as is
 	if exitCode != 0 || err == nil {
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thediveo/enumflag/v2"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/v2/cmdconfig"
+	"github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/pipe-fittings/v2/error_helpers"
+	"github.com/turbot/pipe-fittings/v2/printers"
+	"github.com/turbot/pipe-fittings/v2/sanitize"
+	"github.com/turbot/pipe-fittings/v2/utils"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/display"
+	"github.com/turbot/powerpipe/internal/history"
+)
+
+// variable used to assign the output mode flag for history export
+var historyExportMode = localconstants.HistoryExportModeJSON
+
+func historyCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "history [command]",
+		Args:  cobra.NoArgs,
+		Short: "Powerpipe run history",
+		Long: `Powerpipe run history.
+
+Benchmark, control and detection runs are recorded in the local run history
+when the --history flag (or POWERPIPE_HISTORY environment variable) is set.
+The most recent 1000 runs from the last 90 days are retained.
+
+Examples:
+
+    # Record a benchmark run in the history
+    powerpipe benchmark run cis_v300 --history
+
+    # List recent runs
+    powerpipe history list
+
+    # Show a run and its results
+    powerpipe history show run_cn5ra0mhsbdnhnu0lgt0
+
+    # Export the results of a run as CSV
+    powerpipe history export run_cn5ra0mhsbdnhnu0lgt0 --output csv
+	`,
+	}
+	cmd.AddCommand(historyListCmd(),
+		historyShowCmd(),
+		historyExportCmd(),
+	)
+
+	cmd.Flags().BoolP("help", "h", false, "Help for history")
+
+	return cmd
+}
+
+func historyListCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "list [target]",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runHistoryListCmd,
+		Short: "List recorded runs",
+		Long: `List recorded runs, most recent first.
+
+Optionally, specify a benchmark, control or detection name to only list runs of that target.`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for list", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddIntFlag(localconstants.ArgLimit, 50, "The maximum number of runs to list").
+		AddVarFlag(enumflag.New(&outputMode, constants.ArgOutput, localconstants.OutputModeIds, enumflag.EnumCaseInsensitive),
+			constants.ArgOutput,
+			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.OutputModeIds), ", ")))
+	return cmd
+}
+
+func historyShowCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "show [run id]",
+		Args:  cobra.ExactArgs(1),
+		Run:   runHistoryShowCmd,
+		Short: "Show a recorded run",
+		Long:  `Show a recorded run, including the results of each control or detection.`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for show", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddVarFlag(enumflag.New(&outputMode, constants.ArgOutput, localconstants.OutputModeIds, enumflag.EnumCaseInsensitive),
+			constants.ArgOutput,
+			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.OutputModeIds), ", ")))
+	return cmd
+}
+
+func historyExportCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "export [run id]",
+		Args:  cobra.ExactArgs(1),
+		Run:   runHistoryExportCmd,
+		Short: "Export the results of a recorded run",
+		Long:  `Export the results of a recorded run, including all result rows, to stdout.`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for export", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddBoolFlag(constants.ArgHeader, true, "Include column headers for csv output").
+		AddStringFlag(constants.ArgSeparator, ",", "Separator string for csv output").
+		AddVarFlag(enumflag.New(&historyExportMode, constants.ArgOutput, localconstants.HistoryExportModeIds, enumflag.EnumCaseInsensitive),
+			constants.ArgOutput,
+			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.HistoryExportModeIds), ", ")))
+	return cmd
+}
+
+func runHistoryListCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runHistoryListCmd")
+	defer func() {
+		utils.LogTime("cmd.runHistoryListCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	runs := []*history.Run{}
+	// if there is no history store, there are no runs - do not create an empty store
+	if history.StoreExists() {
+		store, err := history.OpenStore(ctx)
+		error_helpers.FailOnError(err)
+		defer store.Close()
+
+		var target string
+		if len(args) > 0 {
+			target = args[0]
+		}
+		runs, err = store.List(ctx, target, viper.GetInt(localconstants.ArgLimit))
+		error_helpers.FailOnErrorWithMessage(err, "failed to list runs")
+	}
+
+	printer, err := printers.GetPrinter[*history.Run](cmd)
+	error_helpers.FailOnErrorWithMessage(err, "failed obtaining printer")
+
+	err = printer.PrintResource(ctx, display.NewPrintableRuns(runs), cmd.OutOrStdout())
+	error_helpers.FailOnErrorWithMessage(err, "failed when printing")
+}
+
+func runHistoryShowCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runHistoryShowCmd")
+	defer func() {
+		utils.LogTime("cmd.runHistoryShowCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	run := getHistoryRun(cmd, args[0])
+
+	printer, err := printers.GetPrinter[*history.Run](cmd)
+	error_helpers.FailOnErrorWithMessage(err, "failed obtaining printer")
+
+	err = printer.PrintResource(ctx, display.NewPrintableRuns([]*history.Run{run}), cmd.OutOrStdout())
+	error_helpers.FailOnErrorWithMessage(err, "failed when printing")
+}
+
+func runHistoryExportCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runHistoryExportCmd")
+	defer func() {
+		utils.LogTime("cmd.runHistoryExportCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	// only 1 character is allowed for '--separator'
+	separator := []rune(viper.GetString(constants.ArgSeparator))
+	if len(separator) != 1 {
+		error_helpers.FailOnError(fmt.Errorf("'--%s' must be a single character", constants.ArgSeparator))
+	}
+
+	run := getHistoryRun(cmd, args[0])
+
+	switch viper.GetString(constants.ArgOutput) {
+	case constants.OutputFormatCSV:
+		err := run.WriteCSV(cmd.OutOrStdout(), separator[0], viper.GetBool(constants.ArgHeader))
+		error_helpers.FailOnErrorWithMessage(err, "failed to export run")
+	default:
+		s, err := json.MarshalIndent(run, "", "  ")
+		error_helpers.FailOnErrorWithMessage(err, "failed to export run")
+		//nolint:forbidigo // we want to print
+		fmt.Fprintln(cmd.OutOrStdout(), sanitize.Instance.SanitizeString(string(s)))
+	}
+}
+
+// getHistoryRun loads the run with the given id from the history store, failing if it does not exist
+func getHistoryRun(cmd *cobra.Command, id string) *history.Run {
+	ctx := cmd.Context()
+	if !history.StoreExists() {
+		error_helpers.FailOnError(fmt.Errorf("no run history found - use --history to record runs"))
+	}
+	store, err := history.OpenStore(ctx)
+	error_helpers.FailOnError(err)
+	defer store.Close()
+
+	run, err := store.Get(ctx, id)
+	if errors.Is(err, history.ErrRunNotFound) {
+		error_helpers.FailOnError(fmt.Errorf("run '%s' not found", id))
+	}
+	error_helpers.FailOnErrorWithMessage(err, "failed to load run")
+	return run
+}
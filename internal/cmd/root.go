@@ -54,6 +54,8 @@ func rootCommand() *cobra.Command {
 		serverCmd(),
 		modCmd(),
 		loginCmd(),
+		historyCmd(),
+		snapshotCmd(),
 		resourceCmd[*resources.Benchmark](),
 		resourceCmd[*resources.Detection](),
 		resourceCmd[*resources.Control](),
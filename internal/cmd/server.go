@@ -14,10 +14,14 @@ import (
 	"github.com/turbot/pipe-fittings/v2/utils"
 	localcmdconfig "github.com/turbot/powerpipe/internal/cmdconfig"
 	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/controldisplay"
 	"github.com/turbot/powerpipe/internal/dashboardassets"
 	"github.com/turbot/powerpipe/internal/dashboardserver"
 	"github.com/turbot/powerpipe/internal/initialisation"
+	"github.com/turbot/powerpipe/internal/powerpipeconfig"
 	"github.com/turbot/powerpipe/internal/resources"
+	"github.com/turbot/powerpipe/internal/scheduler"
+	"github.com/turbot/powerpipe/internal/serverauth"
 	"github.com/turbot/powerpipe/internal/service/api"
 	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
 	"gopkg.in/olahol/melody.v1"
@@ -45,7 +49,16 @@ Powerpipe server runs in the foreground; Press Ctrl-C to exit.`,
 		AddStringArrayFlag(constants.ArgVariable, []string{}, "Specify the value of a variable. Multiple --var arguments may be passed.").
 		AddStringFlag(constants.ArgVarFile, "", "Specify a .ppvar file containing variable values.").
 		AddStringFlag(constants.ArgDatabase, "", "Turbot Pipes workspace database", localcmdconfig.Deprecated("see https://powerpipe.io/docs/run#selecting-a-database for the new syntax")).
-		AddIntFlag(constants.ArgDashboardTimeout, 0, "Set a the dashboard execution timeout")
+		AddIntFlag(constants.ArgDashboardTimeout, 0, "Set a the dashboard execution timeout").
+		AddIntFlag(localconstants.ArgDashboardMaxRows, 0, "The maximum number of rows returned by a dashboard panel query - further rows are truncated (0 for no limit)").
+		AddIntFlag(localconstants.ArgQueryCacheTtl, 0, "Cache dashboard query results for this many seconds, shared across sessions (0 disables the cache unless a resource sets cache_ttl)").
+		AddBoolFlag(constants.ArgHeader, true, "Include column headers for csv output returned by the API").
+		AddStringFlag(constants.ArgSeparator, ",", "Separator string for csv output returned by the API").
+		AddStringFlag(localconstants.ArgAuthConfig, "", "An auth config file (HCL or YAML) defining the tokens, users and trusted proxy used to authenticate requests").
+		AddStringFlag(localconstants.ArgTlsCert, "", "A TLS certificate file - if set with --tls-key, the server uses HTTPS. The certificate is reloaded when the file changes").
+		AddStringFlag(localconstants.ArgTlsKey, "", "The private key file of the TLS certificate").
+		AddStringFlag(localconstants.ArgTlsClientCa, "", "A file containing the CA certificates used to verify client certificates - if set, clients must present a certificate").
+		AddIntFlag(localconstants.ArgTlsRedirectPort, 0, "If set, serve HTTP on this port, redirecting all requests to HTTPS")
 
 	return cmd
 }
@@ -75,6 +88,22 @@ func runServerCmd(cmd *cobra.Command, _ []string) {
 		error_helpers.FailOnError(sperr.New("Port %d is not available - is another instance of 'powerpipe server' running?\n       Set a different port using the --port argument", serverPort))
 	}
 
+	tlsConfig := serverTLSConfig()
+	if tlsConfig != nil && tlsConfig.RedirectPort != 0 {
+		if err := utils.IsPortBindable(serverHost, tlsConfig.RedirectPort); err != nil {
+			exitCode = constants.ExitCodeBindPortUnavailable
+			error_helpers.FailOnError(sperr.New("Port %d is not available - set a different port using the --%s argument", tlsConfig.RedirectPort, localconstants.ArgTlsRedirectPort))
+		}
+	}
+
+	// if an auth config is specified, all requests must be authenticated
+	var authenticator *serverauth.Authenticator
+	if authConfigPath := viper.GetString(localconstants.ArgAuthConfig); authConfigPath != "" {
+		authConfig, err := serverauth.Load(authConfigPath)
+		error_helpers.FailOnError(err)
+		authenticator = serverauth.NewAuthenticator(authConfig)
+	}
+
 	// initialise the workspace
 	modInitData := initialisation.NewInitData[*resources.Dashboard](ctx, cmd)
 	error_helpers.FailOnError(modInitData.Result.Error)
@@ -84,23 +113,40 @@ func runServerCmd(cmd *cobra.Command, _ []string) {
 	err := dashboardassets.Ensure(ctx)
 	error_helpers.FailOnError(err)
 
+	// ensure the output templates used to format benchmark and control runs started using the API
+	error_helpers.FailOnError(controldisplay.EnsureControlTemplates())
+
 	// setup a new webSocket service
 	webSocket := melody.New()
 	// create the dashboardServer
 	dashboardServer, err := dashboardserver.NewServer(ctx, modInitData, webSocket)
 	error_helpers.FailOnError(err)
 
-	// send it over to the powerpipe API Server
-	powerpipeService, err := api.NewAPIService(ctx,
+	apiOpts := []api.APIServiceOption{
 		api.WithWebSocket(webSocket),
 		api.WithWorkspace(modInitData.Workspace),
+		api.WithDefaultClient(modInitData.DefaultClient),
 		api.WithHTTPPortAndListenConfig(serverPort, serverListen),
-	)
+	}
+	if authenticator != nil {
+		apiOpts = append(apiOpts, api.WithAuthenticator(authenticator))
+	}
+	if tlsConfig != nil {
+		apiOpts = append(apiOpts, api.WithTLSConfig(tlsConfig))
+	}
+
+	// send it over to the powerpipe API Server
+	powerpipeService, err := api.NewAPIService(ctx, apiOpts...)
 	if err != nil {
 		error_helpers.FailOnError(err)
 	}
 	dashboardServer.InitAsync(ctx)
 
+	// start any schedules defined in the config
+	dashboardScheduler, err := scheduler.NewScheduler(modInitData.Workspace, powerpipeconfig.GlobalConfig.Schedules)
+	error_helpers.FailOnError(err)
+	dashboardScheduler.Start(ctx)
+
 	//start the API server
 	err = powerpipeService.Start()
 	if err != nil {
@@ -108,12 +154,48 @@ func runServerCmd(cmd *cobra.Command, _ []string) {
 	}
 
 	dashboardserver.OutputReady(ctx, fmt.Sprintf("Dashboard server started on %d and listening on %s", serverPort, viper.GetString(constants.ArgListen)))
-	dashboardserver.OutputMessage(ctx, fmt.Sprintf("Visit http://localhost:%d", serverPort))
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+	dashboardserver.OutputMessage(ctx, fmt.Sprintf("Visit %s://localhost:%d", scheme, serverPort))
 	dashboardserver.OutputMessage(ctx, "Press Ctrl+C to exit")
 
 	<-ctx.Done()
 }
 
 func validateServerArgs() error {
-	return localcmdconfig.ValidateDatabaseArg()
+	if err := localcmdconfig.ValidateDatabaseArg(); err != nil {
+		return err
+	}
+
+	tlsConfig := serverTLSConfig()
+	if tlsConfig == nil {
+		if viper.GetString(localconstants.ArgTlsKey) != "" || viper.GetString(localconstants.ArgTlsClientCa) != "" || viper.GetInt(localconstants.ArgTlsRedirectPort) != 0 {
+			return sperr.New("--%s must be set to use TLS", localconstants.ArgTlsCert)
+		}
+		return nil
+	}
+	if tlsConfig.RedirectPort != 0 {
+		if err := dashboardserver.ListenPort(tlsConfig.RedirectPort).IsValid(); err != nil {
+			return sperr.WrapWithMessage(err, "invalid --%s", localconstants.ArgTlsRedirectPort)
+		}
+		if tlsConfig.RedirectPort == viper.GetInt(constants.ArgPort) {
+			return sperr.New("--%s must be different to --%s", localconstants.ArgTlsRedirectPort, constants.ArgPort)
+		}
+	}
+	return tlsConfig.Validate()
+}
+
+// serverTLSConfig returns the TLS config of the server, or nil if no TLS certificate is specified
+func serverTLSConfig() *api.TLSConfig {
+	if viper.GetString(localconstants.ArgTlsCert) == "" {
+		return nil
+	}
+	return &api.TLSConfig{
+		CertFile:     viper.GetString(localconstants.ArgTlsCert),
+		KeyFile:      viper.GetString(localconstants.ArgTlsKey),
+		ClientCAFile: viper.GetString(localconstants.ArgTlsClientCa),
+		RedirectPort: viper.GetInt(localconstants.ArgTlsRedirectPort),
+	}
 }
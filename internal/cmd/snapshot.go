@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/thediveo/enumflag/v2"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/v2/cmdconfig"
+	"github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/pipe-fittings/v2/error_helpers"
+	"github.com/turbot/pipe-fittings/v2/schema"
+	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+	"github.com/turbot/pipe-fittings/v2/utils"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/controldisplay"
+	"github.com/turbot/powerpipe/internal/controlexecute"
+	"github.com/turbot/powerpipe/internal/resources"
+	"github.com/turbot/powerpipe/internal/snapshot"
+)
+
+// variables used to assign the output mode flags of the snapshot commands
+var snapshotConvertMode = localconstants.SnapshotConvertModeJSON
+var snapshotDiffMode = localconstants.SnapshotDiffModeText
+
+func snapshotCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "snapshot [command]",
+		Args:  cobra.NoArgs,
+		Short: "Work with snapshot files",
+		Long: `Work with snapshot (.pps) files.
+
+Snapshots are created by running a dashboard or benchmark with --export pps or --output pps.
+
+Examples:
+
+    # Show the panels and data of a snapshot
+    powerpipe snapshot show cis_v300.pps
+
+    # Compare two snapshots of the same benchmark
+    powerpipe snapshot diff cis_v300_jan.pps cis_v300_feb.pps
+
+    # Convert a benchmark snapshot to CSV
+    powerpipe snapshot convert cis_v300.pps --output csv
+
+    # Combine the snapshots of a benchmark run against several accounts
+    powerpipe snapshot merge cis_v300_prod.pps cis_v300_dev.pps > cis_v300.pps
+	`,
+	}
+	cmd.AddCommand(snapshotShowCmd(),
+		snapshotDiffCmd(),
+		snapshotConvertCmd(),
+		snapshotMergeCmd(),
+	)
+
+	cmd.Flags().BoolP("help", "h", false, "Help for snapshot")
+
+	return cmd
+}
+
+func snapshotShowCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "show [file]",
+		Args:  cobra.ExactArgs(1),
+		Run:   runSnapshotShowCmd,
+		Short: "Show the panels of a snapshot",
+		Long:  `Show the panel tree of a snapshot, including the data of each panel.`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for show", cmdconfig.FlagOptions.WithShortHand("h"))
+	return cmd
+}
+
+func snapshotDiffCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "diff [from file] [to file]",
+		Args:  cobra.ExactArgs(2),
+		Run:   runSnapshotDiffCmd,
+		Short: "Compare two snapshots",
+		Long: `Compare two snapshots of the same dashboard or benchmark, panel by panel.
+
+Panels which were added, removed or whose results changed are listed. For controls, the
+status of each resource is compared.`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for diff", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddVarFlag(enumflag.New(&snapshotDiffMode, constants.ArgOutput, localconstants.SnapshotDiffModeIds, enumflag.EnumCaseInsensitive),
+			constants.ArgOutput,
+			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.SnapshotDiffModeIds), ", ")))
+	return cmd
+}
+
+func snapshotConvertCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "convert [file]",
+		Args:  cobra.ExactArgs(1),
+		Run:   runSnapshotConvertCmd,
+		Short: "Convert a snapshot to another format",
+		Long: `Convert a snapshot to another format, writing the result to stdout.
+
+Benchmark and control snapshots may be converted to csv, html, json or md, using the same
+formats as 'benchmark run'. Dashboard snapshots may be converted to html.`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for convert", cmdconfig.FlagOptions.WithShortHand("h")).
+		AddBoolFlag(constants.ArgHeader, true, "Include column headers for csv output").
+		AddStringFlag(constants.ArgSeparator, ",", "Separator string for csv output").
+		AddVarFlag(enumflag.New(&snapshotConvertMode, constants.ArgOutput, localconstants.SnapshotConvertModeIds, enumflag.EnumCaseInsensitive),
+			constants.ArgOutput,
+			fmt.Sprintf("Output format; one of: %s", strings.Join(constants.FlagValues(localconstants.SnapshotConvertModeIds), ", ")))
+	return cmd
+}
+
+func snapshotMergeCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "merge [file] [file]...",
+		Args:  cobra.MinimumNArgs(2),
+		Run:   runSnapshotMergeCmd,
+		Short: "Combine several snapshots",
+		Long: `Combine several snapshots into a single snapshot, writing the result to stdout.
+
+The snapshots must all be of the same dashboard, benchmark or control (for example, runs against
+different connections). The results of each control are combined.`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddBoolFlag(constants.ArgHelp, false, "Help for merge", cmdconfig.FlagOptions.WithShortHand("h"))
+	return cmd
+}
+
+func runSnapshotShowCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runSnapshotShowCmd")
+	defer func() {
+		utils.LogTime("cmd.runSnapshotShowCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	snap := loadSnapshotFile(args[0])
+	err := snapshot.Show(cmd.OutOrStdout(), snap)
+	error_helpers.FailOnErrorWithMessage(err, "failed to show snapshot")
+}
+
+func runSnapshotDiffCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runSnapshotDiffCmd")
+	defer func() {
+		utils.LogTime("cmd.runSnapshotDiffCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	diffs, err := snapshot.Diff(loadSnapshotFile(args[0]), loadSnapshotFile(args[1]))
+	error_helpers.FailOnError(err)
+
+	if viper.GetString(constants.ArgOutput) == constants.OutputFormatJSON {
+		if diffs == nil {
+			diffs = []*snapshot.PanelDiff{}
+		}
+		s, err := json.MarshalIndent(diffs, "", "  ")
+		error_helpers.FailOnError(err)
+		//nolint:forbidigo // we want to print
+		fmt.Fprintln(cmd.OutOrStdout(), string(s))
+		return
+	}
+	writeSnapshotDiff(cmd.OutOrStdout(), diffs)
+}
+
+func writeSnapshotDiff(w io.Writer, diffs []*snapshot.PanelDiff) {
+	if len(diffs) == 0 {
+		//nolint:forbidigo // we want to print
+		fmt.Fprintln(w, "No differences")
+		return
+	}
+	symbols := map[string]string{snapshot.PanelAdded: "+", snapshot.PanelRemoved: "-", snapshot.PanelModified: "~"}
+	for _, d := range diffs {
+		heading := fmt.Sprintf("%s %s: %s", symbols[d.Change], d.PanelType, d.Name)
+		if d.Title != "" {
+			heading += fmt.Sprintf(" (%s)", d.Title)
+		}
+		//nolint:forbidigo // we want to print
+		fmt.Fprintln(w, heading)
+		for _, detail := range d.Details {
+			//nolint:forbidigo // we want to print
+			fmt.Fprintf(w, "    %s\n", detail)
+		}
+	}
+}
+
+func runSnapshotConvertCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runSnapshotConvertCmd")
+	defer func() {
+		utils.LogTime("cmd.runSnapshotConvertCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	// only 1 character is allowed for '--separator'
+	if len(viper.GetString(constants.ArgSeparator)) != 1 {
+		error_helpers.FailOnError(fmt.Errorf("'--%s' must be a single character", constants.ArgSeparator))
+	}
+
+	snap := loadSnapshotFile(args[0])
+	output := viper.GetString(constants.ArgOutput)
+
+	// dashboard snapshots are rendered using the dashboard html renderer
+	if rootType := snap.Layout.NodeType; rootType != schema.BlockTypeBenchmark && rootType != schema.BlockTypeControl {
+		if output != constants.OutputFormatHTML {
+			error_helpers.FailOnError(fmt.Errorf("%s snapshots can only be converted to html", rootType))
+		}
+		err := snapshot.RenderHtml(cmd.OutOrStdout(), snap)
+		error_helpers.FailOnErrorWithMessage(err, "failed to convert snapshot")
+		return
+	}
+
+	tree, err := controlexecute.NewExecutionTreeFromSnapshot(snap)
+	error_helpers.FailOnErrorWithMessage(err, "failed to convert snapshot")
+
+	error_helpers.FailOnError(controldisplay.EnsureControlTemplates())
+	formatResolver, err := controldisplay.NewFormatResolver(&resources.Benchmark{})
+	error_helpers.FailOnError(err)
+	formatter, err := formatResolver.GetFormatter(output)
+	error_helpers.FailOnError(err)
+
+	reader, err := formatter.Format(ctx, tree)
+	error_helpers.FailOnErrorWithMessage(err, "failed to convert snapshot")
+	_, err = io.Copy(cmd.OutOrStdout(), reader)
+	error_helpers.FailOnErrorWithMessage(err, "failed to convert snapshot")
+}
+
+func runSnapshotMergeCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	utils.LogTime("cmd.runSnapshotMergeCmd")
+	defer func() {
+		utils.LogTime("cmd.runSnapshotMergeCmd end")
+		if r := recover(); r != nil {
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	snapshots := make([]*steampipeconfig.SteampipeSnapshot, len(args))
+	for i, path := range args {
+		snapshots[i] = loadSnapshotFile(path)
+	}
+	merged, err := snapshot.Merge(snapshots...)
+	error_helpers.FailOnErrorWithMessage(err, "failed to merge snapshots")
+
+	s, err := json.MarshalIndent(merged, "", "  ")
+	error_helpers.FailOnError(err)
+	//nolint:forbidigo // we want to print
+	fmt.Fprintln(cmd.OutOrStdout(), string(s))
+}
+
+// loadSnapshotFile loads the snapshot at the given path, failing if it cannot be loaded
+func loadSnapshotFile(path string) *steampipeconfig.SteampipeSnapshot {
+	snap, err := snapshot.Load(path)
+	error_helpers.FailOnError(err)
+	return snap
+}
@@ -77,5 +77,13 @@ func envMappings() map[string]cmdconfig.EnvMapping {
 		localconstants.EnvBenchmarkTimeout: {ConfigVar: []string{constants.ArgBenchmarkTimeout}, VarType: cmdconfig.EnvVarTypeInt},
 		localconstants.EnvDashboardTimeout: {ConfigVar: []string{constants.ArgDashboardTimeout}, VarType: cmdconfig.EnvVarTypeInt},
 		localconstants.EnvDisplayWidth:     {ConfigVar: []string{constants.ArgDisplayWidth}, VarType: cmdconfig.EnvVarTypeInt},
+		localconstants.EnvHistory:          {ConfigVar: []string{localconstants.ArgHistory}, VarType: cmdconfig.EnvVarTypeBool},
+		localconstants.EnvQueryCacheTtl:    {ConfigVar: []string{localconstants.ArgQueryCacheTtl}, VarType: cmdconfig.EnvVarTypeInt},
+		localconstants.EnvDashboardMaxRows: {ConfigVar: []string{localconstants.ArgDashboardMaxRows}, VarType: cmdconfig.EnvVarTypeInt},
+		localconstants.EnvAuthConfig:       {ConfigVar: []string{localconstants.ArgAuthConfig}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvTlsCert:          {ConfigVar: []string{localconstants.ArgTlsCert}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvTlsKey:           {ConfigVar: []string{localconstants.ArgTlsKey}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvTlsClientCa:      {ConfigVar: []string{localconstants.ArgTlsClientCa}, VarType: cmdconfig.EnvVarTypeString},
+		localconstants.EnvTlsRedirectPort:  {ConfigVar: []string{localconstants.ArgTlsRedirectPort}, VarType: cmdconfig.EnvVarTypeInt},
 	}
 }
@@ -0,0 +1,141 @@
+package cmdconfig
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/pipe-fittings/v2/utils"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+const day = 24 * time.Hour
+
+// TimeRangePresets are the named values which may be passed to --since
+var TimeRangePresets = map[string]time.Duration{
+	"last_hour":     time.Hour,
+	"last_24_hours": day,
+	"last_7_days":   7 * day,
+	"last_30_days":  30 * day,
+	"last_90_days":  90 * day,
+}
+
+// TimeRangePresetNames returns the sorted names of the --since presets
+func TimeRangePresetNames() []string {
+	names := make([]string, 0, len(TimeRangePresets))
+	for name := range TimeRangePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveTimeRangeArgs builds the time range from the --from, --to and --since args
+// if none are set, an empty time range is returned
+func ResolveTimeRangeArgs(now time.Time) (utils.TimeRange, error) {
+	var res utils.TimeRange
+	fromArg := viper.GetString(constants.ArgFrom)
+	toArg := viper.GetString(constants.ArgTo)
+	sinceArg := viper.GetString(localconstants.ArgSince)
+
+	if fromArg != "" && sinceArg != "" {
+		return res, fmt.Errorf("only one of --%s or --%s may be set", constants.ArgFrom, localconstants.ArgSince)
+	}
+	if fromArg != "" {
+		from, err := parseTimeArg(fromArg, now)
+		if err != nil {
+			return res, fmt.Errorf("invalid --%s: %w", constants.ArgFrom, err)
+		}
+		res.From = &from
+	}
+	if sinceArg != "" {
+		since, err := parseSinceArg(sinceArg)
+		if err != nil {
+			return res, fmt.Errorf("invalid --%s: %w", localconstants.ArgSince, err)
+		}
+		from := now.Add(-since)
+		res.From = &from
+	}
+	if toArg != "" {
+		to, err := parseTimeArg(toArg, now)
+		if err != nil {
+			return res, fmt.Errorf("invalid --%s: %w", constants.ArgTo, err)
+		}
+		res.To = &to
+	}
+	if res.From != nil && res.To != nil && !res.From.Before(*res.To) {
+		return res, fmt.Errorf("the start of the time range must be before the end")
+	}
+	return res, nil
+}
+
+// ResolveIncrementalLagArg parses the --incremental-lag arg, which may be zero or a duration (e.g. 5m or 1h)
+func ResolveIncrementalLagArg() (time.Duration, error) {
+	lagArg := viper.GetString(localconstants.ArgIncrementalLag)
+	if lagArg == "" || lagArg == "0" {
+		return 0, nil
+	}
+	lag, err := parseDuration(lagArg)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --%s: '%s' is not a duration (e.g. 5m or 1h)", localconstants.ArgIncrementalLag, lagArg)
+	}
+	return lag, nil
+}
+
+// parseTimeArg parses a time, which may be an RFC 3339 timestamp, a local date and time (2006-01-02T15:04:05),
+// a local date (2006-01-02) or a duration before now (e.g. 24h or 7d)
+func parseTimeArg(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if d, err := parseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("'%s' is not a timestamp (e.g. 2024-05-01T12:00:00Z), date (e.g. 2024-05-01) or duration (e.g. 24h or 7d)", value)
+}
+
+// parseSinceArg parses the --since arg, which may be a duration (e.g. 24h or 7d) or a named preset (e.g. last_7_days)
+func parseSinceArg(value string) (time.Duration, error) {
+	if d, ok := TimeRangePresets[value]; ok {
+		return d, nil
+	}
+	d, err := parseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a duration (e.g. 24h or 7d) or one of: %s", value, strings.Join(TimeRangePresetNames(), ", "))
+	}
+	return d, nil
+}
+
+// parseDuration parses a positive duration, supporting day (d) and week (w) units as well as the time.ParseDuration units
+func parseDuration(value string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	switch {
+	case strings.HasSuffix(value, "d"), strings.HasSuffix(value, "w"):
+		unit := day
+		if strings.HasSuffix(value, "w") {
+			unit = 7 * day
+		}
+		var n int
+		n, err = strconv.Atoi(value[:len(value)-1])
+		d = time.Duration(n) * unit
+	default:
+		d, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration must be positive")
+	}
+	return d, nil
+}
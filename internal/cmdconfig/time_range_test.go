@@ -0,0 +1,121 @@
+package cmdconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/v2/constants"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+)
+
+func TestResolveTimeRangeArgs(t *testing.T) {
+	now := time.Date(2024, 5, 8, 12, 0, 0, 0, time.UTC)
+	at := func(value string) *time.Time {
+		res, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &res
+	}
+
+	tests := map[string]struct {
+		from, to, since string
+		expectedFrom    *time.Time
+		expectedTo      *time.Time
+		expectErr       bool
+	}{
+		"none":                {},
+		"from and to":         {from: "2024-05-01T00:00:00Z", to: "2024-05-02T00:00:00Z", expectedFrom: at("2024-05-01T00:00:00Z"), expectedTo: at("2024-05-02T00:00:00Z")},
+		"relative from":       {from: "36h", expectedFrom: at("2024-05-07T00:00:00Z")},
+		"since duration":      {since: "24h", expectedFrom: at("2024-05-07T12:00:00Z")},
+		"since days":          {since: "7d", expectedFrom: at("2024-05-01T12:00:00Z")},
+		"since weeks":         {since: "1w", expectedFrom: at("2024-05-01T12:00:00Z")},
+		"since preset":        {since: "last_7_days", expectedFrom: at("2024-05-01T12:00:00Z")},
+		"since with to":       {since: "last_hour", to: "2024-05-08T11:30:00Z", expectedFrom: at("2024-05-08T11:00:00Z"), expectedTo: at("2024-05-08T11:30:00Z")},
+		"from and since":      {from: "2024-05-01T00:00:00Z", since: "24h", expectErr: true},
+		"from after to":       {from: "2024-05-02T00:00:00Z", to: "2024-05-01T00:00:00Z", expectErr: true},
+		"invalid from":        {from: "yesterday", expectErr: true},
+		"invalid since":       {since: "last_year", expectErr: true},
+		"negative since":      {since: "-24h", expectErr: true},
+		"zero since":          {since: "0d", expectErr: true},
+		"invalid since units": {since: "7x", expectErr: true},
+	}
+	for name, test := range tests {
+		viper.Set(constants.ArgFrom, test.from)
+		viper.Set(constants.ArgTo, test.to)
+		viper.Set(localconstants.ArgSince, test.since)
+
+		res, err := ResolveTimeRangeArgs(now)
+		if test.expectErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", name, err)
+			continue
+		}
+		if !timesEqual(res.From, test.expectedFrom) {
+			t.Errorf("%s: expected from %v, got %v", name, test.expectedFrom, res.From)
+		}
+		if !timesEqual(res.To, test.expectedTo) {
+			t.Errorf("%s: expected to %v, got %v", name, test.expectedTo, res.To)
+		}
+	}
+	viper.Reset()
+}
+
+func TestParseTimeArgDate(t *testing.T) {
+	res, err := parseTimeArg("2024-05-01", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	if !res.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, res)
+	}
+}
+
+func timesEqual(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+func TestResolveIncrementalLagArg(t *testing.T) {
+	tests := map[string]struct {
+		value     string
+		expected  time.Duration
+		expectErr bool
+	}{
+		"unset":    {},
+		"zero":     {value: "0"},
+		"minutes":  {value: "5m", expected: 5 * time.Minute},
+		"days":     {value: "1d", expected: 24 * time.Hour},
+		"negative": {value: "-5m", expectErr: true},
+		"invalid":  {value: "soon", expectErr: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			viper.Set(localconstants.ArgIncrementalLag, test.value)
+			defer viper.Reset()
+
+			lag, err := ResolveIncrementalLagArg()
+			if test.expectErr {
+				if err == nil {
+					t.Errorf("expected an error, got %v", lag)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lag != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, lag)
+			}
+		})
+	}
+}
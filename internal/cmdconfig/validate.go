@@ -12,6 +12,7 @@ import (
 	"github.com/turbot/pipe-fittings/v2/error_helpers"
 	"github.com/turbot/pipe-fittings/v2/pipes"
 	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
 	"github.com/turbot/powerpipe/internal/powerpipeconfig"
 )
 
@@ -53,6 +54,16 @@ func ValidateDatabaseArg() error {
 	return nil
 }
 
+// ValidateNotifyArg checks that the notifiers passed to the notify arg are defined in the config
+func ValidateNotifyArg() error {
+	for _, name := range viper.GetStringSlice(localconstants.ArgNotify) {
+		if _, ok := powerpipeconfig.GlobalConfig.Notifiers[name]; !ok {
+			return fmt.Errorf("notifier '%s' not found", name)
+		}
+	}
+	return nil
+}
+
 func ValidateSnapshotArgs(ctx context.Context) error {
 	// only 1 of 'share' and 'snapshot' may be set
 	share := viper.GetBool(constants.ArgShare)
@@ -0,0 +1,23 @@
+package constants
+
+// powerpipe specific argument names (shared arguments are defined in pipe-fittings)
+const (
+	ArgAuthConfig       = "auth-config"
+	ArgBaseline         = "baseline"
+	ArgDashboardMaxRows = "dashboard-max-rows"
+	ArgDedupe           = "dedupe"
+	ArgExceptions       = "exceptions"
+	ArgHistory          = "history"
+	ArgIncremental      = "incremental"
+	ArgIncrementalLag   = "incremental-lag"
+	ArgLimit            = "limit"
+	ArgNotify           = "notify"
+	ArgQueryCacheTtl    = "query-cache-ttl"
+	ArgResetState       = "reset-state"
+	ArgSeverity         = "severity"
+	ArgSince            = "since"
+	ArgTlsCert          = "tls-cert"
+	ArgTlsClientCa      = "tls-client-ca"
+	ArgTlsKey           = "tls-key"
+	ArgTlsRedirectPort  = "tls-redirect-port"
+)
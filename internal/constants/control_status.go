@@ -0,0 +1,5 @@
+package constants
+
+// ControlSuppressed is the status of a control result row which is an alarm, but matches an unexpired
+// exception (the other control statuses are defined in pipe-fittings)
+const ControlSuppressed = "suppressed"
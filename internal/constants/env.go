@@ -5,7 +5,15 @@ const (
 	EnvPort             = "POWERPIPE_PORT"
 	EnvBenchmarkTimeout = "POWERPIPE_BENCHMARK_TIMEOUT"
 	EnvDashboardTimeout = "POWERPIPE_DASHBOARD_TIMEOUT"
+	EnvDashboardMaxRows = "POWERPIPE_DASHBOARD_MAX_ROWS"
 	EnvDisplayWidth     = "POWERPIPE_DISPLAY_WIDTH"
+	EnvHistory          = "POWERPIPE_HISTORY"
+	EnvQueryCacheTtl    = "POWERPIPE_QUERY_CACHE_TTL"
+	EnvAuthConfig       = "POWERPIPE_AUTH_CONFIG"
+	EnvTlsCert          = "POWERPIPE_TLS_CERT"
+	EnvTlsKey           = "POWERPIPE_TLS_KEY"
+	EnvTlsClientCa      = "POWERPIPE_TLS_CLIENT_CA"
+	EnvTlsRedirectPort  = "POWERPIPE_TLS_REDIRECT_PORT"
 	// EnvConfigDump is an undocumented variable is subject to change in the future
 	EnvConfigDump = "POWERPIPE_CONFIG_DUMP"
 )
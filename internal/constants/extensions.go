@@ -1,5 +1,6 @@
 package constants
 
 const (
-	SnapshotExtension = ".pps"
+	SnapshotExtension    = ".pps"
+	OpenMetricsExtension = ".prom"
 )
@@ -38,6 +38,15 @@ const (
 // powerpipe snapshot
 const OutputFormatPpSnapshotShort = "pps"
 
+// SARIF 2.1.0, as consumed by code scanning tools
+const OutputFormatSarif = "sarif"
+
+// JUnit XML, as consumed by CI systems
+const OutputFormatJunit = "junit"
+
+// OpenMetrics text, as consumed by Prometheus
+const OutputFormatOpenMetrics = "openmetrics"
+
 var QueryOutputModeIds = map[QueryOutputMode][]string{
 	QueryOutputModeCsv:           {constants.OutputFormatCSV},
 	QueryOutputModeJson:          {constants.OutputFormatJSON},
@@ -53,12 +62,16 @@ type DashboardOutputMode enumflag.Flag
 const (
 	DashboardOutputModeSnapshot DashboardOutputMode = iota
 	DashboardOutputModeSnapshotShort
+	DashboardOutputModeHtml
+	DashboardOutputModeText
 	DashboardOutputModeNone
 )
 
 var DashboardOutputModeIds = map[DashboardOutputMode][]string{
 	DashboardOutputModeSnapshot:      {constants.OutputFormatSnapshot},
 	DashboardOutputModeSnapshotShort: {OutputFormatPpSnapshotShort},
+	DashboardOutputModeHtml:          {constants.OutputFormatHTML},
+	DashboardOutputModeText:          {constants.OutputFormatText},
 	DashboardOutputModeNone:          {constants.OutputFormatNone},
 }
 
@@ -72,7 +85,9 @@ const (
 	CheckOutputModeCsv
 	CheckOutputModeHTM
 	CheckOutputModeJSON
+	CheckOutputModeJunit
 	CheckOutputModeMd
+	CheckOutputModeSarif
 	CheckOutputModeTest
 	CheckOutputModeSnapshot
 	CheckOutputModeSnapshotShort
@@ -81,7 +96,12 @@ const (
 
 const (
 	DetectionOutputModeText DetectionOutputMode = iota
+	DetectionOutputModeCsv
+	DetectionOutputModeHTML
 	DetectionOutputModeJSON
+	DetectionOutputModeMd
+	DetectionOutputModeSnapshot
+	DetectionOutputModeSnapshotShort
 )
 
 var CheckOutputModeIds = map[CheckOutputMode][]string{
@@ -90,7 +110,9 @@ var CheckOutputModeIds = map[CheckOutputMode][]string{
 	CheckOutputModeCsv:           {constants.OutputFormatCSV},
 	CheckOutputModeHTM:           {constants.OutputFormatHTML},
 	CheckOutputModeJSON:          {constants.OutputFormatJSON},
+	CheckOutputModeJunit:         {OutputFormatJunit},
 	CheckOutputModeMd:            {constants.OutputFormatMD},
+	CheckOutputModeSarif:         {OutputFormatSarif},
 	CheckOutputModeTest:          {constants.OutputFormatText},
 	CheckOutputModeSnapshot:      {constants.OutputFormatSnapshot},
 	CheckOutputModeSnapshotShort: {OutputFormatPpSnapshotShort},
@@ -98,6 +120,51 @@ var CheckOutputModeIds = map[CheckOutputMode][]string{
 }
 
 var DetectionOutputModeIds = map[DetectionOutputMode][]string{
-	DetectionOutputModeText: {constants.OutputFormatText},
-	DetectionOutputModeJSON: {constants.OutputFormatJSON},
+	DetectionOutputModeText:          {constants.OutputFormatText},
+	DetectionOutputModeCsv:           {constants.OutputFormatCSV},
+	DetectionOutputModeHTML:          {constants.OutputFormatHTML},
+	DetectionOutputModeJSON:          {constants.OutputFormatJSON},
+	DetectionOutputModeMd:            {constants.OutputFormatMD},
+	DetectionOutputModeSnapshot:      {constants.OutputFormatSnapshot},
+	DetectionOutputModeSnapshotShort: {OutputFormatPpSnapshotShort},
+}
+
+type HistoryExportMode enumflag.Flag
+
+const (
+	HistoryExportModeJSON HistoryExportMode = iota
+	HistoryExportModeCsv
+)
+
+var HistoryExportModeIds = map[HistoryExportMode][]string{
+	HistoryExportModeJSON: {constants.OutputFormatJSON},
+	HistoryExportModeCsv:  {constants.OutputFormatCSV},
+}
+
+type SnapshotConvertMode enumflag.Flag
+
+const (
+	SnapshotConvertModeCsv SnapshotConvertMode = iota
+	SnapshotConvertModeHtml
+	SnapshotConvertModeJSON
+	SnapshotConvertModeMd
+)
+
+var SnapshotConvertModeIds = map[SnapshotConvertMode][]string{
+	SnapshotConvertModeCsv:  {constants.OutputFormatCSV},
+	SnapshotConvertModeHtml: {constants.OutputFormatHTML},
+	SnapshotConvertModeJSON: {constants.OutputFormatJSON},
+	SnapshotConvertModeMd:   {constants.OutputFormatMD},
+}
+
+type SnapshotDiffMode enumflag.Flag
+
+const (
+	SnapshotDiffModeText SnapshotDiffMode = iota
+	SnapshotDiffModeJSON
+)
+
+var SnapshotDiffModeIds = map[SnapshotDiffMode][]string{
+	SnapshotDiffModeText: {constants.OutputFormatText},
+	SnapshotDiffModeJSON: {constants.OutputFormatJSON},
 }
@@ -0,0 +1,7 @@
+package constants
+
+// powerpipe specific config block types
+const (
+	BlockTypeSchedule = "schedule"
+	BlockTypeNotifier = "notifier"
+)
@@ -0,0 +1,4 @@
+package constants
+
+// Severities is the list of control and detection severities, in increasing order
+var Severities = []string{"none", "low", "medium", "high", "critical"}
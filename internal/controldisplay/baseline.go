@@ -0,0 +1,300 @@
+package controldisplay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/pipe-fittings/v2/schema"
+	"github.com/turbot/powerpipe/internal/controlexecute"
+)
+
+// Baseline is the set of control result rows from a previous benchmark or control run,
+// loaded from either a JSON export or a snapshot (pps)
+type Baseline struct {
+	// the path the baseline was loaded from
+	Path string
+	rows []*BaselineRow
+}
+
+// BaselineRow is a single control result row, identified by its control and resource
+type BaselineRow struct {
+	Control  string `json:"control"`
+	Resource string `json:"resource"`
+	Status   string `json:"status"`
+	Reason   string `json:"reason"`
+}
+
+// types used to deserialize the baseline - these contain only the fields needed to build the diff
+
+// the output of the json formatter
+type baselineJsonGroup struct {
+	GroupId  string               `json:"group_id"`
+	Groups   []*baselineJsonGroup `json:"groups"`
+	Controls []struct {
+		ControlId string `json:"control_id"`
+		Results   []struct {
+			Reason   string `json:"reason"`
+			Resource string `json:"resource"`
+			Status   string `json:"status"`
+		} `json:"results"`
+	} `json:"controls"`
+}
+
+// a snapshot - control results are stored as the data of the control panels
+type baselineSnapshot struct {
+	Panels map[string]struct {
+		Name      string `json:"name"`
+		PanelType string `json:"panel_type"`
+		Data      *struct {
+			Rows []map[string]any `json:"rows"`
+		} `json:"data"`
+	} `json:"panels"`
+}
+
+// LoadBaseline loads a baseline from a JSON export or snapshot of a previous run
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	// determine whether this is a snapshot - snapshots have a top level 'panels' property
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline '%s' - expected a JSON export or a snapshot: %w", path, err)
+	}
+
+	res := &Baseline{Path: path}
+	if _, isSnapshot := probe["panels"]; isSnapshot {
+		err = res.loadSnapshot(data)
+	} else {
+		err = res.loadJson(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse baseline '%s': %w", path, err)
+	}
+	return res, nil
+}
+
+func (b *Baseline) loadSnapshot(data []byte) error {
+	var snapshot baselineSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+	// sort panel names so the row order is deterministic
+	names := make([]string, 0, len(snapshot.Panels))
+	for name := range snapshot.Panels {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		panel := snapshot.Panels[name]
+		if panel.PanelType != schema.BlockTypeControl || panel.Data == nil {
+			continue
+		}
+		for _, row := range panel.Data.Rows {
+			b.rows = append(b.rows, &BaselineRow{
+				Control:  name,
+				Resource: rowString(row, "resource"),
+				Status:   rowString(row, "status"),
+				Reason:   rowString(row, "reason"),
+			})
+		}
+	}
+	return nil
+}
+
+func (b *Baseline) loadJson(data []byte) error {
+	var root baselineJsonGroup
+	if err := json.Unmarshal(data, &root); err != nil {
+		return err
+	}
+	b.addJsonGroup(&root, "")
+	return nil
+}
+
+// addJsonGroup adds the rows of the controls of the group and its children
+// the JSON output only qualifies the names of controls from dependency mods - the names of other controls
+// are qualified with the mod of the benchmark containing them
+func (b *Baseline) addJsonGroup(group *baselineJsonGroup, modName string) {
+	if parts := strings.Split(group.GroupId, "."); len(parts) == 3 {
+		modName = parts[0]
+	}
+	for _, control := range group.Controls {
+		for _, row := range control.Results {
+			b.rows = append(b.rows, &BaselineRow{
+				Control:  qualifiedControlName(control.ControlId, modName),
+				Resource: row.Resource,
+				Status:   row.Status,
+				Reason:   row.Reason,
+			})
+		}
+	}
+	for _, child := range group.Groups {
+		b.addJsonGroup(child, modName)
+	}
+}
+
+func rowString(row map[string]any, key string) string {
+	if v, ok := row[key]; ok && v != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return ""
+}
+
+// qualifiedControlName prefixes an unqualified control name (e.g. 'control.c1') with the mod name, if known
+func qualifiedControlName(control, modName string) string {
+	if modName != "" && len(strings.Split(control, ".")) == 2 {
+		return fmt.Sprintf("%s.%s", modName, control)
+	}
+	return control
+}
+
+// baselineRowKey returns the key used to match rows between the baseline and the current run
+// rows are matched on the full control name, so controls with the same name in different mods are distinct
+// if a control returns multiple rows for the same resource, these are matched in order
+func baselineRowKey(control, resource string, occurrence int) string {
+	return fmt.Sprintf("%s\x00%s\x00%d", control, resource, occurrence)
+}
+
+// rowMap returns the baseline rows keyed by baselineRowKey, and the keys in baseline order
+// any control names which are still unqualified (i.e. from the JSON output of a control run)
+// are qualified with the given mod name
+func (b *Baseline) rowMap(modName string) (map[string]*BaselineRow, []string) {
+	res := make(map[string]*BaselineRow, len(b.rows))
+	var keys []string
+	occurrences := make(map[string]int)
+	for _, row := range b.rows {
+		control := qualifiedControlName(row.Control, modName)
+		k := baselineRowKey(control, row.Resource, 0)
+		key := baselineRowKey(control, row.Resource, occurrences[k])
+		occurrences[k]++
+		res[key] = row
+		keys = append(keys, key)
+	}
+	return res, keys
+}
+
+// DriftRow is a result row which differs between the baseline and the current run
+type DriftRow struct {
+	Control        string `json:"control"`
+	Resource       string `json:"resource"`
+	Status         string `json:"status,omitempty"`
+	Reason         string `json:"reason,omitempty"`
+	BaselineStatus string `json:"baseline_status,omitempty"`
+	BaselineReason string `json:"baseline_reason,omitempty"`
+}
+
+type DriftSummary struct {
+	NewAlarms     int `json:"new_alarms"`
+	NewOk         int `json:"new_ok"`
+	Disappeared   int `json:"disappeared"`
+	ReasonChanged int `json:"reason_changed"`
+	StatusChanged int `json:"status_changed"`
+}
+
+// DriftReport is the difference between the control results of the current run and a baseline
+type DriftReport struct {
+	Baseline string       `json:"baseline"`
+	Summary  DriftSummary `json:"summary"`
+	// rows now in alarm which were not in alarm (or did not exist) in the baseline
+	NewAlarms []*DriftRow `json:"new_alarms"`
+	// rows now ok which were not ok (or did not exist) in the baseline
+	NewOk []*DriftRow `json:"new_ok"`
+	// rows in the baseline which no longer exist
+	Disappeared []*DriftRow `json:"disappeared"`
+	// rows with the same status but a different reason
+	ReasonChanged []*DriftRow `json:"reason_changed"`
+	// rows whose status changed to something other than alarm or ok (e.g. to error or skip)
+	StatusChanged []*DriftRow `json:"status_changed"`
+}
+
+// NewDriftReport compares the control results of the execution tree with the baseline
+func NewDriftReport(tree *controlexecute.ExecutionTree, baseline *Baseline) *DriftReport {
+	res := &DriftReport{
+		Baseline:      baseline.Path,
+		NewAlarms:     []*DriftRow{},
+		NewOk:         []*DriftRow{},
+		Disappeared:   []*DriftRow{},
+		ReasonChanged: []*DriftRow{},
+		StatusChanged: []*DriftRow{},
+	}
+
+	// the baseline is assumed to be from the same workspace mod as the current run
+	var modName string
+	if tree.Workspace != nil && tree.Workspace.Mod != nil {
+		modName = tree.Workspace.Mod.ShortName
+	}
+	baselineRows, baselineKeys := baseline.rowMap(modName)
+	seen := make(map[string]struct{})
+
+	// iterate over the control runs in name order so the output is deterministic
+	controlNames := make([]string, 0, len(tree.ControlRuns))
+	for name := range tree.ControlRuns {
+		controlNames = append(controlNames, name)
+	}
+	slices.Sort(controlNames)
+
+	for _, name := range controlNames {
+		controlRun := tree.ControlRuns[name]
+		occurrences := make(map[string]int)
+		for _, row := range controlRun.Rows {
+			k := baselineRowKey(name, row.Resource, 0)
+			key := baselineRowKey(name, row.Resource, occurrences[k])
+			occurrences[k]++
+			seen[key] = struct{}{}
+
+			driftRow := &DriftRow{
+				Control:  name,
+				Resource: row.Resource,
+				Status:   row.Status,
+				Reason:   row.Reason,
+			}
+			prev, inBaseline := baselineRows[key]
+			if inBaseline {
+				driftRow.BaselineStatus = prev.Status
+				driftRow.BaselineReason = prev.Reason
+			}
+
+			switch {
+			case inBaseline && prev.Status == row.Status:
+				if prev.Reason != row.Reason {
+					res.ReasonChanged = append(res.ReasonChanged, driftRow)
+				}
+			case row.Status == constants.ControlAlarm:
+				res.NewAlarms = append(res.NewAlarms, driftRow)
+			case row.Status == constants.ControlOk:
+				res.NewOk = append(res.NewOk, driftRow)
+			case inBaseline:
+				res.StatusChanged = append(res.StatusChanged, driftRow)
+			}
+		}
+	}
+
+	for _, key := range baselineKeys {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		prev := baselineRows[key]
+		res.Disappeared = append(res.Disappeared, &DriftRow{
+			Control:        qualifiedControlName(prev.Control, modName),
+			Resource:       prev.Resource,
+			BaselineStatus: prev.Status,
+			BaselineReason: prev.Reason,
+		})
+	}
+
+	res.Summary = DriftSummary{
+		NewAlarms:     len(res.NewAlarms),
+		NewOk:         len(res.NewOk),
+		Disappeared:   len(res.Disappeared),
+		ReasonChanged: len(res.ReasonChanged),
+		StatusChanged: len(res.StatusChanged),
+	}
+	return res
+}
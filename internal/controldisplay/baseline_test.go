@@ -0,0 +1,134 @@
+package controldisplay
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/turbot/powerpipe/internal/controlexecute"
+)
+
+const testBaselineJsonExport = `{
+	"group_id": "root_result_group",
+	"groups": [{
+		"group_id": "mod.benchmark.b1",
+		"groups": [],
+		"controls": [{
+			"control_id": "control.c1",
+			"results": [
+				{"reason": "bucket is public", "resource": "arn:b1", "status": "alarm"},
+				{"reason": "bucket is private", "resource": "arn:b2", "status": "ok"},
+				{"reason": "bucket is private", "resource": "arn:b3", "status": "ok"},
+				{"reason": "bucket is public", "resource": "arn:b4", "status": "alarm"},
+				{"reason": "versioning enabled", "resource": "arn:b5", "status": "ok"}
+			]
+		}]
+	}, {
+		"group_id": "dep.benchmark.b2",
+		"groups": [],
+		"controls": [{
+			"control_id": "dep.control.c1",
+			"results": [
+				{"reason": "bucket is private", "resource": "arn:b1", "status": "ok"}
+			]
+		}]
+	}],
+	"controls": null
+}`
+
+const testBaselineSnapshot = `{
+	"schema_version": "20221222",
+	"panels": {
+		"mod.benchmark.b1": {"name": "mod.benchmark.b1", "panel_type": "benchmark"},
+		"mod.control.c1": {
+			"name": "mod.control.c1",
+			"panel_type": "control",
+			"data": {"rows": [
+				{"reason": "bucket is public", "resource": "arn:b1", "status": "alarm"},
+				{"reason": "bucket is private", "resource": "arn:b2", "status": "ok"},
+				{"reason": "bucket is private", "resource": "arn:b3", "status": "ok"},
+				{"reason": "bucket is public", "resource": "arn:b4", "status": "alarm"},
+				{"reason": "versioning enabled", "resource": "arn:b5", "status": "ok"}
+			]}
+		},
+		"dep.control.c1": {
+			"name": "dep.control.c1",
+			"panel_type": "control",
+			"data": {"rows": [
+				{"reason": "bucket is private", "resource": "arn:b1", "status": "ok"}
+			]}
+		}
+	}
+}`
+
+func TestDriftReport(t *testing.T) {
+	tree := &controlexecute.ExecutionTree{
+		ControlRuns: map[string]*controlexecute.ControlRun{
+			"mod.control.c1": {
+				ControlId: "control.c1",
+				Rows: controlexecute.ResultRows{
+					// unchanged
+					{Reason: "bucket is public", Resource: "arn:b1", Status: "alarm"},
+					// newly alarming
+					{Reason: "bucket is public", Resource: "arn:b2", Status: "alarm"},
+					// changed reason
+					{Reason: "bucket is private (policy)", Resource: "arn:b3", Status: "ok"},
+					// newly ok
+					{Reason: "bucket is private", Resource: "arn:b4", Status: "ok"},
+					// new resource, in alarm
+					{Reason: "bucket is public", Resource: "arn:b6", Status: "alarm"},
+					// arn:b5 has disappeared
+				},
+			},
+			// a control with the same name in a dependency mod, which is unchanged
+			"dep.control.c1": {
+				ControlId: "dep.control.c1",
+				Rows: controlexecute.ResultRows{
+					{Reason: "bucket is private", Resource: "arn:b1", Status: "ok"},
+				},
+			},
+		},
+	}
+
+	for name, content := range map[string]string{"export.json": testBaselineJsonExport, "snapshot.pps": testBaselineSnapshot} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			baseline, err := LoadBaseline(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			report := NewDriftReport(tree, baseline)
+			expected := DriftSummary{NewAlarms: 2, NewOk: 1, Disappeared: 1, ReasonChanged: 1}
+			if report.Summary != expected {
+				t.Fatalf("expected summary %+v, got %+v", expected, report.Summary)
+			}
+			if r := report.NewAlarms[0]; r.Control != "mod.control.c1" || r.Resource != "arn:b2" || r.BaselineStatus != "ok" {
+				t.Errorf("unexpected new alarm %+v", r)
+			}
+			if r := report.NewAlarms[1]; r.Resource != "arn:b6" || r.BaselineStatus != "" {
+				t.Errorf("unexpected new alarm %+v", r)
+			}
+			// rows are reported with the full control name, whether or not they are in the current run
+			if r := report.Disappeared[0]; r.Control != "mod.control.c1" || r.Resource != "arn:b5" || r.BaselineStatus != "ok" {
+				t.Errorf("unexpected disappeared row %+v", r)
+			}
+			if r := report.ReasonChanged[0]; r.BaselineReason != "bucket is private" || r.Reason != "bucket is private (policy)" {
+				t.Errorf("unexpected reason changed row %+v", r)
+			}
+		})
+	}
+}
+
+func TestLoadBaselineInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.csv")
+	if err := os.WriteFile(path, []byte("reason,resource,status\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBaseline(path); err == nil {
+		t.Errorf("expected an error loading a non-JSON baseline")
+	}
+}
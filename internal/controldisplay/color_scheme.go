@@ -7,6 +7,7 @@ import (
 	"github.com/logrusorgru/aurora"
 	"github.com/turbot/pipe-fittings/v2/constants"
 	"github.com/turbot/pipe-fittings/v2/utils"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
 )
 
 type colorFunc func(interface{}) aurora.Value
@@ -31,20 +32,23 @@ type ControlColorSchemaDefinition struct {
 	CountGraphInfo       string
 	CountGraphOK         string
 	CountGraphSkip       string
+	CountGraphSuppressed string
 	CountGraphBracket    string
 
 	// results
-	StatusAlarm string
-	StatusError string
-	StatusSkip  string
-	StatusInfo  string
-	StatusOK    string
-	StatusColon string
-	ReasonAlarm string
-	ReasonError string
-	ReasonSkip  string
-	ReasonInfo  string
-	ReasonOK    string
+	StatusAlarm      string
+	StatusError      string
+	StatusSkip       string
+	StatusInfo       string
+	StatusOK         string
+	StatusSuppressed string
+	StatusColon      string
+	ReasonAlarm      string
+	ReasonError      string
+	ReasonSkip       string
+	ReasonInfo       string
+	ReasonOK         string
+	ReasonSuppressed string
 
 	Spacer   string
 	Indent   string
@@ -67,18 +71,21 @@ type ControlColorScheme struct {
 	CountGraphInfo       colorFunc
 	CountGraphOK         colorFunc
 	CountGraphSkip       colorFunc
+	CountGraphSuppressed colorFunc
 	CountGraphBracket    colorFunc
 	StatusAlarm          colorFunc
 	StatusError          colorFunc
 	StatusSkip           colorFunc
 	StatusInfo           colorFunc
 	StatusOK             colorFunc
+	StatusSuppressed     colorFunc
 	StatusColon          colorFunc
 	ReasonAlarm          colorFunc
 	ReasonError          colorFunc
 	ReasonSkip           colorFunc
 	ReasonInfo           colorFunc
 	ReasonOK             colorFunc
+	ReasonSuppressed     colorFunc
 	Spacer               colorFunc
 	Indent               colorFunc
 
@@ -143,25 +150,28 @@ func (c *ControlColorScheme) Initialise(def *ControlColorSchemaDefinition) error
 	}
 	// populate the color maps
 	c.ReasonColors = map[string]colorFunc{
-		constants.ControlAlarm: c.ReasonAlarm,
-		constants.ControlSkip:  c.ReasonSkip,
-		constants.ControlInfo:  c.ReasonInfo,
-		constants.ControlError: c.ReasonError,
-		constants.ControlOk:    c.ReasonOK,
+		constants.ControlAlarm:           c.ReasonAlarm,
+		constants.ControlSkip:            c.ReasonSkip,
+		constants.ControlInfo:            c.ReasonInfo,
+		constants.ControlError:           c.ReasonError,
+		constants.ControlOk:              c.ReasonOK,
+		localconstants.ControlSuppressed: c.ReasonSuppressed,
 	}
 	c.StatusColors = map[string]colorFunc{
-		constants.ControlAlarm: c.StatusAlarm,
-		constants.ControlSkip:  c.StatusSkip,
-		constants.ControlInfo:  c.StatusInfo,
-		constants.ControlError: c.StatusError,
-		constants.ControlOk:    c.StatusOK,
+		constants.ControlAlarm:           c.StatusAlarm,
+		constants.ControlSkip:            c.StatusSkip,
+		constants.ControlInfo:            c.StatusInfo,
+		constants.ControlError:           c.StatusError,
+		constants.ControlOk:              c.StatusOK,
+		localconstants.ControlSuppressed: c.StatusSuppressed,
 	}
 	c.GraphColors = map[string]colorFunc{
-		constants.ControlAlarm: c.CountGraphAlarm,
-		constants.ControlSkip:  c.CountGraphSkip,
-		constants.ControlInfo:  c.CountGraphInfo,
-		constants.ControlError: c.CountGraphError,
-		constants.ControlOk:    c.CountGraphOK,
+		constants.ControlAlarm:           c.CountGraphAlarm,
+		constants.ControlSkip:            c.CountGraphSkip,
+		constants.ControlInfo:            c.CountGraphInfo,
+		constants.ControlError:           c.CountGraphError,
+		constants.ControlOk:              c.CountGraphOK,
+		localconstants.ControlSuppressed: c.CountGraphSuppressed,
 	}
 
 	c.UseColor = def.UseColor
@@ -185,18 +195,21 @@ var ColorSchemes = map[string]*ControlColorSchemaDefinition{
 		CountGraphInfo:       "bright-cyan",
 		CountGraphOK:         "bright-green",
 		CountGraphSkip:       "gray3",
+		CountGraphSuppressed: "yellow",
 		CountGraphBracket:    "gray2",
 		StatusAlarm:          "bold-bright-red",
 		StatusError:          "bold-bright-red",
 		StatusSkip:           "gray3",
 		StatusInfo:           "bright-cyan",
 		StatusOK:             "bright-green",
+		StatusSuppressed:     "yellow",
 		StatusColon:          "gray1",
 		ReasonAlarm:          "bright-red",
 		ReasonError:          "bright-red",
 		ReasonSkip:           "gray3",
 		ReasonInfo:           "bright-cyan",
 		ReasonOK:             "gray4",
+		ReasonSuppressed:     "gray3",
 		Spacer:               "gray1",
 		Indent:               "gray1",
 		UseColor:             true,
@@ -217,18 +230,21 @@ var ColorSchemes = map[string]*ControlColorSchemaDefinition{
 		CountGraphInfo:       "bright-cyan",
 		CountGraphOK:         "bright-green",
 		CountGraphSkip:       "gray3",
+		CountGraphSuppressed: "yellow",
 		CountGraphBracket:    "gray4",
 		StatusAlarm:          "bold-bright-red",
 		StatusError:          "bold-bright-red",
 		StatusSkip:           "gray3",
 		StatusInfo:           "bright-cyan",
 		StatusOK:             "bright-green",
+		StatusSuppressed:     "yellow",
 		StatusColon:          "gray5",
 		ReasonAlarm:          "bright-red",
 		ReasonError:          "bright-red",
 		ReasonSkip:           "gray3",
 		ReasonInfo:           "bright-cyan",
 		ReasonOK:             "gray2",
+		ReasonSuppressed:     "gray3",
 		Spacer:               "gray5",
 		Indent:               "gray5",
 		UseColor:             true,
@@ -109,7 +109,7 @@ func (r ControlRenderer) Render() string {
 	for _, row := range r.run.Rows {
 		resultRenderer := NewControlResultRenderer(
 			row.Status,
-			row.Reason,
+			resultReason(row),
 			row.Dimensions,
 			r.colorGenerator,
 			r.width,
@@ -130,3 +130,11 @@ func (r ControlRenderer) Render() string {
 
 	return strings.Join(controlStrings, "\n")
 }
+
+// for suppressed rows, include the exception in the reason
+func resultReason(row *controlexecute.ResultRow) string {
+	if row.Exception == nil {
+		return row.Reason
+	}
+	return fmt.Sprintf("%s (exception: %s, owner: %s, expires: %s)", row.Reason, row.Exception.Reason, row.Exception.Owner, row.Exception.Expires)
+}
@@ -36,6 +36,7 @@ func NewFormatResolver(target modconfig.ModTreeItem) (*FormatResolver, error) {
 		&NullFormatter{},
 		&TextFormatter{},
 		&SnapshotFormatter{},
+		&OpenMetricsFormatter{},
 	}
 
 	res := &FormatResolver{
@@ -0,0 +1,196 @@
+package controldisplay
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/spf13/viper"
+	"github.com/turbot/pipe-fittings/v2/app_specific"
+	"github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+	"github.com/turbot/powerpipe/internal/dashboardexecute"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+	"github.com/turbot/powerpipe/internal/resources"
+)
+
+func newTestDetectionRun(name, title, severity string, displayColumns []string, columns []string, rows ...map[string]any) *dashboardexecute.DetectionRun {
+	detection := &resources.Detection{DisplayColumns: displayColumns}
+	detection.FullName = name
+	detection.Title = &title
+	detection.Severity = &severity
+
+	data := &dashboardtypes.LeafData{Rows: rows}
+	for _, c := range columns {
+		data.Columns = append(data.Columns, &queryresult.ColumnDef{Name: c})
+	}
+	return &dashboardexecute.DetectionRun{Resource: detection, Data: data}
+}
+
+func newTestDetectionTree() *dashboardexecute.DetectionBenchmarkDisplayTree {
+	timestamp := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	child := &dashboardexecute.DetectionBenchmarkDisplay{GroupId: "mod.detection_benchmark.child", Title: "Child"}
+	child.AddDetection(newTestDetectionRun("mod.detection.logins", "Failed logins", "high",
+		[]string{"user", "timestamp"},
+		[]string{"user", "timestamp", "ip"},
+		map[string]any{"user": "alice, admin", "timestamp": timestamp, "ip": "10.0.0.1"},
+		map[string]any{"user": "bob | <script>", "timestamp": timestamp, "ip": "10.0.0.2"},
+	))
+	parent := &dashboardexecute.DetectionBenchmarkDisplay{GroupId: "mod.detection_benchmark.parent", Title: "Parent"}
+	parent.AddDetection(newTestDetectionRun("mod.detection.buckets", "Public buckets", "low",
+		nil,
+		[]string{"bucket"},
+		map[string]any{"bucket": "logs"},
+	))
+	parent.AddDetection(newTestDetectionRun("mod.detection.empty", "No results", "low", nil, []string{"bucket"}))
+	parent.AddResultGroup(child)
+
+	root := &dashboardexecute.DetectionBenchmarkDisplay{GroupId: dashboardexecute.RootResultGroup_Name, Title: "Parent"}
+	root.AddResultGroup(parent)
+	return &dashboardexecute.DetectionBenchmarkDisplayTree{Root: root, StartTime: timestamp}
+}
+
+func formatDetectionTemplate(t *testing.T, templateDir string, tree *dashboardexecute.DetectionBenchmarkDisplayTree) string {
+	app_specific.AppVersion = semver.MustParse("1.0.0")
+	// install the template, as detection templates may use assets of the control template of the same name
+	target := filepath.Join(t.TempDir(), filepath.Base(templateDir))
+	if err := writeDetectionTemplate(filepath.Base(templateDir), target); err != nil {
+		t.Fatal(err)
+	}
+	formatter, err := NewTemplateFormatter(NewOutputTemplate(target))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := formatter.FormatDetection(context.Background(), tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(output)
+}
+
+func TestDetectionCsvTemplate(t *testing.T) {
+	viper.Set(constants.ArgHeader, true)
+	viper.Set(constants.ArgSeparator, ",")
+	defer viper.Reset()
+
+	output := formatDetectionTemplate(t, "detection_templates/csv", newTestDetectionTree())
+	records, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse csv output: %s\n%s", err, output)
+	}
+
+	expected := [][]string{
+		{"detection", "detection_title", "benchmark", "severity", "error", "bucket", "user", "timestamp"},
+		{"mod.detection.buckets", "Public buckets", "mod.detection_benchmark.parent", "low", "", "logs", "", ""},
+		{"mod.detection.logins", "Failed logins", "mod.detection_benchmark.parent > mod.detection_benchmark.child", "high", "", "", "alice, admin", "2024-05-01T12:30:00Z"},
+		{"mod.detection.logins", "Failed logins", "mod.detection_benchmark.parent > mod.detection_benchmark.child", "high", "", "", "bob | <script>", "2024-05-01T12:30:00Z"},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("expected %d records, got %d:\n%s", len(expected), len(records), output)
+	}
+	for i := range expected {
+		if strings.Join(records[i], "\x00") != strings.Join(expected[i], "\x00") {
+			t.Errorf("record %d: expected %q, got %q", i, expected[i], records[i])
+		}
+	}
+}
+
+func TestDetectionCsvTemplateWithoutHeader(t *testing.T) {
+	viper.Set(constants.ArgHeader, false)
+	viper.Set(constants.ArgSeparator, ";")
+	defer viper.Reset()
+
+	output := formatDetectionTemplate(t, "detection_templates/csv", newTestDetectionTree())
+	r := csv.NewReader(strings.NewReader(output))
+	r.Comma = ';'
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse csv output: %s\n%s", err, output)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d:\n%s", len(records), output)
+	}
+	if records[0][0] != "mod.detection.buckets" {
+		t.Errorf("expected the first record to be a result row, got %q", records[0])
+	}
+}
+
+func TestDetectionMarkdownTemplate(t *testing.T) {
+	output := formatDetectionTemplate(t, "detection_templates/md", newTestDetectionTree())
+
+	for _, expected := range []string{
+		"# Parent",
+		"# Child",
+		"## Failed logins",
+		"**HIGH** · 2 result(s)",
+		"| user | timestamp |",
+		"| alice, admin | 2024-05-01T12:30:00Z |",
+		`| bob \| <script> | 2024-05-01T12:30:00Z |`,
+		"| bucket |",
+		"| logs |",
+		"**LOW** · 0 result(s)",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q:\n%s", expected, output)
+		}
+	}
+}
+
+func TestDetectionHtmlTemplate(t *testing.T) {
+	output := formatDetectionTemplate(t, "detection_templates/html", newTestDetectionTree())
+
+	for _, expected := range []string{
+		`<h1 class="title">Parent</h1>`,
+		"<h2>Child</h2>",
+		"<h3>Failed logins</h3>",
+		"<th>timestamp</th>",
+		"<td>alice, admin</td>",
+		"<td>bob | &lt;script&gt;</td>",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q", expected)
+		}
+	}
+	if strings.Contains(output, "<script>") {
+		t.Errorf("expected result values to be escaped")
+	}
+}
+
+func TestSnapshotFormatterFormatDetection(t *testing.T) {
+	tree := newTestDetectionTree()
+	formatter := &SnapshotFormatter{}
+	if _, err := formatter.FormatDetection(context.Background(), tree); err == nil {
+		t.Errorf("expected an error for a tree without a snapshot")
+	}
+
+	tree.Snapshot = &steampipeconfig.SteampipeSnapshot{
+		SchemaVersion: "20221222",
+		Panels:        map[string]steampipeconfig.SnapshotPanel{},
+		Layout:        &steampipeconfig.SnapshotTreeNode{Name: "mod.detection_benchmark.parent", NodeType: "detection_benchmark"},
+		Title:         "Parent",
+		FileNameRoot:  "mod.detection_benchmark.parent",
+	}
+	reader, err := formatter.FormatDetection(context.Background(), tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snapshot map[string]any
+	if err := json.NewDecoder(reader).Decode(&snapshot); err != nil {
+		t.Fatal(err)
+	}
+	layout, _ := snapshot["layout"].(map[string]any)
+	if layout["name"] != "mod.detection_benchmark.parent" {
+		t.Errorf("expected the snapshot layout to be the detection benchmark, got %v", snapshot["layout"])
+	}
+}
@@ -0,0 +1,185 @@
+package controldisplay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/v2/constants"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/controlexecute"
+	"github.com/turbot/powerpipe/internal/dashboardexecute"
+)
+
+// DriftFormatter implements the 'Formatter' interface and renders the difference between the results
+// of the execution tree and a baseline, as text, JSON or markdown
+type DriftFormatter struct {
+	FormatterBase
+	baseline *Baseline
+	format   string
+}
+
+// NewDriftFormatter returns a DriftFormatter for the given output format - one of text, brief, json or md
+func NewDriftFormatter(baseline *Baseline, format string) (*DriftFormatter, error) {
+	switch format {
+	case constants.OutputFormatText, constants.OutputFormatBrief:
+		format = constants.OutputFormatText
+	case constants.OutputFormatJSON, constants.OutputFormatMD:
+	default:
+		return nil, fmt.Errorf("output format '%s' is not supported with '--%s' - supported formats: text, json, md", format, localconstants.ArgBaseline)
+	}
+	return &DriftFormatter{baseline: baseline, format: format}, nil
+}
+
+func (*DriftFormatter) FormatDetection(context.Context, *dashboardexecute.DetectionBenchmarkDisplayTree) (io.Reader, error) {
+	return nil, fmt.Errorf("baseline comparison is not supported for detections")
+}
+
+func (f *DriftFormatter) Format(_ context.Context, tree *controlexecute.ExecutionTree) (io.Reader, error) {
+	report := NewDriftReport(tree, f.baseline)
+	switch f.format {
+	case constants.OutputFormatJSON:
+		res, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewReader(append(res, '\n')), nil
+	case constants.OutputFormatMD:
+		return strings.NewReader(renderDriftMarkdown(report)), nil
+	default:
+		return strings.NewReader(renderDriftText(report)), nil
+	}
+}
+
+func (f *DriftFormatter) FileExtension() string {
+	switch f.format {
+	case constants.OutputFormatJSON:
+		return constants.JsonExtension
+	case constants.OutputFormatMD:
+		return constants.MarkdownExtension
+	default:
+		return constants.TextExtension
+	}
+}
+
+func (f *DriftFormatter) Name() string {
+	return f.format
+}
+
+type driftSection struct {
+	title string
+	rows  []*DriftRow
+}
+
+func (r *DriftReport) sections() []driftSection {
+	return []driftSection{
+		{"New alarms", r.NewAlarms},
+		{"Newly OK", r.NewOk},
+		{"Disappeared", r.Disappeared},
+		{"Reason changed", r.ReasonChanged},
+		{"Status changed", r.StatusChanged},
+	}
+}
+
+func renderDriftText(report *DriftReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\nDrift from baseline %s\n", report.Baseline)
+	for _, section := range report.sections() {
+		if len(section.rows) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s (%d)\n", section.title, len(section.rows))
+		for _, row := range section.rows {
+			fmt.Fprintf(&b, "  %s  %s  %s\n", row.Control, driftResource(row), driftStatusChange(row, driftColorStatus))
+			if reason := driftReasonChange(row); reason != "" {
+				fmt.Fprintf(&b, "      %s\n", reason)
+			}
+		}
+	}
+	s := report.Summary
+	fmt.Fprintf(&b, "\nSummary: %d new alarms, %d newly ok, %d disappeared, %d reason changed, %d status changed\n",
+		s.NewAlarms, s.NewOk, s.Disappeared, s.ReasonChanged, s.StatusChanged)
+	return b.String()
+}
+
+func renderDriftMarkdown(report *DriftReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Drift from baseline `%s`\n\n", report.Baseline)
+	s := report.Summary
+	b.WriteString("| New alarms | Newly OK | Disappeared | Reason changed | Status changed |\n|-|-|-|-|-|\n")
+	fmt.Fprintf(&b, "| %d | %d | %d | %d | %d |\n", s.NewAlarms, s.NewOk, s.Disappeared, s.ReasonChanged, s.StatusChanged)
+	for _, section := range report.sections() {
+		if len(section.rows) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n| Control | Resource | Status | Reason |\n|-|-|-|-|\n", section.title)
+		for _, row := range section.rows {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n",
+				markdownCell(row.Control),
+				markdownCell(row.Resource),
+				markdownCell(driftStatusChange(row, func(s string) string { return s })),
+				markdownCell(driftReasonChange(row)))
+		}
+	}
+	return b.String()
+}
+
+func driftResource(row *DriftRow) string {
+	if row.Resource == "" {
+		return "-"
+	}
+	return row.Resource
+}
+
+func driftStatusChange(row *DriftRow, colorize func(string) string) string {
+	switch {
+	case row.Status == "":
+		return fmt.Sprintf("%s → (none)", colorize(row.BaselineStatus))
+	case row.BaselineStatus == "":
+		return fmt.Sprintf("(none) → %s", colorize(row.Status))
+	case row.BaselineStatus == row.Status:
+		return colorize(row.Status)
+	default:
+		return fmt.Sprintf("%s → %s", colorize(row.BaselineStatus), colorize(row.Status))
+	}
+}
+
+func driftReasonChange(row *DriftRow) string {
+	switch {
+	case row.Status == "":
+		return row.BaselineReason
+	case row.BaselineStatus != "" && row.BaselineReason != row.Reason:
+		return fmt.Sprintf("%s → %s", row.BaselineReason, row.Reason)
+	default:
+		return row.Reason
+	}
+}
+
+func driftColorStatus(status string) string {
+	if ControlColors == nil {
+		return status
+	}
+	switch status {
+	case constants.ControlAlarm:
+		return ControlColors.StatusAlarm(status).String()
+	case constants.ControlError:
+		return ControlColors.StatusError(status).String()
+	case constants.ControlOk:
+		return ControlColors.StatusOK(status).String()
+	case constants.ControlInfo:
+		return ControlColors.StatusInfo(status).String()
+	case constants.ControlSkip:
+		return ControlColors.StatusSkip(status).String()
+	case localconstants.ControlSuppressed:
+		return ControlColors.StatusSuppressed(status).String()
+	}
+	return status
+}
+
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
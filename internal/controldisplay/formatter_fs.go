@@ -14,6 +14,13 @@ import (
 //go:embed templates/* detection_templates/*
 var builtinTemplateFS embed.FS
 
+// sharedTemplateAssets are the files of each control template which are also used by the detection template
+// of the same name - these are written with the detection template rather than being duplicated in detection_templates
+// NOTE: the version of the detection template must be increased when these files change
+var sharedTemplateAssets = map[string][]string{
+	"html": {"normalize.tmpl.css", "logo.b64", "favicon.b64"},
+}
+
 type TemplateVersionFile struct {
 	Version string `json:"version"`
 }
@@ -154,18 +161,25 @@ func writeDetectionTemplate(path string, target string) error {
 	if err != nil {
 		return err
 	}
+	var sources []string
 	for _, entry := range detectionEntries {
 		if entry.IsDir() {
 			continue
 		}
-		sourceInEmbedFs := filepath.Join("detection_templates", path, entry.Name())
+		sources = append(sources, filepath.Join("detection_templates", path, entry.Name()))
+	}
+	for _, asset := range sharedTemplateAssets[path] {
+		sources = append(sources, filepath.Join("templates", path, asset))
+	}
+
+	for _, sourceInEmbedFs := range sources {
 		bytes, err := fs.ReadFile(builtinTemplateFS, sourceInEmbedFs)
 		if err != nil {
 			return err
 		}
 
 		//nolint: gosec // this file is safe to be read by all users
-		err = os.WriteFile(filepath.Join(target, entry.Name()), bytes, 0744)
+		err = os.WriteFile(filepath.Join(target, filepath.Base(sourceInEmbedFs)), bytes, 0744)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,119 @@
+package controldisplay
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/turbot/pipe-fittings/v2/app_specific"
+	"github.com/turbot/powerpipe/internal/controlexecute"
+	"github.com/turbot/powerpipe/internal/controlstatus"
+)
+
+type junitTestSuites struct {
+	Suites []struct {
+		Name      string `xml:"name,attr"`
+		Tests     int    `xml:"tests,attr"`
+		Failures  int    `xml:"failures,attr"`
+		Errors    int    `xml:"errors,attr"`
+		Skipped   int    `xml:"skipped,attr"`
+		TestCases []struct {
+			Name      string  `xml:"name,attr"`
+			ClassName string  `xml:"classname,attr"`
+			Time      float64 `xml:"time,attr"`
+			Failure   *struct {
+				Message string `xml:"message,attr"`
+				Text    string `xml:",chardata"`
+			} `xml:"failure"`
+			Error *struct {
+				Message string `xml:"message,attr"`
+			} `xml:"error"`
+			Skipped *struct{} `xml:"skipped"`
+		} `xml:"testcase"`
+	} `xml:"testsuite"`
+}
+
+func TestJunitTemplate(t *testing.T) {
+	app_specific.AppVersion = semver.MustParse("1.0.0")
+	formatter, err := NewTemplateFormatter(NewOutputTemplate("templates/junit.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newControlRun := func(title string, summary controlstatus.StatusSummary, runError string, rows controlexecute.ResultRows) *controlexecute.ControlRun {
+		return &controlexecute.ControlRun{
+			ControlId:      "control." + title,
+			FullName:       "mod.control." + title,
+			Title:          title,
+			Summary:        &summary,
+			RunErrorString: runError,
+			Rows:           rows,
+			Duration:       1500 * time.Millisecond,
+		}
+	}
+	child := &controlexecute.ResultGroup{
+		GroupId: "mod.benchmark.child",
+		Title:   "Child <benchmark>",
+		Summary: &controlexecute.GroupSummary{},
+		ControlRuns: []*controlexecute.ControlRun{
+			newControlRun("passing", controlstatus.StatusSummary{Ok: 2}, "", nil),
+			newControlRun("alarming", controlstatus.StatusSummary{Alarm: 1, Ok: 1}, "", controlexecute.ResultRows{
+				{Reason: "bucket is public & unencrypted", Resource: "arn:b1", Status: "alarm"},
+				{Reason: "bucket is private", Resource: "arn:b2", Status: "ok"},
+			}),
+			newControlRun("erroring", controlstatus.StatusSummary{}, "query failed", nil),
+			newControlRun("skipped", controlstatus.StatusSummary{Skip: 1}, "", nil),
+		},
+	}
+	parent := &controlexecute.ResultGroup{
+		GroupId: "mod.benchmark.parent",
+		Title:   "Parent",
+		Summary: &controlexecute.GroupSummary{},
+		Groups:  []*controlexecute.ResultGroup{child},
+	}
+	tree := &controlexecute.ExecutionTree{
+		Root:      &controlexecute.ResultGroup{GroupId: controlexecute.RootResultGroupName, Title: "Parent", Summary: &controlexecute.GroupSummary{}, Groups: []*controlexecute.ResultGroup{parent}},
+		StartTime: time.Now(),
+		EndTime:   time.Now(),
+	}
+
+	reader, err := formatter.Format(context.Background(), tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(output, &suites); err != nil {
+		t.Fatalf("output is not valid XML: %s\n%s", err, output)
+	}
+	// the parent benchmark has no controls so does not get a testsuite
+	if len(suites.Suites) != 1 {
+		t.Fatalf("expected 1 testsuite, got %d\n%s", len(suites.Suites), output)
+	}
+	suite := suites.Suites[0]
+	if suite.Name != "Child <benchmark>" || suite.Tests != 4 || suite.Failures != 1 || suite.Errors != 1 || suite.Skipped != 1 {
+		t.Errorf("unexpected testsuite %+v", suite)
+	}
+
+	cases := suite.TestCases
+	if cases[0].Failure != nil || cases[0].Error != nil || cases[0].Skipped != nil || cases[0].Time != 1.5 || cases[0].ClassName != "mod.benchmark.child" {
+		t.Errorf("expected passing testcase, got %+v", cases[0])
+	}
+	if cases[1].Failure == nil || strings.TrimSpace(cases[1].Failure.Text) != "arn:b1: bucket is public & unencrypted" {
+		t.Errorf("expected failure testcase, got %+v", cases[1])
+	}
+	if cases[2].Error == nil || cases[2].Error.Message != "query failed" {
+		t.Errorf("expected error testcase, got %+v", cases[2])
+	}
+	if cases[3].Skipped == nil {
+		t.Errorf("expected skipped testcase, got %+v", cases[3])
+	}
+}
@@ -0,0 +1,41 @@
+package controldisplay
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/controlexecute"
+	"github.com/turbot/powerpipe/internal/dashboardexecute"
+)
+
+// OpenMetricsFormatter implements the 'Formatter' interface and writes the result counts of a run
+// in the OpenMetrics text format, e.g. for the Prometheus node exporter textfile collector
+type OpenMetricsFormatter struct {
+	FormatterBase
+}
+
+func (*OpenMetricsFormatter) FormatDetection(context.Context, *dashboardexecute.DetectionBenchmarkDisplayTree) (io.Reader, error) {
+	return nil, fmt.Errorf("OpenMetrics format is not supported for detections")
+}
+
+func (f *OpenMetricsFormatter) Format(_ context.Context, tree *controlexecute.ExecutionTree) (io.Reader, error) {
+	w := NewOpenMetricsWriter()
+	w.Add(tree)
+
+	var b strings.Builder
+	if err := w.Write(&b); err != nil {
+		return nil, err
+	}
+	return strings.NewReader(b.String()), nil
+}
+
+func (f *OpenMetricsFormatter) FileExtension() string {
+	return localconstants.OpenMetricsExtension
+}
+
+func (f *OpenMetricsFormatter) Name() string {
+	return localconstants.OutputFormatOpenMetrics
+}
@@ -0,0 +1,145 @@
+package controldisplay
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/v2/app_specific"
+	"github.com/turbot/pipe-fittings/v2/modconfig"
+	"github.com/turbot/powerpipe/internal/controlexecute"
+	"github.com/turbot/powerpipe/internal/controlstatus"
+	"github.com/turbot/powerpipe/internal/resources"
+)
+
+// sarifLog contains the subset of SARIF properties verified by the test
+type sarifLog struct {
+	Version string `json:"version"`
+	Runs    []struct {
+		Tool struct {
+			Driver struct {
+				Rules []struct {
+					Id                   string `json:"id"`
+					DefaultConfiguration struct {
+						Level string `json:"level"`
+					} `json:"defaultConfiguration"`
+					Properties map[string]any `json:"properties"`
+				} `json:"rules"`
+			} `json:"driver"`
+		} `json:"tool"`
+		Results []struct {
+			RuleId    string `json:"ruleId"`
+			Level     string `json:"level"`
+			Locations []struct {
+				PhysicalLocation *struct {
+					ArtifactLocation struct {
+						Uri string `json:"uri"`
+					} `json:"artifactLocation"`
+				} `json:"physicalLocation"`
+				LogicalLocations []struct {
+					FullyQualifiedName string `json:"fullyQualifiedName"`
+				} `json:"logicalLocations"`
+			} `json:"locations"`
+			Properties map[string]any `json:"properties"`
+		} `json:"results"`
+	} `json:"runs"`
+}
+
+func TestSarifTemplate(t *testing.T) {
+	app_specific.AppVersion = semver.MustParse("1.0.0")
+	formatter, err := NewTemplateFormatter(NewOutputTemplate("templates/sarif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	modconfig.AppSpecificNewModResourcesFunc = resources.NewModResources
+	mod := modconfig.NewMod("sarif_mod", ".", hcl.Range{})
+	newControlRun := func(name, severity string, rows controlexecute.ResultRows) *controlexecute.ControlRun {
+		control := resources.NewControl(&hcl.Block{Type: "control"}, mod, name).(*resources.Control)
+		control.Severity = &severity
+		control.Tags = map[string]string{"service": "s3"}
+		run := &controlexecute.ControlRun{Control: control, FullName: control.Name(), Title: name, Severity: severity, Tags: control.Tags, Rows: rows}
+		for _, r := range rows {
+			r.Run = run
+		}
+		return run
+	}
+	highRun := newControlRun("high_control", "high", controlexecute.ResultRows{
+		{Reason: "bucket is public", Resource: "arn:b1", Status: "alarm", Dimensions: []controlexecute.Dimension{{Key: "region", Value: "us-east-1"}, {Key: "status", Value: "active"}}},
+		{Reason: "bucket is private", Resource: "arn:b2", Status: "ok"},
+	})
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	highRun.Control.DeclRange = hcl.Range{Filename: filepath.Join(wd, "controls", "s3.pp"), Start: hcl.Pos{Line: 3}}
+	lowRun := newControlRun("low_control", "low", controlexecute.ResultRows{
+		{Reason: "query failed", Resource: "arn:b3", Status: "error"},
+	})
+	tree := &controlexecute.ExecutionTree{
+		Root: &controlexecute.ResultGroup{
+			Summary: &controlexecute.GroupSummary{Status: controlstatus.StatusSummary{Alarm: 1, Ok: 1}},
+		},
+		ControlRuns: map[string]*controlexecute.ControlRun{
+			highRun.FullName: highRun,
+			lowRun.FullName:  lowRun,
+		},
+		StartTime: time.Now(),
+		EndTime:   time.Now(),
+	}
+
+	reader, err := formatter.Format(context.Background(), tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(output, &log); err != nil {
+		t.Fatalf("output is not valid JSON: %s\n%s", err, output)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF log: %s", output)
+	}
+	run := log.Runs[0]
+
+	rules := run.Tool.Driver.Rules
+	if len(rules) != 2 || rules[0].Id != "sarif_mod.control.high_control" || rules[0].DefaultConfiguration.Level != "error" {
+		t.Fatalf("unexpected rules %+v", rules)
+	}
+	if rules[0].Properties["security-severity"] != "8.0" || rules[1].DefaultConfiguration.Level != "note" {
+		t.Errorf("unexpected rule severity mapping %+v", rules)
+	}
+
+	// the ok row should not be reported
+	if len(run.Results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(run.Results))
+	}
+	alarm := run.Results[0]
+	if alarm.RuleId != "sarif_mod.control.high_control" || alarm.Level != "error" {
+		t.Errorf("unexpected alarm result %+v", alarm)
+	}
+	// dimensions are nested, so they cannot clash with the result status
+	dimensions, _ := alarm.Properties["dimensions"].(map[string]any)
+	if alarm.Locations[0].LogicalLocations[0].FullyQualifiedName != "arn:b1" || alarm.Properties["status"] != "alarm" || dimensions["region"] != "us-east-1" || dimensions["status"] != "active" {
+		t.Errorf("unexpected alarm location or properties %+v", alarm)
+	}
+	if l := alarm.Locations[0].PhysicalLocation; l == nil || l.ArtifactLocation.Uri != "controls/s3.pp" {
+		t.Errorf("expected the physical location to be the control declaration, got %+v", l)
+	}
+	if run.Results[1].Locations[0].PhysicalLocation != nil {
+		t.Errorf("expected no physical location for a control without a declaration range")
+	}
+	if run.Results[1].Level != "error" || run.Results[1].Properties["status"] != "error" {
+		t.Errorf("unexpected error result %+v", run.Results[1])
+	}
+}
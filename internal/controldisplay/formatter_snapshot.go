@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
 	localconstants "github.com/turbot/powerpipe/internal/constants"
 	"github.com/turbot/powerpipe/internal/controlexecute"
 	"github.com/turbot/powerpipe/internal/dashboardexecute"
@@ -16,8 +17,11 @@ type SnapshotFormatter struct {
 	FormatterBase
 }
 
-func (*SnapshotFormatter) FormatDetection(context.Context, *dashboardexecute.DetectionBenchmarkDisplayTree) (io.Reader, error) {
-	return nil, fmt.Errorf("Currently only JSON format is supported for detection benchmarks")
+func (f *SnapshotFormatter) FormatDetection(ctx context.Context, tree *dashboardexecute.DetectionBenchmarkDisplayTree) (io.Reader, error) {
+	if tree.Snapshot == nil {
+		return nil, fmt.Errorf("no snapshot is available for the detection results")
+	}
+	return f.formatSnapshot(ctx, tree.Snapshot)
 }
 
 func (f *SnapshotFormatter) Format(ctx context.Context, tree *controlexecute.ExecutionTree) (io.Reader, error) {
@@ -25,7 +29,10 @@ func (f *SnapshotFormatter) Format(ctx context.Context, tree *controlexecute.Exe
 	if err != nil {
 		return nil, err
 	}
+	return f.formatSnapshot(ctx, snapshot)
+}
 
+func (f *SnapshotFormatter) formatSnapshot(ctx context.Context, snapshot *steampipeconfig.SteampipeSnapshot) (io.Reader, error) {
 	// determine whether to indent the snapshot
 	// TACTICAL: check in the context for contextKeyFormatterUse - if this is "export" then DO NOT indent
 	var indent = true
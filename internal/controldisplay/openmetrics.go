@@ -0,0 +1,164 @@
+package controldisplay
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/v2/constants"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/controlexecute"
+	"github.com/turbot/powerpipe/internal/controlstatus"
+)
+
+// OpenMetricsContentType is the content type of the OpenMetrics text exposition format
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+var openMetricsInvalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+type openMetricsFamily struct {
+	name       string
+	metricType string
+	help       string
+	samples    []string
+}
+
+// OpenMetricsWriter builds OpenMetrics text for the results of one or more execution trees
+// each metric family is written once, with samples for every tree
+type OpenMetricsWriter struct {
+	benchmarkResults *openMetricsFamily
+	controlResults   *openMetricsFamily
+	runDuration      *openMetricsFamily
+	runTimestamp     *openMetricsFamily
+	runControlErrors *openMetricsFamily
+}
+
+func NewOpenMetricsWriter() *OpenMetricsWriter {
+	return &OpenMetricsWriter{
+		benchmarkResults: &openMetricsFamily{name: "powerpipe_benchmark_results", metricType: "gauge", help: "Number of control results in the benchmark, by status."},
+		controlResults:   &openMetricsFamily{name: "powerpipe_control_results", metricType: "gauge", help: "Number of results of the control, by status."},
+		runDuration:      &openMetricsFamily{name: "powerpipe_run_duration_seconds", metricType: "gauge", help: "Duration of the run."},
+		runTimestamp:     &openMetricsFamily{name: "powerpipe_run_timestamp_seconds", metricType: "gauge", help: "Time the run completed, in seconds since the epoch."},
+		runControlErrors: &openMetricsFamily{name: "powerpipe_run_control_errors", metricType: "gauge", help: "Number of controls which failed to run."},
+	}
+}
+
+// Add adds the samples for the results of an execution tree
+func (w *OpenMetricsWriter) Add(tree *controlexecute.ExecutionTree) {
+	if tree.Root == nil {
+		return
+	}
+	target := executionTreeTarget(tree)
+	targetLabel := [][2]string{{"target", target}}
+
+	// benchmarks - a benchmark may be the child of several parents, but is only reported once
+	reported := make(map[string]struct{})
+	var addGroup func(group *controlexecute.ResultGroup)
+	addGroup = func(group *controlexecute.ResultGroup) {
+		if _, ok := reported[group.GroupId]; ok {
+			return
+		}
+		reported[group.GroupId] = struct{}{}
+		if group.GroupId != controlexecute.RootResultGroupName && group.Summary != nil {
+			w.benchmarkResults.addStatusSamples(append(targetLabel, [2]string{"benchmark", group.GroupId}), &group.Summary.Status)
+		}
+		for _, child := range group.Groups {
+			addGroup(child)
+		}
+	}
+	addGroup(tree.Root)
+
+	// controls
+	controlErrors := 0
+	for _, name := range sortedKeys(tree.ControlRuns) {
+		run := tree.ControlRuns[name]
+		if run.RunErrorString != "" {
+			controlErrors++
+		}
+		labels := append(targetLabel, [2]string{"control", run.FullName})
+		if run.Severity != "" {
+			labels = append(labels, [2]string{"severity", run.Severity})
+		}
+		// tag keys which are the same once sanitised (e.g. 'a-b' and 'a.b') would give duplicate labels,
+		// which make the exposition invalid - only the first of these, in key order, is reported
+		tagLabels := make(map[string]struct{})
+		for _, tag := range sortedKeys(run.Tags) {
+			label := "tag_" + openMetricsInvalidLabelChars.ReplaceAllString(tag, "_")
+			if _, ok := tagLabels[label]; ok {
+				continue
+			}
+			tagLabels[label] = struct{}{}
+			labels = append(labels, [2]string{label, run.Tags[tag]})
+		}
+		if run.Summary != nil {
+			w.controlResults.addStatusSamples(labels, run.Summary)
+		}
+	}
+
+	w.runDuration.addSample(targetLabel, fmt.Sprintf("%g", tree.EndTime.Sub(tree.StartTime).Seconds()))
+	if !tree.EndTime.IsZero() {
+		w.runTimestamp.addSample(targetLabel, fmt.Sprintf("%d", tree.EndTime.Unix()))
+	}
+	w.runControlErrors.addSample(targetLabel, fmt.Sprintf("%d", controlErrors))
+}
+
+// Write writes the metric families, terminated by the OpenMetrics EOF marker
+func (w *OpenMetricsWriter) Write(writer io.Writer) error {
+	var b strings.Builder
+	for _, family := range []*openMetricsFamily{w.benchmarkResults, w.controlResults, w.runDuration, w.runTimestamp, w.runControlErrors} {
+		fmt.Fprintf(&b, "# TYPE %s %s\n# HELP %s %s\n", family.name, family.metricType, family.name, family.help)
+		for _, s := range family.samples {
+			b.WriteString(s)
+		}
+	}
+	b.WriteString("# EOF\n")
+	_, err := io.WriteString(writer, b.String())
+	return err
+}
+
+func (f *openMetricsFamily) addStatusSamples(labels [][2]string, summary *controlstatus.StatusSummary) {
+	for _, s := range []struct {
+		status string
+		count  int
+	}{
+		{constants.ControlOk, summary.Ok},
+		{constants.ControlAlarm, summary.Alarm},
+		{constants.ControlInfo, summary.Info},
+		{constants.ControlSkip, summary.Skip},
+		{constants.ControlError, summary.Error},
+		{localconstants.ControlSuppressed, summary.Suppressed},
+	} {
+		f.addSample(append(slices.Clone(labels), [2]string{"status", s.status}), fmt.Sprintf("%d", s.count))
+	}
+}
+
+func (f *openMetricsFamily) addSample(labels [][2]string, value string) {
+	var labelStrings = make([]string, len(labels))
+	for i, l := range labels {
+		labelStrings[i] = fmt.Sprintf(`%s="%s"`, l[0], escapeOpenMetricsLabelValue(l[1]))
+	}
+	f.samples = append(f.samples, fmt.Sprintf("%s{%s} %s\n", f.name, strings.Join(labelStrings, ","), value))
+}
+
+func escapeOpenMetricsLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+// executionTreeTarget returns the name of the benchmark or control the tree was created for
+func executionTreeTarget(tree *controlexecute.ExecutionTree) string {
+	if len(tree.Root.Children) == 1 {
+		return tree.Root.Children[0].GetName()
+	}
+	return tree.Root.GroupId
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
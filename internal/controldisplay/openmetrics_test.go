@@ -0,0 +1,87 @@
+package controldisplay
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/turbot/powerpipe/internal/controlexecute"
+	"github.com/turbot/powerpipe/internal/controlstatus"
+)
+
+func TestOpenMetricsWriter(t *testing.T) {
+	benchmark := &controlexecute.ResultGroup{
+		GroupId: "mod.benchmark.b1",
+		Summary: &controlexecute.GroupSummary{Status: controlstatus.StatusSummary{Ok: 2, Alarm: 1}},
+	}
+	// a benchmark which is a child of both the root benchmark and another benchmark
+	shared := &controlexecute.ResultGroup{
+		GroupId: "mod.benchmark.shared",
+		Summary: &controlexecute.GroupSummary{Status: controlstatus.StatusSummary{Ok: 1}},
+	}
+	benchmark.Groups = []*controlexecute.ResultGroup{
+		shared,
+		{GroupId: "mod.benchmark.b2", Summary: &controlexecute.GroupSummary{}, Groups: []*controlexecute.ResultGroup{shared}},
+	}
+	start := time.Unix(1700000000, 0)
+	tree := &controlexecute.ExecutionTree{
+		Root: &controlexecute.ResultGroup{
+			GroupId:  controlexecute.RootResultGroupName,
+			Summary:  &controlexecute.GroupSummary{Status: controlstatus.StatusSummary{Ok: 2, Alarm: 1}},
+			Groups:   []*controlexecute.ResultGroup{benchmark},
+			Children: []controlexecute.ExecutionTreeNode{benchmark},
+		},
+		ControlRuns: map[string]*controlexecute.ControlRun{
+			"mod.control.c1": {
+				FullName: "mod.control.c1",
+				Severity: "high",
+				Tags:     map[string]string{"service": "s3", "cis-level": `"1"`, "cis.level": "2"},
+				Summary:  &controlstatus.StatusSummary{Ok: 2, Alarm: 1},
+			},
+			"mod.control.c2": {
+				FullName:       "mod.control.c2",
+				Summary:        &controlstatus.StatusSummary{},
+				RunErrorString: "query failed",
+			},
+		},
+		StartTime: start,
+		EndTime:   start.Add(2500 * time.Millisecond),
+	}
+
+	w := NewOpenMetricsWriter()
+	w.Add(tree)
+	var b strings.Builder
+	if err := w.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	output := b.String()
+
+	for _, expected := range []string{
+		"# TYPE powerpipe_benchmark_results gauge\n",
+		`powerpipe_benchmark_results{target="mod.benchmark.b1",benchmark="mod.benchmark.b1",status="alarm"} 1` + "\n",
+		`powerpipe_control_results{target="mod.benchmark.b1",control="mod.control.c1",severity="high",tag_cis_level="\"1\"",tag_service="s3",status="ok"} 2` + "\n",
+		`powerpipe_control_results{target="mod.benchmark.b1",control="mod.control.c2",status="error"} 0` + "\n",
+		`powerpipe_run_duration_seconds{target="mod.benchmark.b1"} 2.5` + "\n",
+		`powerpipe_run_timestamp_seconds{target="mod.benchmark.b1"} 1700000002` + "\n",
+		"# TYPE powerpipe_run_control_errors gauge\n",
+		`powerpipe_run_control_errors{target="mod.benchmark.b1"} 1` + "\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q\n%s", expected, output)
+		}
+	}
+	if !strings.HasSuffix(output, "# EOF\n") {
+		t.Errorf("expected output to end with the EOF marker")
+	}
+	if count := strings.Count(output, `benchmark="mod.benchmark.shared",status="ok"`); count != 1 {
+		t.Errorf("expected a benchmark with several parents to be reported once, got %d samples\n%s", count, output)
+	}
+	// tag keys which are the same once sanitised are only reported once
+	if strings.Contains(output, `tag_cis_level="2"`) {
+		t.Errorf("expected a tag key which clashes with an earlier key to be skipped\n%s", output)
+	}
+	// the synthetic root group should not be reported
+	if strings.Contains(output, controlexecute.RootResultGroupName) {
+		t.Errorf("expected the root result group to be excluded\n%s", output)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/turbot/pipe-fittings/v2/pipes"
 	"github.com/turbot/pipe-fittings/v2/statushooks"
 	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+	"github.com/turbot/pipe-fittings/v2/utils"
 	"github.com/turbot/powerpipe/internal/controlexecute"
 	"github.com/turbot/powerpipe/internal/controlstatus"
 	"github.com/turbot/powerpipe/internal/dashboardexecute"
@@ -80,6 +81,11 @@ func SnapshotToExecutionTree(ctx context.Context, snapshot *steampipeconfig.Stea
 	// Step 4: Add any additional metadata from the snapshot
 	tree.StartTime = snapshot.StartTime
 	tree.EndTime = snapshot.EndTime
+	tree.Snapshot = snapshot
+	// echo the time range evaluated by the run
+	if timeRange, ok := snapshot.Metadata["datetime_range"].(utils.TimeRange); ok {
+		tree.Root.DateTimeRange = &timeRange
+	}
 
 	return tree, nil
 }
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/turbot/go-kit/helpers"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
 	"github.com/turbot/powerpipe/internal/controlexecute"
 )
 
@@ -61,6 +62,10 @@ func (r SummaryRenderer) Render() string {
 		alarmStatusRow,
 		errorStatusRow,
 	}
+	// only show suppressed results if an exceptions file was used and matched an alarm
+	if r.resultTree.Root.Summary.Status.Suppressed > 0 {
+		summaryLines = append(summaryLines, NewSummaryStatusRowRenderer(r.resultTree, availableWidth, localconstants.ControlSuppressed).Render())
+	}
 	// if there is a severity block, add it
 	if len(severityRows) > 0 {
 		summaryLines = append(summaryLines, "") // blank line
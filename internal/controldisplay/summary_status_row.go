@@ -6,6 +6,7 @@ import (
 
 	"github.com/turbot/go-kit/helpers"
 	"github.com/turbot/pipe-fittings/v2/constants"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
 	"github.com/turbot/powerpipe/internal/controlexecute"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
@@ -41,6 +42,8 @@ func (r *SummaryStatusRowRenderer) Render() string {
 		count = r.resultTree.Root.Summary.Status.Alarm
 	case constants.ControlError:
 		count = r.resultTree.Root.Summary.Status.Error
+	case localconstants.ControlSuppressed:
+		count = r.resultTree.Root.Summary.Status.Suppressed
 	default:
 		// we can safely panic here, since the status enum check should have been
 		// done by the executor. this is here for unit tests mostly
@@ -5,6 +5,7 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"sync"
 	"text/template"
@@ -13,6 +14,12 @@ import (
 	"github.com/Masterminds/sprig/v3"
 )
 
+// TemplateFuncs returns the functions available to output templates, using the default render config
+// this allows other template based output (e.g. notification payloads) to use the same functions
+func TemplateFuncs() template.FuncMap {
+	return templateFuncs(TemplateRenderContext{Config: TemplateRenderConfig{Separator: ","}})
+}
+
 // templateFuncs merges desired functions from sprig with custom functions that we
 // define in steampipe
 func templateFuncs(renderContext TemplateRenderContext) template.FuncMap {
@@ -35,7 +42,9 @@ func templateFuncs(renderContext TemplateRenderContext) template.FuncMap {
 	formatterTemplateFuncMap := template.FuncMap{
 		"durationInSeconds": durationInSeconds,
 		"toCsvCell":         toCSVCellFnFactory(renderContext.Config.Separator),
+		"toMdCell":          toMdCell,
 		"toSafeJson":        toSafeJson,
+		"toRelativePath":    toRelativePath,
 	}
 	for k, v := range formatterTemplateFuncMap {
 		funcs[k] = v
@@ -44,6 +53,16 @@ func templateFuncs(renderContext TemplateRenderContext) template.FuncMap {
 	return funcs
 }
 
+// toRelativePath returns the path relative to the base directory, in slash form
+// if the path is not within the base directory, it is returned unchanged
+func toRelativePath(base, path string) string {
+	rel, err := filepath.Rel(base, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
 // toSafeJson safely converts a value to JSON string, handling error cases gracefully
 func toSafeJson(v interface{}) string {
 	if v == nil {
@@ -133,5 +152,15 @@ func toCSVCellFnFactory(comma string) func(interface{}) string {
 	}
 }
 
+// toMdCell escapes a value for use in a markdown table cell
+// pipes are escaped and line breaks are replaced with spaces, as either would break the table
+func toMdCell(v interface{}) string {
+	s := fmt.Sprintf("%v", v)
+	s = strings.ReplaceAll(s, "\r\n", " ")
+	s = strings.ReplaceAll(s, "\n", " ")
+	s = strings.ReplaceAll(s, "\r", " ")
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
 // durationInSeconds returns the passed in duration as seconds
 func durationInSeconds(t time.Duration) float64 { return t.Seconds() }
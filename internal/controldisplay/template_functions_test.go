@@ -11,3 +11,18 @@ func BenchmarkToCsvCell(b *testing.B) {
 		toCsvCell(i)
 	}
 }
+
+func TestToMdCell(t *testing.T) {
+	tests := map[string]interface{}{
+		"plain":              "plain",
+		`a \| b`:             "a | b",
+		"line one line two":  "line one\r\nline two",
+		"line one line  two": "line one\nline \ntwo",
+		"42":                 42,
+	}
+	for expected, input := range tests {
+		if got := toMdCell(input); got != expected {
+			t.Errorf("toMdCell(%q): expected %q, got %q", input, expected, got)
+		}
+	}
+}
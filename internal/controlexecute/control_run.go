@@ -16,6 +16,7 @@ import (
 	"github.com/turbot/pipe-fittings/v2/statushooks"
 	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
 	"github.com/turbot/pipe-fittings/v2/utils"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
 	"github.com/turbot/powerpipe/internal/controlstatus"
 	"github.com/turbot/powerpipe/internal/dashboardtypes"
 	"github.com/turbot/powerpipe/internal/db_client"
@@ -402,6 +403,14 @@ func (r *ControlRun) getDimensionSchema() map[string]*queryresult.ColumnDef {
 
 // add the result row to our results and update the summary with the row status
 func (r *ControlRun) addResultRow(row *ResultRow) {
+	// if the row is an alarm which matches an exception, suppress it
+	if row.Status == constants.ControlAlarm {
+		if exception := r.Tree.Exceptions.Match(r.Control, row.Resource, row.DimensionMap(), time.Now()); exception != nil {
+			row.Status = localconstants.ControlSuppressed
+			row.Exception = exception
+		}
+	}
+
 	// update results
 	r.rowMap[row.Status] = append(r.rowMap[row.Status], row)
 
@@ -417,12 +426,14 @@ func (r *ControlRun) addResultRow(row *ResultRow) {
 		r.Summary.Info++
 	case constants.ControlError:
 		r.Summary.Error++
+	case localconstants.ControlSuppressed:
+		r.Summary.Suppressed++
 	}
 }
 
 // populate ordered list of rows
 func (r *ControlRun) createdOrderedResultRows() {
-	statusOrder := []string{constants.ControlError, constants.ControlAlarm, constants.ControlInfo, constants.ControlOk, constants.ControlSkip}
+	statusOrder := []string{constants.ControlError, constants.ControlAlarm, constants.ControlInfo, constants.ControlOk, constants.ControlSkip, localconstants.ControlSuppressed}
 	for _, status := range statusOrder {
 		r.Rows = append(r.Rows, r.rowMap[status]...)
 	}
@@ -13,6 +13,7 @@ import (
 	pworkspace "github.com/turbot/pipe-fittings/v2/workspace"
 	"github.com/turbot/powerpipe/internal/controlstatus"
 	"github.com/turbot/powerpipe/internal/db_client"
+	"github.com/turbot/powerpipe/internal/exceptions"
 	"github.com/turbot/powerpipe/internal/resources"
 	"github.com/turbot/powerpipe/internal/workspace"
 	"golang.org/x/sync/semaphore"
@@ -33,7 +34,9 @@ type ExecutionTree struct {
 	Workspace  *workspace.PowerpipeWorkspace `json:"-"`
 	// ControlRunInstances is a list of control runs for each parent.
 	ControlRunInstances []*ControlRunInstance `json:"-"`
-	client              *db_client.DbClient
+	// if set, alarms which match an exception are reported as suppressed
+	Exceptions *exceptions.Exceptions `json:"-"`
+	client     *db_client.DbClient
 	// an optional map of control names used to filter the controls which are run
 	controlNameFilterMap map[string]struct{}
 }
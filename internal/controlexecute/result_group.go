@@ -282,6 +282,7 @@ func (r *ResultGroup) updateSummary(summary *controlstatus.StatusSummary) {
 	r.Summary.Status.Info += summary.Info
 	r.Summary.Status.Ok += summary.Ok
 	r.Summary.Status.Error += summary.Error
+	r.Summary.Status.Suppressed += summary.Suppressed
 
 	if r.Parent != nil {
 		r.Parent.updateSummary(summary)
@@ -301,6 +302,7 @@ func (r *ResultGroup) updateSeverityCounts(severity string, summary *controlstat
 	val.Info += summary.Info
 	val.Ok += summary.Ok
 	val.Skip += summary.Skip
+	val.Suppressed += summary.Suppressed
 
 	r.Summary.Severity[severity] = val
 	if r.Parent != nil {
@@ -10,6 +10,7 @@ import (
 	"github.com/turbot/pipe-fittings/v2/queryresult"
 	"github.com/turbot/pipe-fittings/v2/utils"
 	"github.com/turbot/powerpipe/internal/dashboardtypes"
+	"github.com/turbot/powerpipe/internal/exceptions"
 	"github.com/turbot/powerpipe/internal/resources"
 )
 
@@ -48,8 +49,10 @@ type ResultRow struct {
 	Reason string `json:"reason" csv:"reason"`
 	// resource name
 	Resource string `json:"resource" csv:"resource"`
-	// status of the row (ok, info, alarm, error, skip)
+	// status of the row (ok, info, alarm, error, skip, suppressed)
 	Status string `json:"status" csv:"status"`
+	// if the row is suppressed, the exception which matched it
+	Exception *exceptions.Exception `json:"exception,omitempty"`
 	// dimensions for this row
 	Dimensions []Dimension `json:"dimensions"`
 	// parent control run
@@ -58,6 +61,15 @@ type ResultRow struct {
 	Control *resources.Control `json:"-" csv:"control_id:UnqualifiedName,control_title:Title,control_description:Description"`
 }
 
+// DimensionMap returns the dimensions of the row as a map of key to value
+func (r *ResultRow) DimensionMap() map[string]string {
+	res := make(map[string]string, len(r.Dimensions))
+	for _, dim := range r.Dimensions {
+		res[dim.Key] = dim.Value
+	}
+	return res
+}
+
 // GetDimensionValue returns the value for a dimension key. Returns an empty string with 'false' if not found
 func (r *ResultRow) GetDimensionValue(key string) string {
 	for _, dim := range r.Dimensions {
@@ -0,0 +1,207 @@
+package controlexecute
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/pipe-fittings/v2/schema"
+	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+	"github.com/turbot/powerpipe/internal/controlstatus"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+	"github.com/turbot/powerpipe/internal/resources"
+	"github.com/turbot/powerpipe/internal/snapshot"
+)
+
+// NewExecutionTreeFromSnapshot rebuilds the execution tree of a benchmark or control run from a snapshot loaded by snapshot.Load,
+// so the results can be displayed using the control formatters
+//
+// the tree is built from the snapshot panels only - as there is no workspace, control ids are the unqualified control names
+func NewExecutionTreeFromSnapshot(s *steampipeconfig.SteampipeSnapshot) (*ExecutionTree, error) {
+	rootPanel, ok := snapshot.GetPanel(s, s.Layout.Name)
+	if !ok {
+		return nil, fmt.Errorf("snapshot has no panel '%s'", s.Layout.Name)
+	}
+	if t := rootPanel.PanelType(); t != schema.BlockTypeBenchmark && t != schema.BlockTypeControl {
+		return nil, fmt.Errorf("snapshot is of a %s - only benchmark and control snapshots are supported", t)
+	}
+
+	tree := &ExecutionTree{
+		ControlRuns: make(map[string]*ControlRun),
+		StartTime:   s.StartTime,
+		EndTime:     s.EndTime,
+		SearchPath:  s.SearchPath,
+	}
+	tree.DimensionColorGenerator, _ = NewDimensionColorGenerator(4, 27)
+	tree.Root = &ResultGroup{
+		GroupId:    RootResultGroupName,
+		Groups:     []*ResultGroup{},
+		Tags:       make(map[string]string),
+		Summary:    NewGroupSummary(),
+		Severity:   make(map[string]controlstatus.StatusSummary),
+		updateLock: new(sync.Mutex),
+		NodeType:   schema.BlockTypeBenchmark,
+		Title:      rootPanel.Title(),
+	}
+	if err := tree.addSnapshotNode(s, s.Layout, tree.Root); err != nil {
+		return nil, err
+	}
+
+	tree.Progress = controlstatus.NewControlProgress(len(tree.ControlRuns))
+	tree.PopulateControlRunInstances()
+	return tree, nil
+}
+
+func (tree *ExecutionTree) addSnapshotNode(s *steampipeconfig.SteampipeSnapshot, node *steampipeconfig.SnapshotTreeNode, parent *ResultGroup) error {
+	panel, ok := snapshot.GetPanel(s, node.Name)
+	if !ok {
+		return fmt.Errorf("snapshot has no panel '%s'", node.Name)
+	}
+
+	switch panel.PanelType() {
+	case schema.BlockTypeBenchmark:
+		group := &ResultGroup{
+			GroupId:       node.Name,
+			Title:         panel.Title(),
+			Description:   panelString(panel, "description"),
+			Tags:          panelTags(panel),
+			Documentation: panelString(panel, "documentation"),
+			Display:       panelString(panel, "display"),
+			Type:          panelString(panel, "type"),
+			Parent:        parent,
+			Groups:        []*ResultGroup{},
+			Summary:       NewGroupSummary(),
+			Severity:      make(map[string]controlstatus.StatusSummary),
+			updateLock:    new(sync.Mutex),
+			NodeType:      schema.BlockTypeBenchmark,
+		}
+		parent.addResultGroup(group)
+		for _, c := range node.Children {
+			if err := tree.addSnapshotNode(s, c, group); err != nil {
+				return err
+			}
+		}
+	case schema.BlockTypeControl:
+		// a control may be a child of more than one benchmark
+		run, ok := tree.ControlRuns[node.Name]
+		if ok {
+			run.Parents = append(run.Parents, parent)
+		} else {
+			run = newControlRunFromSnapshotPanel(node.Name, panel, tree)
+			run.Parents = []*ResultGroup{parent}
+			tree.ControlRuns[node.Name] = run
+		}
+		parent.addControl(run)
+		parent.addDimensionKeys(run.DimensionKeys...)
+		// the summaries are rebuilt from the control summaries, as is done when the controls are executed
+		parent.updateSummary(run.Summary)
+		if run.Severity != "" {
+			parent.updateSeverityCounts(run.Severity, run.Summary)
+		}
+	default:
+		return fmt.Errorf("unexpected %s panel '%s' in benchmark snapshot", panel.PanelType(), node.Name)
+	}
+	return nil
+}
+
+func newControlRunFromSnapshotPanel(name string, panel snapshot.Panel, tree *ExecutionTree) *ControlRun {
+	properties, _ := panel["properties"].(map[string]any)
+	severity, _ := properties["severity"].(string)
+	title := panel.Title()
+
+	// the panel name is the fully qualified control name
+	unqualifiedName := name
+	if parts := strings.SplitN(name, ".", 2); len(parts) == 2 && parts[0] != schema.BlockTypeControl {
+		unqualifiedName = parts[1]
+	}
+	control := &resources.Control{}
+	control.FullName = name
+	control.UnqualifiedName = unqualifiedName
+	control.ShortName = strings.TrimPrefix(unqualifiedName, schema.BlockTypeControl+".")
+	control.Title = &title
+	control.Tags = panelTags(panel)
+	if severity != "" {
+		control.Severity = &severity
+	}
+
+	run := &ControlRun{
+		Control:        control,
+		ControlId:      unqualifiedName,
+		FullName:       name,
+		Title:          title,
+		Description:    panelString(panel, "description"),
+		Documentation:  panelString(panel, "documentation"),
+		Tags:           control.Tags,
+		Display:        panelString(panel, "display"),
+		Type:           panelString(panel, "display_type"),
+		Severity:       severity,
+		NodeType:       schema.BlockTypeControl,
+		Properties:     properties,
+		Summary:        &controlstatus.StatusSummary{},
+		RunStatus:      dashboardtypes.RunStatus(panelString(panel, "status")),
+		RunErrorString: panelString(panel, "error"),
+		Tree:           tree,
+		rowMap:         make(map[string]ResultRows),
+	}
+
+	summary := panel.StatusSummary()
+	run.Summary.Ok = summary["ok"]
+	run.Summary.Alarm = summary["alarm"]
+	run.Summary.Info = summary["info"]
+	run.Summary.Skip = summary["skip"]
+	run.Summary.Error = summary["error"]
+	run.Summary.Suppressed = summary["suppressed"]
+
+	// the result rows are the panel data - any columns other than reason, resource and status are dimensions
+	columns, rows := panel.Data()
+	dimensionSchema := make(map[string]*queryresult.ColumnDef)
+	for _, c := range columns {
+		if c != "reason" && c != "resource" && c != "status" {
+			run.DimensionKeys = append(run.DimensionKeys, c)
+			dimensionSchema[c] = &queryresult.ColumnDef{Name: c, DataType: "TEXT"}
+		}
+	}
+	for _, r := range rows {
+		row := &ResultRow{
+			Reason:   panelValueString(r["reason"]),
+			Resource: panelValueString(r["resource"]),
+			Status:   panelValueString(r["status"]),
+			Run:      run,
+			Control:  control,
+		}
+		for _, key := range run.DimensionKeys {
+			if v, ok := r[key]; ok && v != nil {
+				row.Dimensions = append(row.Dimensions, Dimension{Key: key, Value: panelValueString(v), SqlType: "TEXT"})
+			}
+		}
+		run.Rows = append(run.Rows, row)
+	}
+	run.Data = run.Rows.ToLeafData(dimensionSchema)
+	return run
+}
+
+func panelString(panel snapshot.Panel, key string) string {
+	s, _ := panel[key].(string)
+	return s
+}
+
+func panelValueString(v any) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func panelTags(panel snapshot.Panel) map[string]string {
+	res := make(map[string]string)
+	if tags, ok := panel["tags"].(map[string]any); ok {
+		for k, v := range tags {
+			res[k] = panelValueString(v)
+		}
+	}
+	return res
+}
@@ -0,0 +1,67 @@
+package controlexecute
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/turbot/powerpipe/internal/snapshot"
+)
+
+const testBenchmarkSnapshot = `{"schema_version": "20240607", "panels": {
+  "mod.benchmark.b1": {"name": "mod.benchmark.b1", "panel_type": "benchmark", "title": "B1"},
+  "mod.benchmark.b2": {"name": "mod.benchmark.b2", "panel_type": "benchmark", "title": "B2"},
+  "mod.control.c1": {"name": "mod.control.c1", "panel_type": "control", "title": "C1", "status": "complete",
+    "properties": {"severity": "high"}, "tags": {"service": "s3"}, "summary": {"ok": 1, "alarm": 1},
+    "data": {"columns": [{"name": "reason"}, {"name": "resource"}, {"name": "status"}, {"name": "region"}],
+      "rows": [{"reason": "r1", "resource": "arn1", "status": "ok", "region": "us-east-1"}, {"reason": "r2", "resource": "arn2", "status": "alarm", "region": null}]}}
+  },
+  "layout": {"name": "mod.benchmark.b1", "panel_type": "benchmark", "children": [
+    {"name": "mod.control.c1", "panel_type": "control"},
+    {"name": "mod.benchmark.b2", "panel_type": "benchmark", "children": [{"name": "mod.control.c1", "panel_type": "control"}]}
+  ]}
+}`
+
+func TestNewExecutionTreeFromSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "b1.pps")
+	if err := os.WriteFile(path, []byte(testBenchmarkSnapshot), 0600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := snapshot.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := NewExecutionTreeFromSnapshot(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run, ok := tree.ControlRuns["mod.control.c1"]
+	if !ok {
+		t.Fatal("expected control run for mod.control.c1")
+	}
+	if run.ControlId != "control.c1" || run.Severity != "high" || run.Tags["service"] != "s3" {
+		t.Errorf("unexpected control run properties: id %s, severity %s, tags %v", run.ControlId, run.Severity, run.Tags)
+	}
+	if len(run.Rows) != 2 || run.Rows[0].GetDimensionValue("region") != "us-east-1" || len(run.Rows[1].Dimensions) != 0 {
+		t.Errorf("unexpected control rows: %v", run.Rows)
+	}
+	if len(run.Parents) != 2 || len(tree.ControlRunInstances) != 2 {
+		t.Errorf("expected the control to have 2 parents, got %d", len(run.Parents))
+	}
+
+	// the control is counted once for each parent
+	b1 := tree.Root.GetGroupByName("mod.benchmark.b1")
+	if b1 == nil {
+		t.Fatal("expected result group for mod.benchmark.b1")
+	}
+	if _, ok := b1.Summary.Severity["high"]; b1.Summary.Status.Ok != 2 || b1.Summary.Status.Alarm != 2 || !ok {
+		t.Errorf("unexpected benchmark summary: %+v", b1.Summary)
+	}
+	if b2 := tree.Root.GetChildGroupByName("mod.benchmark.b2"); b2 == nil || b2.Summary.Status.Ok != 1 {
+		t.Errorf("unexpected nested benchmark: %+v", b2)
+	}
+	if keys := tree.Root.DimensionKeys; len(keys) != 1 || keys[0] != "region" {
+		t.Errorf("expected dimension keys [region], got %v", keys)
+	}
+}
@@ -3,6 +3,7 @@ package controlinit
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -11,7 +12,9 @@ import (
 	"github.com/turbot/pipe-fittings/v2/modconfig"
 	"github.com/turbot/pipe-fittings/v2/statushooks"
 	pfworkspace "github.com/turbot/pipe-fittings/v2/workspace"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
 	"github.com/turbot/powerpipe/internal/controldisplay"
+	"github.com/turbot/powerpipe/internal/exceptions"
 	"github.com/turbot/powerpipe/internal/initialisation"
 	"github.com/turbot/powerpipe/internal/resources"
 	"github.com/turbot/powerpipe/internal/workspace"
@@ -26,6 +29,10 @@ type InitData struct {
 	initialisation.InitData
 	OutputFormatter controldisplay.Formatter
 	ControlFilter   pfworkspace.ResourceFilter
+	// if a '--baseline' was passed, the results of the baseline run - the output will show the drift from this
+	Baseline *controldisplay.Baseline
+	// if an '--exceptions' file was passed, alarms which match an exception are reported as suppressed
+	Exceptions *exceptions.Exceptions
 }
 
 func (i *InitData) BaseInitData() *initialisation.InitData {
@@ -99,6 +106,21 @@ func NewInitData[T CheckTarget](ctx context.Context, cmd *cobra.Command, args ..
 	}
 	i.OutputFormatter = formatter
 
+	// if a baseline was specified, replace the output formatter with a drift formatter
+	if baselinePath := viper.GetString(localconstants.ArgBaseline); baselinePath != "" {
+		if err := i.setBaseline(baselinePath, output); err != nil {
+			i.Result.Error = err
+			return i
+		}
+	}
+
+	if exceptionsPath := viper.GetString(localconstants.ArgExceptions); exceptionsPath != "" {
+		if err := i.setExceptions(exceptionsPath); err != nil {
+			i.Result.Error = err
+			return i
+		}
+	}
+
 	i.setControlFilter()
 
 	return i
@@ -119,6 +141,43 @@ func (i *InitData) setControlFilter() {
 	}
 }
 
+func (i *InitData) setBaseline(baselinePath, output string) error {
+	switch i.Targets[0].(type) {
+	case *resources.Detection, *resources.DetectionBenchmark:
+		return fmt.Errorf("'--%s' is not supported for detections", localconstants.ArgBaseline)
+	}
+
+	baseline, err := controldisplay.LoadBaseline(baselinePath)
+	if err != nil {
+		return err
+	}
+	formatter, err := controldisplay.NewDriftFormatter(baseline, output)
+	if err != nil {
+		return err
+	}
+	i.Baseline = baseline
+	i.OutputFormatter = formatter
+	return nil
+}
+
+func (i *InitData) setExceptions(exceptionsPath string) error {
+	switch i.Targets[0].(type) {
+	case *resources.Detection, *resources.DetectionBenchmark:
+		return fmt.Errorf("'--%s' is not supported for detections", localconstants.ArgExceptions)
+	}
+
+	e, err := exceptions.Load(exceptionsPath)
+	if err != nil {
+		return err
+	}
+	// expired exceptions no longer suppress alarms - warn so they are reviewed
+	for _, expired := range e.Expired(time.Now()) {
+		i.Result.AddWarnings(fmt.Sprintf("exception '%s' (owner: %s) expired on %s - matching alarms are no longer suppressed", expired, expired.Owner, expired.Expires))
+	}
+	i.Exceptions = e
+	return nil
+}
+
 // register exporters for each of the supported check formats
 func (i *InitData) registerExporters(target modconfig.ModTreeItem) error {
 	exporters, err := controldisplay.GetExporters(target)
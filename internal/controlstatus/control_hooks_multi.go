@@ -0,0 +1,44 @@
+package controlstatus
+
+import (
+	"context"
+)
+
+// MultiControlHooks is a struct which implements ControlHooks, and forwards all events to each of its hooks
+type MultiControlHooks struct {
+	hooks []ControlHooks
+}
+
+func NewMultiControlHooks(hooks ...ControlHooks) *MultiControlHooks {
+	return &MultiControlHooks{hooks: hooks}
+}
+
+func (m *MultiControlHooks) OnStart(ctx context.Context, p *ControlProgress) {
+	for _, h := range m.hooks {
+		h.OnStart(ctx, p)
+	}
+}
+
+func (m *MultiControlHooks) OnControlStart(ctx context.Context, c ControlRunStatusProvider, p *ControlProgress) {
+	for _, h := range m.hooks {
+		h.OnControlStart(ctx, c, p)
+	}
+}
+
+func (m *MultiControlHooks) OnControlComplete(ctx context.Context, c ControlRunStatusProvider, p *ControlProgress) {
+	for _, h := range m.hooks {
+		h.OnControlComplete(ctx, c, p)
+	}
+}
+
+func (m *MultiControlHooks) OnControlError(ctx context.Context, c ControlRunStatusProvider, p *ControlProgress) {
+	for _, h := range m.hooks {
+		h.OnControlError(ctx, c, p)
+	}
+}
+
+func (m *MultiControlHooks) OnComplete(ctx context.Context, p *ControlProgress) {
+	for _, h := range m.hooks {
+		h.OnComplete(ctx, p)
+	}
+}
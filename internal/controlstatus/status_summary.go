@@ -7,6 +7,8 @@ type StatusSummary struct {
 	Info  int `json:"info"`
 	Skip  int `json:"skip"`
 	Error int `json:"error"`
+	// alarms which match an exception
+	Suppressed int `json:"suppressed"`
 }
 
 func (s *StatusSummary) PassedCount() int {
@@ -18,7 +20,7 @@ func (s *StatusSummary) FailedCount() int {
 }
 
 func (s *StatusSummary) TotalCount() int {
-	return s.Alarm + s.Ok + s.Info + s.Skip + s.Error
+	return s.Alarm + s.Ok + s.Info + s.Skip + s.Error + s.Suppressed
 }
 
 func (s *StatusSummary) Merge(summary *StatusSummary) {
@@ -27,4 +29,5 @@ func (s *StatusSummary) Merge(summary *StatusSummary) {
 	s.Info += summary.Info
 	s.Skip += summary.Skip
 	s.Error += summary.Error
+	s.Suppressed += summary.Suppressed
 }
@@ -22,7 +22,6 @@ type CheckRun struct {
 	DashboardParentImpl
 
 	Summary       *controlexecute.GroupSummary     `json:"summary"`
-	SessionId     string                           `json:"-"`
 	Root          controlexecute.ExecutionTreeNode `json:"-"`
 	BenchmarkType string                           `json:"benchmark_type"`
 
@@ -36,7 +35,7 @@ func (r *CheckRun) AsTreeNode() *steampipeconfig.SnapshotTreeNode {
 }
 
 func NewCheckRun(resource resources.DashboardLeafNode, parent dashboardtypes.DashboardParent, executionTree *DashboardExecutionTree) (*CheckRun, error) {
-	r := &CheckRun{SessionId: executionTree.sessionId}
+	r := &CheckRun{}
 	// create NewDashboardTreeRunImpl
 	// (we must create after creating the run as it requires a ref to the run)
 	r.DashboardParentImpl = newDashboardParentImpl(resource, parent, r, executionTree)
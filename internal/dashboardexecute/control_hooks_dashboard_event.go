@@ -28,27 +28,31 @@ func (c *DashboardEventControlHooks) OnControlStart(context.Context, controlstat
 }
 
 func (c *DashboardEventControlHooks) OnControlComplete(ctx context.Context, controlRun controlstatus.ControlRunStatusProvider, progress *controlstatus.ControlProgress) {
-	event := &dashboardevents.ControlComplete{
-		Control:     controlRun,
-		Progress:    progress,
-		Name:        c.CheckRun.Name,
-		ExecutionId: c.CheckRun.executionTree.id,
-		Session:     c.CheckRun.SessionId,
-		Timestamp:   time.Now(),
-	}
-	c.CheckRun.executionTree.workspace.PublishDashboardEvent(ctx, event)
+	timestamp := time.Now()
+	c.CheckRun.executionTree.publishEvent(ctx, func(subscriber executionSubscriber) dashboardevents.DashboardEvent {
+		return &dashboardevents.ControlComplete{
+			Control:     controlRun,
+			Progress:    progress,
+			Name:        c.CheckRun.Name,
+			ExecutionId: subscriber.executionId,
+			Session:     subscriber.sessionId,
+			Timestamp:   timestamp,
+		}
+	})
 }
 
 func (c *DashboardEventControlHooks) OnControlError(ctx context.Context, controlRun controlstatus.ControlRunStatusProvider, progress *controlstatus.ControlProgress) {
-	var event = &dashboardevents.ControlError{
-		Control:     controlRun,
-		Progress:    progress,
-		Name:        c.CheckRun.Name,
-		ExecutionId: c.CheckRun.executionTree.id,
-		Session:     c.CheckRun.SessionId,
-		Timestamp:   time.Now(),
-	}
-	c.CheckRun.executionTree.workspace.PublishDashboardEvent(ctx, event)
+	timestamp := time.Now()
+	c.CheckRun.executionTree.publishEvent(ctx, func(subscriber executionSubscriber) dashboardevents.DashboardEvent {
+		return &dashboardevents.ControlError{
+			Control:     controlRun,
+			Progress:    progress,
+			Name:        c.CheckRun.Name,
+			ExecutionId: subscriber.executionId,
+			Session:     subscriber.sessionId,
+			Timestamp:   timestamp,
+		}
+	})
 }
 
 func (c *DashboardEventControlHooks) OnComplete(ctx context.Context, _ *controlstatus.ControlProgress) {
@@ -0,0 +1,133 @@
+package dashboardexecute
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/turbot/pipe-fittings/v2/backend"
+	"github.com/turbot/pipe-fittings/v2/modconfig"
+	"github.com/turbot/pipe-fittings/v2/utils"
+	"github.com/turbot/powerpipe/internal/dashboardevents"
+)
+
+// executionSubscriber is a session subscribed to the events of an execution
+// sessions which request the same root resource, inputs and search path share a single execution
+type executionSubscriber struct {
+	sessionId string
+	// the execution id used in the events sent to this session
+	executionId string
+}
+
+// executionShareKey returns the key used to identify executions which may be shared
+// i.e. executions of the same root resource with the same inputs and search path
+func executionShareKey(rootResource modconfig.ModTreeItem, inputs *InputValues, opts ...backend.BackendOption) (string, error) {
+	var cfg backend.BackendConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	key := struct {
+		Resource         string          `json:"resource"`
+		Inputs           map[string]any  `json:"inputs,omitempty"`
+		DateTimeRange    utils.TimeRange `json:"datetime_range"`
+		SearchPath       []string        `json:"search_path,omitempty"`
+		SearchPathPrefix []string        `json:"search_path_prefix,omitempty"`
+	}{
+		Resource:         rootResource.Name(),
+		SearchPath:       cfg.SearchPathConfig.SearchPath,
+		SearchPathPrefix: cfg.SearchPathConfig.SearchPathPrefix,
+	}
+	if inputs != nil {
+		key.Inputs = inputs.Inputs
+		key.DateTimeRange = inputs.DateTimeRange
+	}
+	// map keys are sorted when marshalled, so the key is deterministic
+	res, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	return string(res), nil
+}
+
+// subscribe adds a subscriber to the execution
+// it returns false if the execution has already completed, so cannot be subscribed to,
+// and whether the execution has already started (i.e. the subscriber has missed the ExecutionStarted event)
+func (e *DashboardExecutionTree) subscribe(subscriber executionSubscriber) (subscribed, started bool) {
+	e.subscriberLock.Lock()
+	defer e.subscriberLock.Unlock()
+
+	if e.complete {
+		return false, e.started
+	}
+	e.subscribers = append(e.subscribers, subscriber)
+	return true, e.started
+}
+
+// unsubscribe removes the subscriber from the execution, returning the number of remaining subscribers
+func (e *DashboardExecutionTree) unsubscribe(sessionId, executionId string) int {
+	e.subscriberLock.Lock()
+	defer e.subscriberLock.Unlock()
+
+	e.subscribers = slices.DeleteFunc(e.subscribers, func(s executionSubscriber) bool {
+		return s.sessionId == sessionId && s.executionId == e.subscriberExecutionId(executionId)
+	})
+	return len(e.subscribers)
+}
+
+func (e *DashboardExecutionTree) subscriberCount() int {
+	e.subscriberLock.Lock()
+	defer e.subscriberLock.Unlock()
+
+	return len(e.subscribers)
+}
+
+// getSubscribers returns the current subscribers
+// if started or complete is set, the execution state is updated under the same lock,
+// so a subscriber is either included or is aware it has missed the event
+func (e *DashboardExecutionTree) getSubscribers(started, complete bool) []executionSubscriber {
+	e.subscriberLock.Lock()
+	defer e.subscriberLock.Unlock()
+
+	e.started = e.started || started
+	e.complete = e.complete || complete
+	return slices.Clone(e.subscribers)
+}
+
+// subscriberExecutionId returns the execution id used in events for a subscriber with the given client execution id
+func (e *DashboardExecutionTree) subscriberExecutionId(executionId string) string {
+	if executionId == "" {
+		return e.id
+	}
+	return executionId
+}
+
+// newExecutionStartedEvent builds the ExecutionStarted event for a subscriber
+func (e *DashboardExecutionTree) newExecutionStartedEvent(subscriber executionSubscriber, panels map[string]any) *dashboardevents.ExecutionStarted {
+	return &dashboardevents.ExecutionStarted{
+		Root:        e.Root,
+		Session:     subscriber.sessionId,
+		ExecutionId: subscriber.executionId,
+		Panels:      panels,
+		Inputs:      e.inputValues,
+		Variables:   e.referencedVariables,
+		StartTime:   e.startTime,
+	}
+}
+
+// publishCurrentState sends an ExecutionStarted event containing the current state of the panels to a subscriber
+// which subscribed after the execution started - subsequent updates are sent to the subscriber as they occur
+func (e *DashboardExecutionTree) publishCurrentState(ctx context.Context, subscriber executionSubscriber) error {
+	panels, err := utils.JsonCloneToMap(e.BuildSnapshotPanels())
+	if err != nil {
+		return err
+	}
+	e.workspace.PublishDashboardEvent(ctx, e.newExecutionStartedEvent(subscriber, panels))
+	return nil
+}
+
+// publishEvent publishes an event to each subscriber of the execution
+func (e *DashboardExecutionTree) publishEvent(ctx context.Context, buildEvent func(subscriber executionSubscriber) dashboardevents.DashboardEvent) {
+	for _, subscriber := range e.getSubscribers(false, false) {
+		e.workspace.PublishDashboardEvent(ctx, buildEvent(subscriber))
+	}
+}
@@ -0,0 +1,70 @@
+package dashboardexecute
+
+import (
+	"testing"
+
+	"github.com/turbot/pipe-fittings/v2/backend"
+	"github.com/turbot/powerpipe/internal/resources"
+)
+
+func TestExecutionShareKey(t *testing.T) {
+	dashboard := &resources.Dashboard{}
+	dashboard.FullName = "mod.dashboard.a"
+	other := &resources.Dashboard{}
+	other.FullName = "mod.dashboard.b"
+
+	key := func(d *resources.Dashboard, inputs map[string]any, opts ...backend.BackendOption) string {
+		k, err := executionShareKey(d, &InputValues{Inputs: inputs}, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+
+	base := key(dashboard, map[string]any{"input.a": "1", "input.b": "2"})
+	if key(dashboard, map[string]any{"input.b": "2", "input.a": "1"}) != base {
+		t.Errorf("expected identical inputs to give the same key")
+	}
+	if key(dashboard, map[string]any{"input.a": "1", "input.b": "3"}) == base {
+		t.Errorf("expected different inputs to give a different key")
+	}
+	if key(other, map[string]any{"input.a": "1", "input.b": "2"}) == base {
+		t.Errorf("expected a different root resource to give a different key")
+	}
+	searchPath := backend.WithSearchPathConfig(backend.SearchPathConfig{SearchPath: []string{"aws"}})
+	if key(dashboard, map[string]any{"input.a": "1", "input.b": "2"}, searchPath) == base {
+		t.Errorf("expected a different search path to give a different key")
+	}
+}
+
+func TestExecutionSubscribers(t *testing.T) {
+	e := &DashboardExecutionTree{id: "tree"}
+	e.subscribers = []executionSubscriber{{sessionId: "s1", executionId: e.subscriberExecutionId("")}}
+
+	if subscribed, started := e.subscribe(executionSubscriber{sessionId: "s2", executionId: e.subscriberExecutionId("x")}); !subscribed || started {
+		t.Fatalf("expected to subscribe to an execution which has not started")
+	}
+	// once the ExecutionStarted event is sent, new subscribers must be sent the current state
+	if len(e.getSubscribers(true, false)) != 2 {
+		t.Fatalf("expected 2 subscribers")
+	}
+	if _, started := e.subscribe(executionSubscriber{sessionId: "s3", executionId: "tree"}); !started {
+		t.Errorf("expected the execution to have started")
+	}
+
+	if remaining := e.unsubscribe("s2", "x"); remaining != 2 {
+		t.Errorf("expected 2 remaining subscribers, got %d", remaining)
+	}
+	if remaining := e.unsubscribe("s1", ""); remaining != 1 {
+		t.Errorf("expected 1 remaining subscriber, got %d", remaining)
+	}
+
+	// once complete, an execution cannot be subscribed to
+	e.getSubscribers(false, true)
+	if subscribed, _ := e.subscribe(executionSubscriber{sessionId: "s4", executionId: "tree"}); subscribed {
+		t.Errorf("expected a complete execution not to accept subscribers")
+	}
+	if remaining := e.unsubscribe("s3", ""); remaining != 0 {
+		t.Errorf("expected no remaining subscribers, got %d", remaining)
+	}
+}
@@ -28,7 +28,17 @@ type DashboardExecutionTree struct {
 	Root dashboardtypes.DashboardTreeRun
 
 	dashboardName string
-	sessionId     string
+	// the sessions subscribed to the events of the execution
+	// and whether the ExecutionStarted and ExecutionComplete events have been sent to them
+	subscribers    []executionSubscriber
+	subscriberLock sync.Mutex
+	started        bool
+	complete       bool
+	// the key used to share the execution between sessions - empty if the execution may not be shared
+	shareKey string
+	// the start time and referenced variables, used to send the ExecutionStarted event to late subscribers
+	startTime           time.Time
+	referencedVariables map[string]string
 	// map of clients, keyed by connection string - we will close this at end of execution
 	clientMap *db_client.ClientMap
 	// map of server-managed clients, keyed by connection string - we will NOT close this
@@ -47,21 +57,50 @@ type DashboardExecutionTree struct {
 	database         connection.ConnectionStringProvider
 	searchPathConfig backend.SearchPathConfig
 	DateTimeRange    utils.TimeRange
+	// if set, detections are run incrementally, using and updating the high-water marks of the state
+	detectionState DetectionStateProvider
+	// the time allowed for late events before they are evaluated by an incremental run
+	incrementalLag time.Duration
+	// if set, detections only report result rows whose fingerprint has not been seen before
+	fingerprintState DetectionFingerprintProvider
+	// if set, detection benchmark runs are pruned to the detections which match the filter
+	detectionFilter *DetectionFilter
+	// if set, detections are reported without being run
+	dryRun bool
+	// if set, tables with a page size retain their full result and send only the first page
+	// (this is only the case for interactive executions, which may request further pages)
+	pageTables bool
+	// the query cache, and the cache ttl for resources which do not override it
+	queryCache      *QueryCache
+	defaultCacheTtl time.Duration
 }
 
-func (e *DashboardExecutor) newDashboardExecutionTree(rootResource modconfig.ModTreeItem, sessionId string, workspace *workspace.PowerpipeWorkspace, inputs *InputValues, opts ...backend.BackendOption) (*DashboardExecutionTree, error) {
+func (e *DashboardExecutor) newDashboardExecutionTree(rootResource modconfig.ModTreeItem, sessionId, executionId string, workspace *workspace.PowerpipeWorkspace, inputs *InputValues, opts ...backend.BackendOption) (*DashboardExecutionTree, error) {
 	// now populate the DashboardExecutionTree
 	executionTree := &DashboardExecutionTree{
 		dashboardName:    rootResource.Name(),
-		sessionId:        sessionId,
 		defaultClientMap: e.defaultClient,
 		clientMap:        db_client.NewClientMap(),
 		runs:             make(map[string]dashboardtypes.DashboardTreeRun),
 		workspace:        workspace,
 		runComplete:      make(chan dashboardtypes.DashboardTreeRun, 1),
 		inputValues:      make(map[string]any),
+		queryCache:       e.queryCache,
 	}
 	executionTree.id = fmt.Sprintf("%p", executionTree)
+	// the session requesting the execution is the first subscriber
+	executionTree.subscribers = []executionSubscriber{{
+		sessionId:   sessionId,
+		executionId: executionTree.subscriberExecutionId(executionId),
+	}}
+
+	// a dashboard may override the default cache ttl for all of its panels
+	if e.queryCache != nil {
+		executionTree.defaultCacheTtl = e.queryCache.defaultTtl
+		if p, ok := rootResource.(resources.CacheTtlProvider); ok && p.GetCacheTtl() != nil {
+			executionTree.defaultCacheTtl = time.Duration(*p.GetCacheTtl()) * time.Second
+		}
+	}
 
 	// apply options and use to override the default search path
 	var cfg backend.BackendConfig
@@ -82,6 +121,11 @@ func (e *DashboardExecutor) newDashboardExecutionTree(rootResource modconfig.Mod
 	executionTree.database = database
 	executionTree.searchPathConfig = searchPathConfig
 
+	// the detection filter must be set before creating the root run, as it prunes the detection benchmark runs
+	if inputs != nil {
+		executionTree.detectionFilter = inputs.DetectionFilter
+	}
+
 	// create the root run node (either a report run or a counter run)
 	root, err := executionTree.createRootItem(rootResource)
 	if err != nil {
@@ -174,32 +218,30 @@ func (e *DashboardExecutionTree) Execute(ctx context.Context) {
 		e.SetError(ctx, err)
 		return
 	}
-	workspace.PublishDashboardEvent(ctx, &dashboardevents.ExecutionStarted{
-		Root:        e.Root,
-		Session:     e.sessionId,
-		ExecutionId: e.id,
-		Panels:      immutablePanels,
-		Inputs:      e.inputValues,
-		Variables:   referencedVariables,
-		StartTime:   startTime,
-	})
+	e.startTime = startTime
+	e.referencedVariables = referencedVariables
+	for _, subscriber := range e.getSubscribers(true, false) {
+		workspace.PublishDashboardEvent(ctx, e.newExecutionStartedEvent(subscriber, immutablePanels))
+	}
 	defer func() {
+		endTime := time.Now()
+		for _, subscriber := range e.getSubscribers(false, true) {
+			ev := &dashboardevents.ExecutionComplete{
+				Root:             e.Root,
+				Session:          subscriber.sessionId,
+				ExecutionId:      subscriber.executionId,
+				Panels:           panels,
+				Inputs:           e.inputValues,
+				Variables:        referencedVariables,
+				SearchPath:       searchPath,
+				DateTimeRange:    e.DateTimeRange,
+				SearchPathPrefix: e.searchPathConfig.SearchPathPrefix,
+				StartTime:        startTime,
+				EndTime:          endTime,
+			}
 
-		ev := &dashboardevents.ExecutionComplete{
-			Root:             e.Root,
-			Session:          e.sessionId,
-			ExecutionId:      e.id,
-			Panels:           panels,
-			Inputs:           e.inputValues,
-			Variables:        referencedVariables,
-			SearchPath:       searchPath,
-			DateTimeRange:    e.DateTimeRange,
-			SearchPathPrefix: e.searchPathConfig.SearchPathPrefix,
-			StartTime:        startTime,
-			EndTime:          time.Now(),
+			workspace.PublishDashboardEvent(ctx, ev)
 		}
-
-		workspace.PublishDashboardEvent(ctx, ev)
 	}()
 
 	slog.Debug("begin DashboardExecutionTree.Execute")
@@ -269,6 +311,10 @@ func (e *DashboardExecutionTree) SetInputValues(inputValues *InputValues) {
 	// if a time range has been passed, set the detection time range
 	// (if time range is not set, From and To will be nil - this is expected and handled)
 	e.DateTimeRange = inputValues.DateTimeRange
+	e.detectionState = inputValues.DetectionState
+	e.incrementalLag = inputValues.IncrementalLag
+	e.fingerprintState = inputValues.FingerprintState
+	e.dryRun = inputValues.DryRun
 }
 
 // ChildCompleteChan implements DashboardParent
@@ -374,11 +420,25 @@ func (*DashboardExecutionTree) GetResource() resources.DashboardLeafNode {
 	panic("should never call for DashboardExecutionTree")
 }
 
+// ask the provider for the connection string, passing the time range filter
+func (e *DashboardExecutionTree) getConnectionString(csp connection.ConnectionStringProvider) (string, error) {
+	return getConnectionStringForTimeRange(csp, e.DateTimeRange)
+}
+
+func getConnectionStringForTimeRange(csp connection.ConnectionStringProvider, timeRange utils.TimeRange) (string, error) {
+	filter := &backend.DatabaseFilters{From: timeRange.From, To: timeRange.To}
+	return csp.GetConnectionString(connection.WithFilter(filter))
+}
+
 // function to get a client from one of the client maps
 func (e *DashboardExecutionTree) getClient(ctx context.Context, csp connection.ConnectionStringProvider, searchPathConfig backend.SearchPathConfig) (*db_client.DbClient, error) {
-	// ask the provider for the connection string, passing the filter
-	filter := &backend.DatabaseFilters{From: e.DateTimeRange.From, To: e.DateTimeRange.To}
-	cs, err := csp.GetConnectionString(connection.WithFilter(filter))
+	return e.getClientForTimeRange(ctx, csp, searchPathConfig, e.DateTimeRange)
+}
+
+// getClientForTimeRange gets a client for the connection, filtered to the given time range
+// (this is used by incremental detection runs, which each evaluate their own time range)
+func (e *DashboardExecutionTree) getClientForTimeRange(ctx context.Context, csp connection.ConnectionStringProvider, searchPathConfig backend.SearchPathConfig, timeRange utils.TimeRange) (*db_client.DbClient, error) {
+	cs, err := getConnectionStringForTimeRange(csp, timeRange)
 	if err != nil {
 		return nil, err
 	}
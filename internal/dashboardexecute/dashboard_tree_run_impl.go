@@ -166,8 +166,18 @@ func (r *DashboardTreeRunImpl) setStatus(ctx context.Context, status dashboardty
 	// raise LeafNodeUpdated event
 	// TODO [node_reuse] do this a different way https://github.com/turbot/steampipe/issues/2919
 	// TACTICAL: pass the full run struct - 'r.run', rather than ourselves - so we serialize all properties
-	e, _ := dashboardevents.NewLeafNodeUpdate(r.run, r.executionTree.sessionId, r.executionTree.id)
-	r.executionTree.workspace.PublishDashboardEvent(ctx, e)
+	e, err := dashboardevents.NewLeafNodeUpdate(r.run, "", "")
+	if err != nil {
+		slog.Warn("failed to build leaf node updated event", "name", r.Name, "error", err)
+		return
+	}
+	// send the update to every session subscribed to the execution
+	r.executionTree.publishEvent(ctx, func(subscriber executionSubscriber) dashboardevents.DashboardEvent {
+		ev := *e
+		ev.Session = subscriber.sessionId
+		ev.ExecutionId = subscriber.executionId
+		return &ev
+	})
 
 }
 
@@ -11,6 +11,7 @@ import (
 	"github.com/turbot/pipe-fittings/v2/modconfig"
 	"github.com/turbot/pipe-fittings/v2/schema"
 	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+	"github.com/turbot/pipe-fittings/v2/utils"
 	"github.com/turbot/powerpipe/internal/controlstatus"
 )
 
@@ -34,6 +35,9 @@ type DetectionBenchmarkDisplay struct {
 	Display       string            `json:"display,omitempty"`
 	Type          string            `json:"type,omitempty"`
 
+	// the time range evaluated by the run (only set for the root group)
+	DateTimeRange *utils.TimeRange `json:"datetime_range,omitempty"`
+
 	// the overall summary of the group
 	Summary *DetectionBenchmarkSummary `json:"summary"`
 	// child result groups
@@ -3,6 +3,7 @@ package dashboardexecute
 import (
 	"time"
 
+	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
 	"github.com/turbot/powerpipe/internal/controlexecute"
 
 	"github.com/turbot/powerpipe/internal/controlstatus"
@@ -22,6 +23,8 @@ type DetectionBenchmarkDisplayTree struct {
 	Workspace *workspace.PowerpipeWorkspace `json:"-"`
 	// for now just using DetectionRuns
 	DetectionRuns []*DetectionRun `json:"-"`
+	// the snapshot the tree was built from - used for snapshot output and export
+	Snapshot *steampipeconfig.SteampipeSnapshot `json:"-"`
 }
 
 // IsExportSourceData implements ExportSourceData
@@ -52,11 +52,19 @@ func NewDetectionBenchmarkRun(container *resources.DetectionBenchmark, parent da
 		var err error
 		switch i := child.(type) {
 		case *resources.DetectionBenchmark:
+			// prune benchmarks which contain no detections matching the filter
+			if !executionTree.detectionFilter.ShouldIncludeItem(i) {
+				continue
+			}
 			childRun, err = NewDetectionBenchmarkRun(i, r, executionTree)
 			if err != nil {
 				return nil, err
 			}
 		case *resources.Detection:
+			if !executionTree.detectionFilter.ShouldIncludeDetection(i) {
+				slog.Debug("skipping detection which does not match the filter", "name", i.Name())
+				continue
+			}
 			childRun, err = NewDetectionRun(i, r, executionTree)
 			if err != nil {
 				return nil, err
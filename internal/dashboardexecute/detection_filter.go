@@ -0,0 +1,135 @@
+package dashboardexecute
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	typehelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/pipe-fittings/v2/modconfig"
+	"github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/resources"
+	"github.com/turbot/powerpipe/internal/workspace"
+)
+
+// the severity comparison operators - the two character operators must come first so they are matched first
+var severityOperators = []string{">=", "<=", "!=", ">", "<", "="}
+
+// SeverityFilter matches detection severities against a severity, e.g. '>=high'
+type SeverityFilter struct {
+	Operator string
+	Severity string
+}
+
+// ParseSeverityFilter parses a severity optionally preceded by one of the operators >=, >, <=, <, = or !=
+// a severity with no operator only matches that severity
+func ParseSeverityFilter(value string) (*SeverityFilter, error) {
+	res := &SeverityFilter{Operator: "="}
+	severity := strings.TrimSpace(value)
+	for _, op := range severityOperators {
+		if strings.HasPrefix(severity, op) {
+			res.Operator = op
+			severity = strings.TrimSpace(strings.TrimPrefix(severity, op))
+			break
+		}
+	}
+	severity = strings.ToLower(severity)
+	if !slices.Contains(constants.Severities, severity) {
+		return nil, fmt.Errorf("invalid severity '%s' - must be one of: %s, optionally preceded by one of: %s", value, strings.Join(constants.Severities, ", "), strings.Join(severityOperators, ", "))
+	}
+	res.Severity = severity
+	return res, nil
+}
+
+// Matches returns whether the severity satisfies the filter (a detection with no severity has severity 'none')
+func (f *SeverityFilter) Matches(severity string) bool {
+	rank := severityRank(severity)
+	filterRank := severityRank(f.Severity)
+	switch f.Operator {
+	case ">=":
+		return rank >= filterRank
+	case ">":
+		return rank > filterRank
+	case "<=":
+		return rank <= filterRank
+	case "<":
+		return rank < filterRank
+	case "!=":
+		return rank != filterRank
+	default:
+		return rank == filterRank
+	}
+}
+
+// severityRank returns the position of the severity in the list of severities (unknown severities rank as 'none')
+func severityRank(severity string) int {
+	return max(slices.Index(constants.Severities, strings.ToLower(severity)), 0)
+}
+
+// DetectionFilter selects which detections of a detection benchmark are run
+// the detections which do not match are pruned from the execution tree, as are any benchmarks left with no detections
+type DetectionFilter struct {
+	tagPredicate func(modconfig.HclResource) bool
+	severity     *SeverityFilter
+}
+
+// NewDetectionFilter creates a filter from the --tag and --severity args
+// if neither is set, nil is returned
+func NewDetectionFilter(tags []string, severity string) (*DetectionFilter, error) {
+	if len(tags) == 0 && severity == "" {
+		return nil, nil
+	}
+	res := &DetectionFilter{}
+	if len(tags) > 0 {
+		res.tagPredicate = workspace.ResourceFilterFromTagArgs(tags).WherePredicate
+	}
+	if severity != "" {
+		severityFilter, err := ParseSeverityFilter(severity)
+		if err != nil {
+			return nil, err
+		}
+		res.severity = severityFilter
+	}
+	return res, nil
+}
+
+// ShouldIncludeDetection returns whether the detection matches the filter
+func (f *DetectionFilter) ShouldIncludeDetection(detection *resources.Detection) bool {
+	if f == nil {
+		return true
+	}
+	if f.tagPredicate != nil && !f.tagPredicate(detection) {
+		return false
+	}
+	if f.severity != nil && !f.severity.Matches(typehelpers.SafeString(detection.Severity)) {
+		return false
+	}
+	return true
+}
+
+// ShouldIncludeItem returns whether the item is a detection which matches the filter,
+// or a benchmark containing at least one detection which matches the filter
+func (f *DetectionFilter) ShouldIncludeItem(item modconfig.ModTreeItem) bool {
+	if f == nil {
+		return true
+	}
+	if detection, ok := item.(*resources.Detection); ok {
+		return f.ShouldIncludeDetection(detection)
+	}
+	return slices.ContainsFunc(item.GetChildren(), f.ShouldIncludeItem)
+}
+
+// SkippedDetections returns the names of the detections within the item which do not match the filter
+func (f *DetectionFilter) SkippedDetections(item modconfig.ModTreeItem) []string {
+	if detection, ok := item.(*resources.Detection); ok {
+		if f.ShouldIncludeDetection(detection) {
+			return nil
+		}
+		return []string{detection.Name()}
+	}
+	var res []string
+	for _, child := range item.GetChildren() {
+		res = append(res, f.SkippedDetections(child)...)
+	}
+	return res
+}
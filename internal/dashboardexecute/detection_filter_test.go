@@ -0,0 +1,170 @@
+package dashboardexecute
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/turbot/powerpipe/internal/resources"
+)
+
+func TestParseSeverityFilter(t *testing.T) {
+	tests := []struct {
+		value    string
+		operator string
+		severity string
+		wantErr  bool
+	}{
+		{value: "high", operator: "=", severity: "high"},
+		{value: ">=high", operator: ">=", severity: "high"},
+		{value: "> medium", operator: ">", severity: "medium"},
+		{value: "<=LOW", operator: "<=", severity: "low"},
+		{value: "!=none", operator: "!=", severity: "none"},
+		{value: "=critical", operator: "=", severity: "critical"},
+		{value: ">=severe", wantErr: true},
+		{value: "=>high", wantErr: true},
+		{value: ">=", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			filter, err := ParseSeverityFilter(test.value)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", filter)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if filter.Operator != test.operator || filter.Severity != test.severity {
+				t.Errorf("expected %s%s, got %s%s", test.operator, test.severity, filter.Operator, filter.Severity)
+			}
+		})
+	}
+}
+
+func TestSeverityFilterMatches(t *testing.T) {
+	tests := []struct {
+		filter   string
+		severity string
+		expected bool
+	}{
+		{filter: ">=high", severity: "critical", expected: true},
+		{filter: ">=high", severity: "HIGH", expected: true},
+		{filter: ">=high", severity: "medium", expected: false},
+		{filter: ">=high", severity: "", expected: false},
+		{filter: ">medium", severity: "medium", expected: false},
+		{filter: "<medium", severity: "low", expected: true},
+		{filter: "<medium", severity: "", expected: true},
+		{filter: "<=medium", severity: "medium", expected: true},
+		{filter: "!=low", severity: "high", expected: true},
+		{filter: "low", severity: "low", expected: true},
+		{filter: "low", severity: "high", expected: false},
+	}
+	for _, test := range tests {
+		filter, err := ParseSeverityFilter(test.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := filter.Matches(test.severity); got != test.expected {
+			t.Errorf("%s matching '%s': expected %v, got %v", test.filter, test.severity, test.expected, got)
+		}
+	}
+}
+
+func newTestFilterDetection(name, severity string, tags map[string]string) *resources.Detection {
+	detection := &resources.Detection{}
+	detection.FullName = name
+	if severity != "" {
+		detection.Severity = &severity
+	}
+	detection.Tags = tags
+	return detection
+}
+
+func newTestFilterBenchmark() *resources.DetectionBenchmark {
+	child := &resources.DetectionBenchmark{}
+	child.FullName = "mod.detection_benchmark.child"
+	child.AddChild(
+		newTestFilterDetection("mod.detection.low", "low", map[string]string{"service": "aws/s3"}),
+		newTestFilterDetection("mod.detection.none", "", nil),
+	)
+	root := &resources.DetectionBenchmark{}
+	root.FullName = "mod.detection_benchmark.root"
+	root.AddChild(
+		newTestFilterDetection("mod.detection.critical", "critical", map[string]string{"service": "aws/iam"}),
+		newTestFilterDetection("mod.detection.high", "high", map[string]string{"service": "aws/s3"}),
+		child,
+	)
+	return root
+}
+
+func TestDetectionFilter(t *testing.T) {
+	benchmark := newTestFilterBenchmark()
+	child := benchmark.GetChildren()[2]
+
+	tests := []struct {
+		name            string
+		tags            []string
+		severity        string
+		expectedSkipped []string
+		includeChild    bool
+	}{
+		{
+			name:            "severity",
+			severity:        ">=high",
+			expectedSkipped: []string{"mod.detection.low", "mod.detection.none"},
+			includeChild:    false,
+		},
+		{
+			name:            "tag",
+			tags:            []string{"service=aws/s3"},
+			expectedSkipped: []string{"mod.detection.critical", "mod.detection.none"},
+			includeChild:    true,
+		},
+		{
+			name:            "tag and severity",
+			tags:            []string{"service=aws/s3"},
+			severity:        "high",
+			expectedSkipped: []string{"mod.detection.critical", "mod.detection.low", "mod.detection.none"},
+			includeChild:    false,
+		},
+		{
+			name:            "not equals tag",
+			tags:            []string{"service!=aws/iam"},
+			expectedSkipped: []string{"mod.detection.critical"},
+			includeChild:    true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter, err := NewDetectionFilter(test.tags, test.severity)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if skipped := filter.SkippedDetections(benchmark); !slices.Equal(skipped, test.expectedSkipped) {
+				t.Errorf("expected skipped detections %v, got %v", test.expectedSkipped, skipped)
+			}
+			if got := filter.ShouldIncludeItem(child); got != test.includeChild {
+				t.Errorf("expected child benchmark included to be %v, got %v", test.includeChild, got)
+			}
+		})
+	}
+}
+
+func TestNilDetectionFilter(t *testing.T) {
+	filter, err := NewDetectionFilter(nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter != nil {
+		t.Fatalf("expected no filter when no tags or severity are passed")
+	}
+	benchmark := newTestFilterBenchmark()
+	if skipped := filter.SkippedDetections(benchmark); len(skipped) != 0 {
+		t.Errorf("expected no skipped detections, got %v", skipped)
+	}
+	if !filter.ShouldIncludeItem(&resources.DetectionBenchmark{}) {
+		t.Errorf("expected an empty benchmark to be included when there is no filter")
+	}
+}
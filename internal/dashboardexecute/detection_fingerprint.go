@@ -0,0 +1,98 @@
+package dashboardexecute
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/utils"
+
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+)
+
+// Fingerprint returns a stable fingerprint of a detection result row
+// this is computed from the detection name and the values of the fingerprint columns of the detection
+// (or all columns, if the detection does not declare fingerprint columns)
+func (r *DetectionRun) Fingerprint(row map[string]any) string {
+	columns := r.Resource.FingerprintColumns
+	if len(columns) == 0 {
+		columns = make([]string, 0, len(row))
+		for c := range row {
+			columns = append(columns, c)
+		}
+		sort.Strings(columns)
+	}
+
+	// build a list of column names and values, so the encoding does not depend on map ordering
+	values := make([]any, 0, 2*len(columns)+1)
+	values = append(values, r.Resource.Name())
+	for _, c := range columns {
+		values = append(values, c, row[c])
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		// values which cannot be serialised are fingerprinted using their string representation
+		data = []byte(fmt.Sprintf("%v", values))
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// setFingerprints populates the fingerprints of the result rows
+// if only first seen results are reported, rows whose fingerprint has been seen before are removed
+// an error is returned if the result does not contain all fingerprint columns of the detection,
+// as the rows would otherwise all share the same fingerprint
+func (r *DetectionRun) setFingerprints() error {
+	state := r.executionTree.fingerprintState
+	if r.Data == nil || (state == nil && len(r.Resource.FingerprintColumns) == 0) {
+		return nil
+	}
+	if err := r.validateFingerprintColumns(); err != nil {
+		return err
+	}
+
+	seen := r.executionTree.startTime
+	if seen.IsZero() {
+		seen = time.Now()
+	}
+	// build new data rather than updating the rows in place, as the data may be shared with the query cache
+	data := &dashboardtypes.LeafData{
+		Columns:   r.Data.Columns,
+		Rows:      make([]map[string]any, 0, len(r.Data.Rows)),
+		Truncated: r.Data.Truncated,
+	}
+	fingerprints := make([]string, 0, len(r.Data.Rows))
+	for _, row := range r.Data.Rows {
+		fingerprint := r.Fingerprint(row)
+		if state != nil && !state.RecordFingerprint(r.Resource.Name(), fingerprint, seen) {
+			continue
+		}
+		data.Rows = append(data.Rows, row)
+		fingerprints = append(fingerprints, fingerprint)
+	}
+	r.Data = data
+	r.Fingerprints = fingerprints
+	return nil
+}
+
+// validateFingerprintColumns returns an error if any fingerprint column of the detection is not a result column
+func (r *DetectionRun) validateFingerprintColumns() error {
+	columns := make(map[string]struct{}, len(r.Data.Columns))
+	for _, c := range r.Data.Columns {
+		columns[c.Name] = struct{}{}
+	}
+	var missing []string
+	for _, c := range r.Resource.FingerprintColumns {
+		if _, ok := columns[c]; !ok {
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("fingerprint %s %s not returned by the detection query", utils.Pluralize("column", len(missing)), strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package dashboardexecute
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/queryresult"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+	"github.com/turbot/powerpipe/internal/resources"
+)
+
+type testFingerprintState map[string]bool
+
+func (s testFingerprintState) RecordFingerprint(detection, fingerprint string, _ time.Time) bool {
+	key := detection + "/" + fingerprint
+	if s[key] {
+		return false
+	}
+	s[key] = true
+	return true
+}
+
+func newTestFingerprintRun(name string, fingerprintColumns []string, rows ...map[string]any) *DetectionRun {
+	detection := &resources.Detection{FingerprintColumns: fingerprintColumns}
+	detection.FullName = name
+	data := &dashboardtypes.LeafData{Rows: rows}
+	// the columns are those of the rows
+	columns := make(map[string]struct{})
+	for _, row := range rows {
+		for c := range row {
+			if _, ok := columns[c]; !ok {
+				columns[c] = struct{}{}
+				data.Columns = append(data.Columns, &queryresult.ColumnDef{Name: c})
+			}
+		}
+	}
+	r := &DetectionRun{Resource: detection, Data: data}
+	r.executionTree = &DashboardExecutionTree{}
+	return r
+}
+
+func TestDetectionRunFingerprint(t *testing.T) {
+	timestamp := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	row := map[string]any{"user": "alice", "ip": "10.0.0.1", "timestamp": timestamp}
+
+	r := newTestFingerprintRun("mod.detection.a", []string{"user", "ip"})
+	fingerprint := r.Fingerprint(row)
+	if fingerprint != r.Fingerprint(map[string]any{"ip": "10.0.0.1", "user": "alice", "timestamp": timestamp.Add(time.Hour)}) {
+		t.Errorf("expected columns which are not fingerprint columns to be ignored")
+	}
+	if fingerprint == r.Fingerprint(map[string]any{"user": "bob", "ip": "10.0.0.1"}) {
+		t.Errorf("expected a different fingerprint for different fingerprint column values")
+	}
+	if fingerprint == newTestFingerprintRun("mod.detection.b", []string{"user", "ip"}).Fingerprint(row) {
+		t.Errorf("expected a different fingerprint for a different detection")
+	}
+
+	// with no fingerprint columns, all columns are used
+	all := newTestFingerprintRun("mod.detection.a", nil)
+	if all.Fingerprint(row) != all.Fingerprint(map[string]any{"timestamp": timestamp, "ip": "10.0.0.1", "user": "alice"}) {
+		t.Errorf("expected the fingerprint not to depend on column order")
+	}
+	if all.Fingerprint(row) == all.Fingerprint(map[string]any{"user": "alice", "ip": "10.0.0.1", "timestamp": timestamp.Add(time.Hour)}) {
+		t.Errorf("expected all columns to be fingerprinted when no fingerprint columns are declared")
+	}
+}
+
+func TestDetectionRunSetFingerprints(t *testing.T) {
+	rows := []map[string]any{
+		{"user": "alice", "count": 1},
+		{"user": "bob", "count": 2},
+		{"user": "alice", "count": 3},
+	}
+
+	// fingerprint columns declared, without dedupe - all rows are reported with their fingerprints
+	r := newTestFingerprintRun("mod.detection.a", []string{"user"}, rows...)
+	if err := r.setFingerprints(); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Data.Rows) != 3 || len(r.Fingerprints) != 3 {
+		t.Fatalf("expected 3 rows and fingerprints, got %d and %d", len(r.Data.Rows), len(r.Fingerprints))
+	}
+
+	// with dedupe, only first seen fingerprints are reported
+	state := testFingerprintState{}
+	r = newTestFingerprintRun("mod.detection.a", []string{"user"}, rows...)
+	source := r.Data
+	r.executionTree.fingerprintState = state
+	if err := r.setFingerprints(); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Data.Rows) != 2 || r.Data.Rows[0]["user"] != "alice" || r.Data.Rows[1]["user"] != "bob" {
+		t.Errorf("expected the first alice and bob rows, got %v", r.Data.Rows)
+	}
+	if len(source.Rows) != 3 {
+		t.Errorf("expected the source data not to be modified")
+	}
+
+	// a subsequent run reports only new fingerprints
+	r = newTestFingerprintRun("mod.detection.a", []string{"user"}, append(rows, map[string]any{"user": "carol"})...)
+	r.executionTree.fingerprintState = state
+	if err := r.setFingerprints(); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Data.Rows) != 1 || r.Data.Rows[0]["user"] != "carol" {
+		t.Errorf("expected only the carol row, got %v", r.Data.Rows)
+	}
+
+	// with neither fingerprint columns nor dedupe, fingerprints are not computed
+	r = newTestFingerprintRun("mod.detection.a", nil, rows...)
+	if err := r.setFingerprints(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Fingerprints != nil {
+		t.Errorf("expected no fingerprints, got %v", r.Fingerprints)
+	}
+}
+
+func TestDetectionRunSetFingerprintsMissingColumn(t *testing.T) {
+	state := testFingerprintState{}
+	r := newTestFingerprintRun("mod.detection.a", []string{"user", "usr"}, map[string]any{"user": "alice"}, map[string]any{"user": "bob"})
+	r.executionTree.fingerprintState = state
+	err := r.setFingerprints()
+	if err == nil || !strings.Contains(err.Error(), "usr") {
+		t.Fatalf("expected an error for the missing fingerprint column, got %v", err)
+	}
+	if len(state) != 0 {
+		t.Errorf("expected no fingerprints to be recorded, got %v", state)
+	}
+}
@@ -0,0 +1,151 @@
+package dashboardexecute
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	typehelpers "github.com/turbot/go-kit/types"
+)
+
+// DetectionResultRow is a single row of detection results, flattened for tabular output (e.g. csv)
+// a detection which failed is represented by a single row with no values
+type DetectionResultRow struct {
+	Run *DetectionRun
+	// the benchmarks containing the detection, from the outermost benchmark inwards
+	Benchmarks []*DetectionBenchmarkDisplay
+	Values     map[string]any
+}
+
+// DetectionName returns the name of the detection which produced the row
+func (r *DetectionResultRow) DetectionName() string {
+	return r.Run.Resource.Name()
+}
+
+// DetectionTitle returns the title of the detection which produced the row
+func (r *DetectionResultRow) DetectionTitle() string {
+	return r.Run.Resource.GetTitle()
+}
+
+// Severity returns the severity of the detection which produced the row
+func (r *DetectionResultRow) Severity() string {
+	return r.Run.GetSeverity()
+}
+
+// BenchmarkPath returns the names of the benchmarks containing the detection, separated by ' > '
+func (r *DetectionResultRow) BenchmarkPath() string {
+	names := make([]string, len(r.Benchmarks))
+	for i, b := range r.Benchmarks {
+		names[i] = b.GroupId
+	}
+	return strings.Join(names, " > ")
+}
+
+// ErrorMessage returns the error message of the detection run, if any
+func (r *DetectionResultRow) ErrorMessage() string {
+	if err := r.Run.GetError(); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// GetValue returns the value of the given column as a string
+func (r *DetectionResultRow) GetValue(column string) string {
+	return r.Run.GetDisplayValue(r.Values, column)
+}
+
+// DisplayColumns returns the columns to display for the detection results
+// this is the display_columns of the detection if set, otherwise all columns returned by the query
+func (r *DetectionRun) DisplayColumns() []string {
+	if len(r.Resource.DisplayColumns) > 0 {
+		return r.Resource.DisplayColumns
+	}
+	if r.Data == nil {
+		return nil
+	}
+	columns := make([]string, len(r.Data.Columns))
+	for i, c := range r.Data.Columns {
+		columns[i] = c.Name
+	}
+	return columns
+}
+
+// GetSeverity returns the severity of the detection, or an empty string if it is not set
+func (r *DetectionRun) GetSeverity() string {
+	return typehelpers.SafeString(r.Resource.Severity)
+}
+
+// GetDisplayValue returns the value of the given column of a result row as a string
+// time values are formatted as RFC3339 and missing values are returned as an empty string
+func (r *DetectionRun) GetDisplayValue(row map[string]any, column string) string {
+	val, ok := row[column]
+	if !ok || val == nil {
+		return ""
+	}
+	switch v := val.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// ResultRows returns the rows of all detection runs in the tree, in tree order
+func (t *DetectionBenchmarkDisplayTree) ResultRows() []*DetectionResultRow {
+	var res []*DetectionResultRow
+	var addRows func(group *DetectionBenchmarkDisplay, benchmarks []*DetectionBenchmarkDisplay)
+	addRows = func(group *DetectionBenchmarkDisplay, benchmarks []*DetectionBenchmarkDisplay) {
+		// the synthetic root group is not a benchmark so is excluded from the path
+		if group.GroupId != RootResultGroup_Name {
+			// clone the path so sibling groups do not share a backing array
+			benchmarks = append(slices.Clone(benchmarks), group)
+		}
+		for _, run := range group.DetectionRuns {
+			if run.Data == nil || len(run.Data.Rows) == 0 {
+				// only include a row for a detection with no results if it failed
+				if run.GetError() != nil {
+					res = append(res, &DetectionResultRow{Run: run, Benchmarks: benchmarks})
+				}
+				continue
+			}
+			for _, row := range run.Data.Rows {
+				res = append(res, &DetectionResultRow{Run: run, Benchmarks: benchmarks, Values: row})
+			}
+		}
+		for _, child := range group.Groups {
+			addRows(child, benchmarks)
+		}
+	}
+	if t.Root != nil {
+		addRows(t.Root, nil)
+	}
+	return res
+}
+
+// DisplayColumns returns the distinct display columns of all detection runs in the tree,
+// in the order they are first found
+func (t *DetectionBenchmarkDisplayTree) DisplayColumns() []string {
+	var res []string
+	seen := make(map[string]struct{})
+	var addColumns func(group *DetectionBenchmarkDisplay)
+	addColumns = func(group *DetectionBenchmarkDisplay) {
+		for _, run := range group.DetectionRuns {
+			for _, c := range run.DisplayColumns() {
+				if _, ok := seen[c]; !ok {
+					seen[c] = struct{}{}
+					res = append(res, c)
+				}
+			}
+		}
+		for _, child := range group.Groups {
+			addColumns(child)
+		}
+	}
+	if t.Root != nil {
+		addColumns(t.Root)
+	}
+	return res
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/turbot/pipe-fittings/v2/connection"
 	"github.com/turbot/pipe-fittings/v2/statushooks"
 	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+	"github.com/turbot/pipe-fittings/v2/utils"
 	"github.com/turbot/powerpipe/internal/controlexecute"
 	"github.com/turbot/powerpipe/internal/dashboardtypes"
 	"github.com/turbot/powerpipe/internal/db_client"
@@ -28,6 +29,11 @@ type DetectionRun struct {
 	Properties    map[string]any           `json:"properties,omitempty"`
 	Data          *dashboardtypes.LeafData `json:"data,omitempty"`
 	Documentation string                   `json:"documentation,omitempty"`
+	// for an incremental run, the time range evaluated by the detection
+	TimeRange *utils.TimeRange `json:"time_range,omitempty"`
+	// the fingerprint of each result row, in the same order as the rows
+	// (only populated if the detection declares fingerprint columns, or only first seen results are reported)
+	Fingerprints []string `json:"fingerprints,omitempty"`
 
 	// function called when the run is complete
 	// this property populated for 'with' runs
@@ -124,6 +130,13 @@ func (r *DetectionRun) Execute(ctx context.Context) {
 
 	slog.Debug("DetectionRun Execute()", "name", r.resource.Name())
 
+	// for a dry run, the detection is reported without being run
+	if r.executionTree.dryRun {
+		r.Data = &dashboardtypes.LeafData{}
+		r.SetComplete(ctx)
+		return
+	}
+
 	// to get here, we must be a query provider
 
 	// if we have children and with runs, start them asynchronously (they may block waiting for our runtime dependencies)
@@ -148,6 +161,10 @@ func (r *DetectionRun) Execute(ctx context.Context) {
 			r.SetError(ctx, err)
 			return
 		}
+		if err := r.setFingerprints(); err != nil {
+			r.SetError(ctx, err)
+			return
+		}
 	}
 
 	// wait for all children and withs
@@ -195,9 +212,46 @@ func (r *DetectionRun) executeQuery(ctx context.Context) error {
 		return err
 	}
 
+	// if this is an incremental run, only evaluate the time since the high-water mark of the detection
+	timeRange := r.executionTree.DateTimeRange
+	state := r.executionTree.detectionState
+	var connectionKey string
+	// whether the time range starts at the high-water mark of the detection
+	var fromHighWaterMark bool
+	if state != nil {
+		var err error
+		connectionKey, err = detectionStateConnectionKey(r.database)
+		if err != nil {
+			return err
+		}
+		mark, ok := state.GetHighWaterMark(r.resource.Name(), connectionKey)
+		timeRange, err = incrementalTimeRange(timeRange, mark, ok, r.executionTree.startTime, r.executionTree.incrementalLag)
+		if err != nil {
+			return err
+		}
+		r.TimeRange = &timeRange
+		fromHighWaterMark = ok && timeRange.From != nil && timeRange.From.Equal(mark)
+	}
+
+	// if the results of this query have been cached by another execution, use them
+	// (the results of an incremental run depend on the high-water mark, so are never cached)
+	query := &cachedQuery{}
+	if state == nil {
+		var err error
+		query, err = r.executionTree.newCachedQuery(ctx, r.resource, r.database, r.searchPathConfig, r.executeSQL, r.Args)
+		if err != nil {
+			return err
+		}
+	}
+	if data, ok := query.get(); ok {
+		slog.Debug("DetectionRun using cached query result", "name", r.resource.Name())
+		r.Data = data
+		return nil
+	}
+
 	// get the client for this leaf run
 	// (we have already resolved the database and search path config)
-	client, err := r.executionTree.getClient(ctx, r.database, r.searchPathConfig)
+	client, err := r.executionTree.getClientForTimeRange(ctx, r.database, r.searchPathConfig, timeRange)
 	if err != nil {
 		return err
 	}
@@ -218,6 +272,16 @@ func (r *DetectionRun) executeQuery(ctx context.Context) error {
 		return err
 
 	}
+	query.set(r.Data)
+
+	if state != nil {
+		// the start of the time range is exclusive - results at the high-water mark were reported by the previous run
+		if fromHighWaterMark {
+			r.Data = rowsAfterHighWaterMark(r.Data, *timeRange.From)
+		}
+		// the detection has now been evaluated up to the end of the time range
+		state.SetHighWaterMark(r.resource.Name(), connectionKey, *timeRange.To)
+	}
 	return nil
 }
 
@@ -0,0 +1,108 @@
+package dashboardexecute
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/connection"
+	"github.com/turbot/pipe-fittings/v2/utils"
+	"github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+)
+
+// the columns which may hold the event time of a detection result row, in order of preference
+var incrementalTimestampColumns = []string{"tp_timestamp", "timestamp"}
+
+// incrementalTimeRange returns the time range evaluated by an incremental detection run
+// this starts at the high-water mark of the detection (if later than the requested start)
+// and ends at the requested end, or if no end was requested, the execution start time less the lag
+// (the lag allows for events which are written late, so would otherwise be before the next high-water mark)
+// the end is truncated to the second, as time range filters have a precision of one second
+// if the requested end is not after the high-water mark there is nothing to evaluate, so an error is returned
+// if the end based on the lag is before the high-water mark (e.g. the lag was increased) the range is empty
+func incrementalTimeRange(timeRange utils.TimeRange, highWaterMark time.Time, hasHighWaterMark bool, now time.Time, lag time.Duration) (utils.TimeRange, error) {
+	if now.IsZero() {
+		now = time.Now()
+	}
+	res := timeRange
+	if res.To == nil {
+		to := now.Add(-lag).Truncate(time.Second)
+		res.To = &to
+	}
+	if !hasHighWaterMark || (res.From != nil && !highWaterMark.After(*res.From)) {
+		return res, nil
+	}
+	res.From = &highWaterMark
+	if res.To.After(highWaterMark) {
+		return res, nil
+	}
+	if timeRange.To != nil {
+		return res, fmt.Errorf("the requested end time %s is not after the high-water mark of the detection %s - use --%s to evaluate earlier events",
+			res.To.Format(time.RFC3339), highWaterMark.Format(time.RFC3339), constants.ArgResetState)
+	}
+	res.To = &highWaterMark
+	return res, nil
+}
+
+// rowsAfterHighWaterMark returns the result rows whose event time is after the high-water mark
+// the time range filter includes its start, so events at the high-water mark were already evaluated by the previous run
+// rows with no event time column are all returned
+func rowsAfterHighWaterMark(data *dashboardtypes.LeafData, highWaterMark time.Time) *dashboardtypes.LeafData {
+	column := incrementalTimestampColumn(data)
+	if column == "" {
+		return data
+	}
+	// build new data rather than updating the rows in place, as the query result may be shared
+	res := &dashboardtypes.LeafData{Columns: data.Columns, Truncated: data.Truncated}
+	for _, row := range data.Rows {
+		if t, ok := rowTimestamp(row[column]); ok && !t.After(highWaterMark) {
+			continue
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	return res
+}
+
+// incrementalTimestampColumn returns the name of the event time column of the result, if any
+func incrementalTimestampColumn(data *dashboardtypes.LeafData) string {
+	for _, name := range incrementalTimestampColumns {
+		for _, c := range data.Columns {
+			if c.Name == name {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+func rowTimestamp(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		return t, err == nil
+	default:
+		return time.Time{}, false
+	}
+}
+
+// detectionStateConnectionKey returns the key used to record the high-water mark of a detection for a connection
+// this is the connection name (or redacted url) if available, otherwise a hash of the connection string,
+// so credentials are never written to the state file
+func detectionStateConnectionKey(database connection.ConnectionStringProvider) (string, error) {
+	if name := databaseName(database); name != "" {
+		return name, nil
+	}
+	if database == nil {
+		return "", nil
+	}
+	connectionString, err := database.GetConnectionString()
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256([]byte(connectionString))
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -2,9 +2,8 @@ package dashboardexecute
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
 	"strings"
 	"sync"
 	"time"
@@ -17,12 +16,21 @@ import (
 	"github.com/turbot/powerpipe/internal/dashboardevents"
 	"github.com/turbot/powerpipe/internal/dashboardtypes"
 	"github.com/turbot/powerpipe/internal/db_client"
+	"github.com/turbot/powerpipe/internal/snapshot"
 	"github.com/turbot/powerpipe/internal/workspace"
 )
 
+// executionKey identifies an execution
+// a session may have several concurrent executions, each identified by a client supplied execution id
+// clients which do not supply an execution id have a single execution per session, with an empty execution id
+type executionKey struct {
+	sessionId   string
+	executionId string
+}
+
 type DashboardExecutor struct {
-	// map of executions, keyed by session id
-	executions    map[string]*DashboardExecutionTree
+	// map of executions, keyed by session and execution id
+	executions    map[executionKey]*DashboardExecutionTree
 	executionLock sync.Mutex
 	// is this an interactive execution
 	// i.e. inputs may be specified _after_ execution starts
@@ -33,22 +41,39 @@ type DashboardExecutor struct {
 	defaultClient           *db_client.ClientMap
 	defaultDatabase         connection.ConnectionStringProvider
 	defaultSearchPathConfig backend.SearchPathConfig
+	// cache of query results, shared by all executions
+	queryCache *QueryCache
 }
 
-func NewDashboardExecutor(defaultClient *db_client.ClientMap, defaultDatabase connection.ConnectionStringProvider, defaultSearchPathConfig backend.SearchPathConfig) *DashboardExecutor {
+func NewDashboardExecutor(defaultClient *db_client.ClientMap, defaultDatabase connection.ConnectionStringProvider, defaultSearchPathConfig backend.SearchPathConfig, queryCacheTtl time.Duration) *DashboardExecutor {
 	return &DashboardExecutor{
-		executions: make(map[string]*DashboardExecutionTree),
+		executions: make(map[executionKey]*DashboardExecutionTree),
 		// default to interactive execution
 		interactive:             true,
 		defaultClient:           defaultClient,
 		defaultDatabase:         defaultDatabase,
 		defaultSearchPathConfig: defaultSearchPathConfig,
+		queryCache:              NewQueryCache(queryCacheTtl),
 	}
 }
 
 var Executor *DashboardExecutor
 
-func (e *DashboardExecutor) ExecuteDashboard(ctx context.Context, sessionId string, rootResource modconfig.ModTreeItem, inputs *InputValues, workspace *workspace.PowerpipeWorkspace, opts ...backend.BackendOption) (err error) {
+// ExecuteDashboard executes the root resource, replacing any existing execution with the same session and execution id
+// if an execution id is given it is used as the id of the execution in all events, so clients may run several
+// executions at once - otherwise a unique id is generated
+func (e *DashboardExecutor) ExecuteDashboard(ctx context.Context, sessionId, executionId string, rootResource modconfig.ModTreeItem, inputs *InputValues, workspace *workspace.PowerpipeWorkspace, opts ...backend.BackendOption) error {
+	return e.executeDashboard(ctx, sessionId, executionId, rootResource, inputs, workspace, e.interactive, opts...)
+}
+
+// ExecuteDashboardBatch executes a dashboard non-interactively, regardless of the executor mode
+// i.e. all inputs must be provided up front
+// this is used for background executions in an interactive server (e.g. scheduled runs)
+func (e *DashboardExecutor) ExecuteDashboardBatch(ctx context.Context, sessionId string, rootResource modconfig.ModTreeItem, inputs *InputValues, workspace *workspace.PowerpipeWorkspace, opts ...backend.BackendOption) error {
+	return e.executeDashboard(ctx, sessionId, "", rootResource, inputs, workspace, false, opts...)
+}
+
+func (e *DashboardExecutor) executeDashboard(ctx context.Context, sessionId, executionId string, rootResource modconfig.ModTreeItem, inputs *InputValues, workspace *workspace.PowerpipeWorkspace, interactive bool, opts ...backend.BackendOption) (err error) {
 	var executionTree *DashboardExecutionTree
 	defer func() {
 		if err == nil && ctx.Err() != nil {
@@ -61,29 +86,59 @@ func (e *DashboardExecutor) ExecuteDashboard(ctx context.Context, sessionId stri
 		// if there was an error executing, send an ExecutionError event
 		if err != nil {
 			errorEvent := &dashboardevents.ExecutionError{
-				Error:     err,
-				Session:   sessionId,
-				Timestamp: time.Now(),
+				Error:       err,
+				Session:     sessionId,
+				ExecutionId: executionId,
+				Timestamp:   time.Now(),
+			}
+			if executionTree != nil {
+				errorEvent.ExecutionId = executionTree.subscriberExecutionId(executionId)
 			}
 			workspace.PublishDashboardEvent(ctx, errorEvent)
 		}
 	}()
 
-	// reset any existing executions for this session
-	e.CancelExecutionForSession(ctx, sessionId)
+	// reset any existing execution with this id for this session
+	e.CancelExecution(ctx, sessionId, executionId)
+
+	// interactive executions may be shared between sessions requesting the same root resource, inputs and search path
+	var shareKey string
+	if interactive {
+		shareKey, err = executionShareKey(rootResource, inputs, opts...)
+		if err != nil {
+			return err
+		}
+		// if an identical execution is in progress, subscribe to it rather than executing again
+		// (a refresh always starts a new execution, so the query cache is bypassed)
+		if !isQueryCacheRefresh(ctx) {
+			var subscriber executionSubscriber
+			var started bool
+			executionTree, subscriber, started = e.subscribeToSharedExecution(shareKey, sessionId, executionId)
+			if executionTree != nil {
+				slog.Debug("subscribed to shared execution", "session", sessionId, "execution", executionTree.id)
+				// if the execution has already started, send the current state to the new subscriber
+				if started {
+					return executionTree.publishCurrentState(ctx, subscriber)
+				}
+				return nil
+			}
+		}
+	}
 
 	// now create a new execution
-	executionTree, err = e.newDashboardExecutionTree(rootResource, sessionId, workspace, inputs, opts...)
+	executionTree, err = e.newDashboardExecutionTree(rootResource, sessionId, executionId, workspace, inputs, opts...)
 	if err != nil {
 		return err
 	}
+	executionTree.shareKey = shareKey
+	executionTree.pageTables = interactive
 
 	// add to execution map
-	e.setExecution(sessionId, executionTree)
+	e.setExecution(executionKey{sessionId, executionId}, executionTree)
 
 	// if inputs must be provided before execution (i.e. this is a batch dashboard execution),
 	// verify all required inputs are provided
-	if err = e.validateInputs(executionTree, inputs.Inputs); err != nil {
+	if err = e.validateInputs(executionTree, inputs.Inputs, interactive); err != nil {
 		return err
 	}
 
@@ -94,8 +149,8 @@ func (e *DashboardExecutor) ExecuteDashboard(ctx context.Context, sessionId stri
 
 // if inputs must be provided before execution (i.e. this is a batch dashboard execution),
 // verify all required inputs are provided
-func (e *DashboardExecutor) validateInputs(executionTree *DashboardExecutionTree, inputs map[string]interface{}) error {
-	if e.interactive {
+func (e *DashboardExecutor) validateInputs(executionTree *DashboardExecutionTree, inputs map[string]interface{}, interactive bool) error {
+	if interactive {
 		// interactive dashboard execution - no need to validate
 		return nil
 	}
@@ -123,28 +178,16 @@ func (e *DashboardExecutor) LoadSnapshot(ctx context.Context, sessionId, snapsho
 		return nil, fmt.Errorf("snapshot %s not does not exist", snapshotPath)
 	}
 
-	snapshotContent, err := os.ReadFile(snapshotPath)
-	if err != nil {
-		return nil, err
-	}
-
-	// deserialize the snapshot as an interface map
-	// we cannot deserialize into a SteampipeSnapshot struct
-	// (without custom derserialisation code) as the Panels property is an interface
-	snap := map[string]any{}
-
-	err = json.Unmarshal(snapshotContent, &snap)
-	if err != nil {
-		return nil, err
-	}
-
-	return snap, nil
+	return snapshot.ReadFile(snapshotPath)
 }
 
-func (e *DashboardExecutor) OnInputChanged(ctx context.Context, sessionId string, inputs *InputValues, changedInput string) error {
+func (e *DashboardExecutor) OnInputChanged(ctx context.Context, sessionId, executionId string, inputs *InputValues, changedInput string) error {
 	// find the execution
-	executionTree, found := e.executions[sessionId]
+	executionTree, found := e.getExecution(executionKey{sessionId, executionId})
 	if !found {
+		if executionId != "" {
+			return fmt.Errorf("no dashboard running for session %s with execution id %s", sessionId, executionId)
+		}
 		return fmt.Errorf("no dashboard running for session %s", sessionId)
 	}
 
@@ -156,8 +199,8 @@ func (e *DashboardExecutor) OnInputChanged(ctx context.Context, sessionId string
 	if len(dependentInputs) > 0 {
 		event := &dashboardevents.InputValuesCleared{
 			ClearedInputs: dependentInputs,
-			Session:       executionTree.sessionId,
-			ExecutionId:   executionTree.id,
+			Session:       sessionId,
+			ExecutionId:   executionTree.subscriberExecutionId(executionId),
 		}
 		executionTree.workspace.PublishDashboardEvent(ctx, event)
 	}
@@ -176,10 +219,12 @@ func (e *DashboardExecutor) OnInputChanged(ctx context.Context, sessionId string
 	// (i.e. this is really a CHANGE of input not just the first time the inputs have been set)
 	// NOTE: if the previous input value is nil and we are currently executing we do not need to re-execute
 	// as the current execution will be waiting for the inputs to be available
-	if currentRunFinished || timeRangeChanged || prevInputsExist {
+	// - the execution is shared with other sessions - the inputs of a shared execution cannot be changed in place
+	if currentRunFinished || timeRangeChanged || prevInputsExist || !e.unshareExecution(executionTree) {
 		return e.ExecuteDashboard(
 			ctx,
 			sessionId,
+			executionId,
 			executionTree.Root.GetResource(),
 			inputs,
 			executionTree.workspace)
@@ -208,39 +253,123 @@ func (e *DashboardExecutor) clearDependentInputs(root dashboardtypes.DashboardTr
 	return clearedInputs
 }
 
-func (e *DashboardExecutor) CancelExecutionForSession(_ context.Context, sessionId string) {
+// CancelExecution cancels the execution with the given id for the session, if there is one
+// if the execution is shared, the session is unsubscribed and the execution is only cancelled
+// when its last subscriber leaves
+func (e *DashboardExecutor) CancelExecution(_ context.Context, sessionId, executionId string) {
+	key := executionKey{sessionId, executionId}
 	// find the execution
-	executionTree, found := e.getExecution(sessionId)
+	executionTree, found := e.getExecution(key)
 	if !found {
 		// nothing to do
 		return
 	}
 
+	// remove from execution map
+	e.removeExecution(key)
+	if executionTree.unsubscribe(sessionId, executionId) > 0 {
+		return
+	}
 	// cancel if in progress
 	executionTree.Cancel()
-	// remove from execution tree
-	e.removeExecution(sessionId)
 }
 
-// find the execution for the given session id
-func (e *DashboardExecutor) getExecution(sessionId string) (*DashboardExecutionTree, bool) {
+// CancelExecutionForSession cancels all executions for the session
+func (e *DashboardExecutor) CancelExecutionForSession(ctx context.Context, sessionId string) {
+	for _, key := range e.getSessionExecutionKeys(sessionId) {
+		e.CancelExecution(ctx, sessionId, key.executionId)
+	}
+}
+
+// GetTablePage returns a page of the rows of a paged table panel of an execution
+func (e *DashboardExecutor) GetTablePage(sessionId, executionId, panelName string, req dashboardtypes.LeafDataPageRequest) (*dashboardtypes.LeafData, error) {
+	executionTree, found := e.getExecution(executionKey{sessionId, executionId})
+	if !found {
+		// clients which do not supply an execution id use the id from the execution events
+		if t, ok := e.getExecution(executionKey{sessionId, ""}); ok && t.id == executionId {
+			executionTree, found = t, true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no dashboard running for session %s with execution id %s", sessionId, executionId)
+	}
+
+	leafRun, ok := executionTree.runs[panelName].(*LeafRun)
+	if !ok {
+		return nil, fmt.Errorf("panel '%s' not found", panelName)
+	}
+	return leafRun.GetPage(req)
+}
+
+// subscribeToSharedExecution subscribes the session to an in-progress execution with the given share key, if there is one
+// it returns the execution, the subscriber and whether the execution has already started
+func (e *DashboardExecutor) subscribeToSharedExecution(shareKey, sessionId, executionId string) (*DashboardExecutionTree, executionSubscriber, bool) {
 	e.executionLock.Lock()
 	defer e.executionLock.Unlock()
 
-	executionTree, found := e.executions[sessionId]
+	for _, executionTree := range e.executions {
+		if executionTree.shareKey != shareKey || executionTree.GetRunStatus().IsFinished() {
+			continue
+		}
+		subscriber := executionSubscriber{
+			sessionId:   sessionId,
+			executionId: executionTree.subscriberExecutionId(executionId),
+		}
+		subscribed, started := executionTree.subscribe(subscriber)
+		if !subscribed {
+			continue
+		}
+		e.executions[executionKey{sessionId, executionId}] = executionTree
+		return executionTree, subscriber, started
+	}
+	return nil, executionSubscriber{}, false
+}
+
+// unshareExecution prevents other sessions subscribing to the execution, so its inputs may be changed in place
+// it returns false if the execution is already shared with other sessions
+func (e *DashboardExecutor) unshareExecution(executionTree *DashboardExecutionTree) bool {
+	e.executionLock.Lock()
+	defer e.executionLock.Unlock()
+
+	if executionTree.subscriberCount() > 1 {
+		return false
+	}
+	executionTree.shareKey = ""
+	return true
+}
+
+// find the execution for the given key
+func (e *DashboardExecutor) getExecution(key executionKey) (*DashboardExecutionTree, bool) {
+	e.executionLock.Lock()
+	defer e.executionLock.Unlock()
+
+	executionTree, found := e.executions[key]
 	return executionTree, found
 }
 
-func (e *DashboardExecutor) setExecution(sessionId string, executionTree *DashboardExecutionTree) {
+func (e *DashboardExecutor) getSessionExecutionKeys(sessionId string) []executionKey {
+	e.executionLock.Lock()
+	defer e.executionLock.Unlock()
+
+	var keys []executionKey
+	for key := range e.executions {
+		if key.sessionId == sessionId {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (e *DashboardExecutor) setExecution(key executionKey, executionTree *DashboardExecutionTree) {
 	e.executionLock.Lock()
 	defer e.executionLock.Unlock()
 
-	e.executions[sessionId] = executionTree
+	e.executions[key] = executionTree
 }
 
-func (e *DashboardExecutor) removeExecution(sessionId string) {
+func (e *DashboardExecutor) removeExecution(key executionKey) {
 	e.executionLock.Lock()
 	defer e.executionLock.Unlock()
 
-	delete(e.executions, sessionId)
+	delete(e.executions, key)
 }
@@ -0,0 +1,145 @@
+package dashboardexecute
+
+import (
+	"context"
+	"testing"
+
+	"github.com/turbot/pipe-fittings/v2/backend"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+)
+
+// testRootRun is a root run whose status is set directly by the test
+type testRootRun struct {
+	dashboardtypes.DashboardTreeRun
+	status dashboardtypes.RunStatus
+}
+
+func (r *testRootRun) GetRunStatus() dashboardtypes.RunStatus {
+	return r.status
+}
+
+func (r *testRootRun) RunComplete() bool {
+	return r.status.IsFinished()
+}
+
+// newTestExecution creates a running execution, subscribed to by the given sessions, which is marked as canceled when cancelled
+func newTestExecution(id string, subscribers ...executionSubscriber) *DashboardExecutionTree {
+	root := &testRootRun{status: dashboardtypes.RunRunning}
+	e := &DashboardExecutionTree{
+		id:          id,
+		Root:        root,
+		subscribers: subscribers,
+	}
+	e.cancel = func() { root.status = dashboardtypes.RunCanceled }
+	return e
+}
+
+func newTestExecutor(executions map[executionKey]*DashboardExecutionTree) *DashboardExecutor {
+	e := NewDashboardExecutor(nil, nil, backend.SearchPathConfig{}, 0)
+	for key, executionTree := range executions {
+		e.setExecution(key, executionTree)
+	}
+	return e
+}
+
+func TestExecutorConcurrentExecutionsInSession(t *testing.T) {
+	a := newTestExecution("a", executionSubscriber{sessionId: "s1", executionId: "a"})
+	b := newTestExecution("b", executionSubscriber{sessionId: "s1", executionId: "b"})
+	e := newTestExecutor(map[executionKey]*DashboardExecutionTree{
+		{"s1", "a"}: a,
+		{"s1", "b"}: b,
+	})
+
+	if got, found := e.getExecution(executionKey{"s1", "a"}); !found || got != a {
+		t.Errorf("expected execution a to be found for session s1")
+	}
+	if got, found := e.getExecution(executionKey{"s1", "b"}); !found || got != b {
+		t.Errorf("expected execution b to be found for session s1")
+	}
+	if _, found := e.getExecution(executionKey{"s1", ""}); found {
+		t.Errorf("expected no execution without an execution id for session s1")
+	}
+	if keys := e.getSessionExecutionKeys("s1"); len(keys) != 2 {
+		t.Errorf("expected 2 executions for session s1, got %d", len(keys))
+	}
+}
+
+func TestExecutorCancelExecution(t *testing.T) {
+	a := newTestExecution("a", executionSubscriber{sessionId: "s1", executionId: "a"})
+	b := newTestExecution("b", executionSubscriber{sessionId: "s1", executionId: "b"})
+	e := newTestExecutor(map[executionKey]*DashboardExecutionTree{
+		{"s1", "a"}: a,
+		{"s1", "b"}: b,
+	})
+
+	e.CancelExecution(context.Background(), "s1", "a")
+
+	if a.GetRunStatus() != dashboardtypes.RunCanceled {
+		t.Errorf("expected execution a to be canceled, got %s", a.GetRunStatus())
+	}
+	if _, found := e.getExecution(executionKey{"s1", "a"}); found {
+		t.Errorf("expected execution a to be removed")
+	}
+	if b.GetRunStatus() != dashboardtypes.RunRunning {
+		t.Errorf("expected execution b to keep running, got %s", b.GetRunStatus())
+	}
+	if _, found := e.getExecution(executionKey{"s1", "b"}); !found {
+		t.Errorf("expected execution b to remain")
+	}
+
+	// cancelling an unknown execution is a no-op
+	e.CancelExecution(context.Background(), "s1", "unknown")
+	if b.GetRunStatus() != dashboardtypes.RunRunning {
+		t.Errorf("expected execution b to keep running, got %s", b.GetRunStatus())
+	}
+}
+
+func TestExecutorCancelSharedExecution(t *testing.T) {
+	shared := newTestExecution("shared",
+		executionSubscriber{sessionId: "s1", executionId: "shared"},
+		executionSubscriber{sessionId: "s2", executionId: "x"},
+	)
+	e := newTestExecutor(map[executionKey]*DashboardExecutionTree{
+		{"s1", ""}:  shared,
+		{"s2", "x"}: shared,
+	})
+
+	// the execution is only cancelled when its last subscriber leaves
+	e.CancelExecution(context.Background(), "s1", "")
+	if shared.GetRunStatus() != dashboardtypes.RunRunning {
+		t.Errorf("expected shared execution to keep running while subscribed, got %s", shared.GetRunStatus())
+	}
+	e.CancelExecution(context.Background(), "s2", "x")
+	if shared.GetRunStatus() != dashboardtypes.RunCanceled {
+		t.Errorf("expected shared execution to be canceled, got %s", shared.GetRunStatus())
+	}
+}
+
+func TestExecutorCancelExecutionForSession(t *testing.T) {
+	a := newTestExecution("a", executionSubscriber{sessionId: "s1", executionId: "a"})
+	b := newTestExecution("b", executionSubscriber{sessionId: "s1", executionId: "b"})
+	c := newTestExecution("c", executionSubscriber{sessionId: "s2", executionId: "a"})
+	e := newTestExecutor(map[executionKey]*DashboardExecutionTree{
+		{"s1", "a"}: a,
+		{"s1", "b"}: b,
+		{"s2", "a"}: c,
+	})
+
+	e.CancelExecutionForSession(context.Background(), "s1")
+
+	for name, executionTree := range map[string]*DashboardExecutionTree{"a": a, "b": b} {
+		if executionTree.GetRunStatus() != dashboardtypes.RunCanceled {
+			t.Errorf("expected execution %s to be canceled, got %s", name, executionTree.GetRunStatus())
+		}
+	}
+	if keys := e.getSessionExecutionKeys("s1"); len(keys) != 0 {
+		t.Errorf("expected no executions for session s1, got %d", len(keys))
+	}
+	// executions of other sessions are unaffected, even with the same execution id
+	if c.GetRunStatus() != dashboardtypes.RunRunning {
+		t.Errorf("expected execution of session s2 to keep running, got %s", c.GetRunStatus())
+	}
+	if _, found := e.getExecution(executionKey{"s2", "a"}); !found {
+		t.Errorf("expected execution of session s2 to remain")
+	}
+}
@@ -1,13 +1,39 @@
 package dashboardexecute
 
 import (
+	"time"
+
 	"github.com/turbot/pipe-fittings/v2/utils"
 )
 
+// DetectionStateProvider provides the high-water marks of incremental detection runs,
+// i.e. the time up to which each detection has been evaluated, per connection
+type DetectionStateProvider interface {
+	GetHighWaterMark(detection, connection string) (time.Time, bool)
+	SetHighWaterMark(detection, connection string, t time.Time)
+}
+
+// DetectionFingerprintProvider records the fingerprints of the detection results which have been seen
+type DetectionFingerprintProvider interface {
+	// RecordFingerprint records that a result row of the detection with the given fingerprint was seen,
+	// returning whether this is the first time the fingerprint has been seen
+	RecordFingerprint(detection, fingerprint string, seen time.Time) bool
+}
+
 type InputValues struct {
 	Inputs map[string]interface{} `json:"inputs"`
 	// map of time ranges, keyed by target benchmark/detection
 	DateTimeRange utils.TimeRange `json:"detection_time_ranges"`
+	// if set, detections are run incrementally - each detection only evaluates the time since its high-water mark
+	DetectionState DetectionStateProvider `json:"-"`
+	// for an incremental run, the time allowed for late events - the evaluated time range ends this long before the run started
+	IncrementalLag time.Duration `json:"-"`
+	// if set, detections only report result rows whose fingerprint has not been seen before
+	FingerprintState DetectionFingerprintProvider `json:"-"`
+	// if set, only the detections of a detection benchmark which match the filter are run
+	DetectionFilter *DetectionFilter `json:"-"`
+	// if set, detections are reported without being run
+	DryRun bool `json:"-"`
 }
 
 func NewInputValues() *InputValues {
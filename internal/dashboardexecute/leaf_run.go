@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"golang.org/x/exp/maps"
 	"log/slog"
+	"net/url"
+	"sync"
 	"time"
 
+	"github.com/spf13/viper"
 	"github.com/turbot/pipe-fittings/v2/backend"
 	"github.com/turbot/pipe-fittings/v2/connection"
 	"github.com/turbot/pipe-fittings/v2/error_helpers"
@@ -14,6 +17,7 @@ import (
 	"github.com/turbot/pipe-fittings/v2/schema"
 	"github.com/turbot/pipe-fittings/v2/statushooks"
 	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
 	"github.com/turbot/powerpipe/internal/dashboardtypes"
 	"github.com/turbot/powerpipe/internal/db_client"
 	"github.com/turbot/powerpipe/internal/resources"
@@ -30,11 +34,16 @@ type LeafRun struct {
 	Properties    map[string]any           `json:"properties,omitempty"`
 	Data          *dashboardtypes.LeafData `json:"data,omitempty"`
 	Documentation string                   `json:"documentation,omitempty"`
+	// the timing of the panel execution - this is populated when the run executes
+	Timing *dashboardtypes.PanelTiming `json:"timing,omitempty"`
 	// function called when the run is complete
 	// this property populated for 'with' runs
 	onComplete       func()
 	database         connection.ConnectionStringProvider
 	searchPathConfig backend.SearchPathConfig
+	// for a paged table, the full query result - Data contains the first page only
+	pagedData *dashboardtypes.LeafData
+	pageLock  sync.RWMutex
 }
 
 func (r *LeafRun) AsTreeNode() *steampipeconfig.SnapshotTreeNode {
@@ -151,6 +160,11 @@ func (r *LeafRun) Execute(ctx context.Context) {
 
 	slog.Debug("LeafRun Execute()", "name", r.resource.Name())
 
+	r.Timing = &dashboardtypes.PanelTiming{
+		QueuedAt: time.Now(),
+		Database: databaseName(r.database),
+	}
+
 	// to get here, we must be a query provider
 
 	// if we have children and with runs, start them asynchronously (they may block waiting for our runtime dependencies)
@@ -196,6 +210,9 @@ func (r *LeafRun) Execute(ctx context.Context) {
 func (r *LeafRun) SetError(ctx context.Context, err error) {
 	// increment error count for snapshot hook
 	statushooks.SnapshotError(ctx)
+	if r.Timing != nil {
+		r.Timing.SetFinished()
+	}
 	r.DashboardTreeRunImpl.SetError(ctx, err)
 }
 
@@ -203,6 +220,9 @@ func (r *LeafRun) SetError(ctx context.Context, err error) {
 func (r *LeafRun) SetComplete(ctx context.Context) {
 	// call snapshot hooks with progress
 	statushooks.UpdateSnapshotProgress(ctx, 1)
+	if r.Timing != nil {
+		r.Timing.SetFinished()
+	}
 
 	r.DashboardTreeRunImpl.SetComplete(ctx)
 }
@@ -222,6 +242,18 @@ func (r *LeafRun) executeQuery(ctx context.Context) error {
 		return err
 	}
 
+	// if the results of this query have been cached by another execution, use them
+	query, err := r.executionTree.newCachedQuery(ctx, r.resource, r.database, r.searchPathConfig, r.executeSQL, r.Args)
+	if err != nil {
+		return err
+	}
+	r.Timing.SetStarted()
+	if data, ok := query.get(); ok {
+		slog.Debug("LeafRun using cached query result", "name", r.resource.Name())
+		r.Timing.SetQueryComplete(data.QueryRowCount(), true)
+		return r.setData(data)
+	}
+
 	// get the client for this leaf run
 	// (we have already resolved the database and search path config)
 	client, err := r.executionTree.getClient(ctx, r.database, r.searchPathConfig)
@@ -240,14 +272,68 @@ func (r *LeafRun) executeQuery(ctx context.Context) error {
 	}
 	slog.Debug("LeafRun complete", "name", r.resource.Name())
 
-	r.Data, err = dashboardtypes.NewLeafData(queryResult)
+	data, err := dashboardtypes.NewLeafData(queryResult)
 	if err != nil {
 		return err
 
 	}
+	// record the row count of the query before the rows are truncated
+	r.Timing.SetQueryComplete(len(data.Rows), false)
+	// apply the max rows safeguard before caching, so cached results are also limited
+	if maxRows := viper.GetInt(localconstants.ArgDashboardMaxRows); maxRows > 0 && len(data.Rows) > maxRows {
+		slog.Warn("LeafRun query result truncated", "name", r.resource.Name(), "rows", len(data.Rows), "max rows", maxRows)
+		data.Truncate(maxRows)
+	}
+	query.set(data)
+	return r.setData(data)
+}
+
+// setData sets the data of the run
+// for a paged table, the full result is retained and only the first page is sent to the client
+func (r *LeafRun) setData(data *dashboardtypes.LeafData) error {
+	pageSize := r.pageSize()
+	if pageSize == 0 {
+		r.Data = data
+		return nil
+	}
+
+	page, err := data.Page(dashboardtypes.LeafDataPageRequest{Limit: pageSize})
+	if err != nil {
+		return err
+	}
+	r.pageLock.Lock()
+	r.pagedData = data
+	r.pageLock.Unlock()
+	r.Data = page
 	return nil
 }
 
+// pageSize returns the page size of a paged table, or zero if the run is not paged
+func (r *LeafRun) pageSize() int {
+	if !r.executionTree.pageTables {
+		return 0
+	}
+	if t, ok := r.resource.(*resources.DashboardTable); ok && t.PageSize != nil {
+		return *t.PageSize
+	}
+	return 0
+}
+
+// GetPage returns a page of the full result of a paged table
+// the page size of the table is used if no limit is requested, and is the maximum limit
+// (so a client cannot request the full result of a large table in a single page)
+func (r *LeafRun) GetPage(req dashboardtypes.LeafDataPageRequest) (*dashboardtypes.LeafData, error) {
+	r.pageLock.RLock()
+	data := r.pagedData
+	r.pageLock.RUnlock()
+	if data == nil {
+		return nil, fmt.Errorf("panel '%s' is not a paged table, or has not completed", r.Name)
+	}
+
+	req.ClampLimit(r.pageSize())
+	return data.Page(req)
+}
+
 func (r *LeafRun) combineChildData() {
 	// we either have children OR a query
 	// if there are no children, do nothing
@@ -275,6 +361,27 @@ func (r *LeafRun) combineChildData() {
 	r.Data.Columns = maps.Values(schemaMap)
 }
 
+// databaseName returns the name of the database used by a run, for inclusion in the panel timing
+// for a connection string, any password is redacted
+func databaseName(database connection.ConnectionStringProvider) string {
+	if database == nil {
+		return ""
+	}
+	if c, ok := database.(connection.PipelingConnection); ok {
+		return c.Name()
+	}
+	connectionString, err := database.GetConnectionString()
+	if err != nil {
+		return ""
+	}
+	// only connection strings which are urls are included - other formats may contain credentials
+	u, err := url.Parse(connectionString)
+	if err != nil || u.Scheme == "" {
+		return ""
+	}
+	return u.Redacted()
+}
+
 func (r *LeafRun) populateProperties() error {
 	if r.resource == nil {
 		return nil
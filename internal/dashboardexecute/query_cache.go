@@ -0,0 +1,148 @@
+package dashboardexecute
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/backend"
+	"github.com/turbot/pipe-fittings/v2/connection"
+	"github.com/turbot/pipe-fittings/v2/modconfig"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+	"github.com/turbot/powerpipe/internal/resources"
+)
+
+type queryCacheRefreshKey struct{}
+
+// WithQueryCacheRefresh returns a context which causes dashboard executions to bypass the query cache
+// (the results of the execution are still added to the cache)
+func WithQueryCacheRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, queryCacheRefreshKey{}, true)
+}
+
+func isQueryCacheRefresh(ctx context.Context) bool {
+	refresh, _ := ctx.Value(queryCacheRefreshKey{}).(bool)
+	return refresh
+}
+
+// QueryCache is a TTL bounded cache of query results, shared by all dashboard executions
+// so that sessions viewing the same dashboard do not re-execute the same queries
+type QueryCache struct {
+	// the ttl used for resources which do not override it - if zero, results are only cached
+	// for resources (or dashboards) which set a cache_ttl
+	defaultTtl time.Duration
+	entries    map[string]*queryCacheEntry
+	mut        sync.Mutex
+}
+
+type queryCacheEntry struct {
+	data    *dashboardtypes.LeafData
+	expires time.Time
+}
+
+func NewQueryCache(defaultTtl time.Duration) *QueryCache {
+	return &QueryCache{
+		defaultTtl: defaultTtl,
+		entries:    make(map[string]*queryCacheEntry),
+	}
+}
+
+// Get returns the cached data for the key, if it exists and has not expired
+func (c *QueryCache) Get(key string) (*dashboardtypes.LeafData, bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !time.Now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.data, true
+}
+
+// Set adds data to the cache with the given ttl, removing any expired entries
+func (c *QueryCache) Set(key string, data *dashboardtypes.LeafData, ttl time.Duration) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = &queryCacheEntry{data: data, expires: now.Add(ttl)}
+}
+
+// cachedQuery is the cache key and ttl for the query of a LeafRun or DetectionRun
+// if the ttl is zero, the results of the query are not cached
+type cachedQuery struct {
+	cache   *QueryCache
+	key     string
+	ttl     time.Duration
+	refresh bool
+}
+
+// newCachedQuery builds the cache key for a query from the resolved sql, args, connection string and search path
+func (e *DashboardExecutionTree) newCachedQuery(ctx context.Context, resource modconfig.ModTreeItem, csp connection.ConnectionStringProvider, searchPathConfig backend.SearchPathConfig, sql string, args []any) (*cachedQuery, error) {
+	res := &cachedQuery{
+		cache:   e.queryCache,
+		ttl:     e.queryCacheTtl(resource),
+		refresh: isQueryCacheRefresh(ctx),
+	}
+	if res.cache == nil || res.ttl <= 0 {
+		return res, nil
+	}
+
+	connectionString, err := e.getConnectionString(csp)
+	if err != nil {
+		return nil, err
+	}
+	keyData, err := json.Marshal(struct {
+		SQL              string   `json:"sql"`
+		Args             []any    `json:"args"`
+		ConnectionString string   `json:"connection_string"`
+		SearchPath       []string `json:"search_path"`
+		SearchPathPrefix []string `json:"search_path_prefix"`
+	}{sql, args, connectionString, searchPathConfig.SearchPath, searchPathConfig.SearchPathPrefix})
+	if err != nil {
+		// args which cannot be serialised cannot be cached
+		slog.Debug("query cache disabled for resource - failed to build cache key", "name", resource.Name(), "error", err.Error())
+		res.ttl = 0
+		return res, nil
+	}
+	// hash the key so the connection string is not held in memory
+	hash := sha256.Sum256(keyData)
+	res.key = hex.EncodeToString(hash[:])
+	return res, nil
+}
+
+// the cache ttl for a resource - the resource cache_ttl overrides the dashboard cache_ttl,
+// which overrides the server default
+func (e *DashboardExecutionTree) queryCacheTtl(resource modconfig.ModTreeItem) time.Duration {
+	if p, ok := resource.(resources.CacheTtlProvider); ok && p.GetCacheTtl() != nil {
+		return time.Duration(*p.GetCacheTtl()) * time.Second
+	}
+	return e.defaultCacheTtl
+}
+
+func (q *cachedQuery) get() (*dashboardtypes.LeafData, bool) {
+	if q.ttl <= 0 || q.refresh {
+		return nil, false
+	}
+	return q.cache.Get(q.key)
+}
+
+func (q *cachedQuery) set(data *dashboardtypes.LeafData) {
+	if q.ttl <= 0 {
+		return
+	}
+	q.cache.Set(q.key, data, q.ttl)
+}
@@ -0,0 +1,80 @@
+package dashboardexecute
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/backend"
+	"github.com/turbot/pipe-fittings/v2/connection"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+	"github.com/turbot/powerpipe/internal/resources"
+)
+
+func TestQueryCacheExpiry(t *testing.T) {
+	cache := NewQueryCache(0)
+	data := &dashboardtypes.LeafData{}
+
+	cache.Set("k1", data, time.Hour)
+	cache.Set("k2", data, -time.Second)
+
+	if got, ok := cache.Get("k1"); !ok || got != data {
+		t.Errorf("expected k1 to be cached")
+	}
+	if _, ok := cache.Get("k2"); ok {
+		t.Errorf("expected k2 to have expired")
+	}
+	if _, ok := cache.Get("k3"); ok {
+		t.Errorf("expected k3 not to be cached")
+	}
+}
+
+func TestCachedQuery(t *testing.T) {
+	tree := &DashboardExecutionTree{
+		queryCache:      NewQueryCache(time.Minute),
+		defaultCacheTtl: time.Minute,
+	}
+	ctx := context.Background()
+	db1 := connection.NewConnectionString("postgres://localhost:9193/db1")
+	db2 := connection.NewConnectionString("postgres://localhost:9193/db2")
+	spc := backend.SearchPathConfig{SearchPath: []string{"aws"}}
+	card := &resources.DashboardCard{}
+	card.FullName = "mod.card.c1"
+
+	newQuery := func(ctx context.Context, csp connection.ConnectionStringProvider, spc backend.SearchPathConfig, sql string, args ...any) *cachedQuery {
+		q, err := tree.newCachedQuery(ctx, card, csp, spc, sql, args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return q
+	}
+
+	data := &dashboardtypes.LeafData{}
+	newQuery(ctx, db1, spc, "select $1", "a").set(data)
+
+	if got, ok := newQuery(ctx, db1, spc, "select $1", "a").get(); !ok || got != data {
+		t.Errorf("expected an identical query to use the cached result")
+	}
+	for name, q := range map[string]*cachedQuery{
+		"args":        newQuery(ctx, db1, spc, "select $1", "b"),
+		"sql":         newQuery(ctx, db1, spc, "select $1 ", "a"),
+		"database":    newQuery(ctx, db2, spc, "select $1", "a"),
+		"search path": newQuery(ctx, db1, backend.SearchPathConfig{SearchPath: []string{"gcp"}}, "select $1", "a"),
+	} {
+		if _, ok := q.get(); ok {
+			t.Errorf("expected a query with different %s not to use the cached result", name)
+		}
+	}
+
+	// a refresh bypasses the cache
+	if _, ok := newQuery(WithQueryCacheRefresh(ctx), db1, spc, "select $1", "a").get(); ok {
+		t.Errorf("expected a refresh to bypass the cache")
+	}
+
+	// a resource cache_ttl of zero disables caching
+	ttl := 0
+	card.CacheTtl = &ttl
+	if _, ok := newQuery(ctx, db1, spc, "select $1", "a").get(); ok {
+		t.Errorf("expected a resource with a zero cache_ttl not to use the cache")
+	}
+}
@@ -24,7 +24,7 @@ func GenerateSnapshot(ctx context.Context, w *workspace.PowerpipeWorkspace, root
 	// all runtime dependencies must be resolved before execution (i.e. inputs must be passed in)
 	Executor.interactive = false
 
-	if err := Executor.ExecuteDashboard(ctx, sessionId, rootResource, inputs, w); err != nil {
+	if err := Executor.ExecuteDashboard(ctx, sessionId, "", rootResource, inputs, w); err != nil {
 		return nil, err
 	}
 
@@ -17,12 +17,25 @@ import (
 	"github.com/turbot/powerpipe/internal/dashboardassets"
 	"github.com/turbot/powerpipe/internal/dashboardevents"
 	"github.com/turbot/powerpipe/internal/dashboardexecute"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
 	"github.com/turbot/powerpipe/internal/db_client"
+	"github.com/turbot/powerpipe/internal/history"
 	"github.com/turbot/powerpipe/internal/resources"
+	"github.com/turbot/powerpipe/internal/serverauth"
 	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
 )
 
-func (s *Server) buildServerMetadataPayload(rm modconfig.ModResources, pipesMetadata *steampipeconfig.PipesMetadata) ([]byte, error) {
+func (s *Server) buildServerMetadataPayload(rm modconfig.ModResources, pipesMetadata *steampipeconfig.PipesMetadata, principal *serverauth.Principal) ([]byte, error) {
+	payload, err := s.buildServerMetadata(rm, pipesMetadata)
+	if err != nil {
+		return nil, err
+	}
+	payload.Metadata.User = principal
+	return json.Marshal(payload)
+}
+
+// buildServerMetadata builds the server metadata payload, without the user
+func (s *Server) buildServerMetadata(rm modconfig.ModResources, pipesMetadata *steampipeconfig.PipesMetadata) (*ServerMetadataPayload, error) {
 	workspaceResources := rm.(*resources.PowerpipeModResources)
 	installedMods := make(map[string]*ModMetadata)
 	for _, mod := range workspaceResources.Mods {
@@ -89,7 +102,7 @@ func (s *Server) buildServerMetadataPayload(rm modconfig.ModResources, pipesMeta
 		payload.Metadata.Cloud = pipesMetadata
 	}
 
-	return json.Marshal(payload)
+	return &payload, nil
 }
 
 func (s *Server) buildDashboardMetadataPayload(dashboard modconfig.ModTreeItem) ([]byte, error) {
@@ -196,12 +209,14 @@ func addDetectionBenchmarkChildren(benchmark *resources.DetectionBenchmark, reco
 	return children
 }
 
-func buildAvailableDashboardsPayload(workspaceResources *resources.PowerpipeModResources) ([]byte, error) {
+// buildAvailableDashboardsPayload builds the payload listing the dashboards and benchmarks which the principal may access
+func (s *Server) buildAvailableDashboardsPayload(ctx context.Context, workspaceResources *resources.PowerpipeModResources, principal *serverauth.Principal) ([]byte, error) {
 	payload := AvailableDashboardsPayload{
 		Action:     "available_dashboards",
 		Dashboards: make(map[string]ModAvailableDashboard),
 		Benchmarks: make(map[string]ModAvailableBenchmark),
-		Snapshots:  workspaceResources.Snapshots,
+		Snapshots:  filterSnapshots(workspaceResources.Snapshots, principal, s.getSnapshotFileRootResource),
+		History:    filterRuns(s.getRecentRuns(ctx), principal, s.getResource),
 	}
 
 	// if workspace resources has a mod, populate dashboards and benchmarks
@@ -212,6 +227,9 @@ func buildAvailableDashboardsPayload(workspaceResources *resources.PowerpipeModR
 		topLevelResources := resources.GetModResources(workspaceResources.Mod)
 
 		for _, dashboard := range topLevelResources.Dashboards {
+			if !principal.CanAccess(dashboard) {
+				continue
+			}
 			mod := dashboard.Mod
 			// add this dashboard
 			payload.Dashboards[dashboard.FullName] = ModAvailableDashboard{
@@ -225,7 +243,7 @@ func buildAvailableDashboardsPayload(workspaceResources *resources.PowerpipeModR
 
 		benchmarkTrunks := make(map[string][][]string)
 		for _, benchmark := range topLevelResources.ControlBenchmarks {
-			if benchmark.IsAnonymous() {
+			if benchmark.IsAnonymous() || !principal.CanAccess(benchmark) {
 				continue
 			}
 
@@ -267,7 +285,7 @@ func buildAvailableDashboardsPayload(workspaceResources *resources.PowerpipeModR
 
 		detectionBenchmarkTrunks := make(map[string][][]string)
 		for _, detectionBenchmark := range topLevelResources.DetectionBenchmarks {
-			if detectionBenchmark.IsAnonymous() {
+			if detectionBenchmark.IsAnonymous() || !principal.CanAccess(detectionBenchmark) {
 				continue
 			}
 
@@ -370,9 +388,10 @@ func buildExecutionStartedPayload(event *dashboardevents.ExecutionStarted) ([]by
 
 func buildExecutionErrorPayload(event *dashboardevents.ExecutionError) ([]byte, error) {
 	payload := ExecutionErrorPayload{
-		Action:    "execution_error",
-		Error:     event.Error.Error(),
-		Timestamp: event.Timestamp,
+		Action:      "execution_error",
+		Error:       event.Error.Error(),
+		ExecutionId: event.ExecutionId,
+		Timestamp:   event.Timestamp,
 	}
 	return json.Marshal(payload)
 }
@@ -388,11 +407,25 @@ func buildExecutionCompletePayload(event *dashboardevents.ExecutionComplete) ([]
 	return json.Marshal(payload)
 }
 
-func buildDisplaySnapshotPayload(snap map[string]any) ([]byte, error) {
+func buildDisplaySnapshotPayload(snap map[string]any, executionId string) ([]byte, error) {
 	payload := &DisplaySnapshotPayload{
 		Action:        "execution_complete",
 		SchemaVersion: fmt.Sprintf("%d", ExecutionCompletePayloadSchemaVersion),
 		Snapshot:      snap,
+		ExecutionId:   executionId,
+	}
+	return json.Marshal(payload)
+}
+
+func buildTablePagePayload(executionId, panel string, data *dashboardtypes.LeafData, err error) ([]byte, error) {
+	payload := TablePagePayload{
+		Action:      "table_page",
+		ExecutionId: executionId,
+		Panel:       panel,
+		Data:        data,
+	}
+	if err != nil {
+		payload.Error = err.Error()
 	}
 	return json.Marshal(payload)
 }
@@ -405,3 +438,62 @@ func buildInputValuesClearedPayload(event *dashboardevents.InputValuesCleared) (
 	}
 	return json.Marshal(payload)
 }
+
+// filterSnapshots returns the snapshots (keyed by name) whose root resource the principal may access
+// the snapshot files are only read if the principal has tag rules
+func filterSnapshots(snapshots map[string]string, principal *serverauth.Principal, rootResource func(path string) modconfig.ModTreeItem) map[string]string {
+	if !principal.HasTagRules() {
+		return snapshots
+	}
+	res := make(map[string]string)
+	for name, path := range snapshots {
+		if principal.CanAccess(rootResource(path)) {
+			res[name] = path
+		}
+	}
+	return res
+}
+
+// filterRuns returns the runs whose target the principal may access
+func filterRuns(runs []*history.Run, principal *serverauth.Principal, getResource func(name string) modconfig.ModTreeItem) []*history.Run {
+	if !principal.HasTagRules() {
+		return runs
+	}
+	var res []*history.Run
+	for _, run := range runs {
+		if principal.CanAccess(getResource(run.Target)) {
+			res = append(res, run)
+		}
+	}
+	return res
+}
+
+// the number of recent runs included in the available dashboards payload
+const availableDashboardsHistoryLimit = 50
+
+// getRecentRuns returns the most recent runs from the run history (if any runs have been recorded)
+// the history store is opened the first time it exists, and reused for subsequent payloads
+func (s *Server) getRecentRuns(ctx context.Context) []*history.Run {
+	s.historyLock.Lock()
+	defer s.historyLock.Unlock()
+
+	if s.historyStore == nil {
+		// do not create the history store if it does not exist
+		if !history.StoreExists() {
+			return nil
+		}
+		store, err := history.OpenStore(ctx)
+		if err != nil {
+			slog.Warn("failed to open run history", "error", err)
+			return nil
+		}
+		s.historyStore = store
+	}
+
+	runs, err := s.historyStore.List(ctx, "", availableDashboardsHistoryLimit)
+	if err != nil {
+		slog.Warn("failed to list run history", "error", err)
+		return nil
+	}
+	return runs
+}
@@ -0,0 +1,75 @@
+package dashboardserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/turbot/pipe-fittings/v2/modconfig"
+	"github.com/turbot/powerpipe/internal/history"
+	"github.com/turbot/powerpipe/internal/resources"
+	"github.com/turbot/powerpipe/internal/serverauth"
+)
+
+func newTestPrincipal(t *testing.T, tags ...string) *serverauth.Principal {
+	authenticator := serverauth.NewAuthenticator(&serverauth.Config{
+		Tokens: []*serverauth.Token{{User: "auditor", Value: "secret", Tags: tags}},
+	})
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("Authorization", "Bearer secret")
+	principal, err := authenticator.Authenticate(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return principal
+}
+
+func newTestResources() map[string]modconfig.ModTreeItem {
+	res := make(map[string]modconfig.ModTreeItem)
+	for name, category := range map[string]string{"mod.benchmark.compliance": "compliance", "mod.benchmark.cost": "cost"} {
+		benchmark := &resources.Benchmark{}
+		benchmark.FullName = name
+		benchmark.Tags = map[string]string{"category": category}
+		res[name] = benchmark
+	}
+	return res
+}
+
+func TestFilterSnapshots(t *testing.T) {
+	workspaceResources := newTestResources()
+	snapshots := map[string]string{
+		"snapshot.compliance": "compliance.pps",
+		"snapshot.cost":       "cost.pps",
+		"snapshot.unknown":    "unknown.pps",
+	}
+	roots := map[string]string{"compliance.pps": "mod.benchmark.compliance", "cost.pps": "mod.benchmark.cost"}
+	rootResource := func(path string) modconfig.ModTreeItem {
+		return workspaceResources[roots[path]]
+	}
+
+	if res := filterSnapshots(snapshots, nil, rootResource); len(res) != 3 {
+		t.Errorf("expected all snapshots without authentication, got %v", res)
+	}
+	res := filterSnapshots(snapshots, newTestPrincipal(t, "category=compliance"), rootResource)
+	if len(res) != 1 || res["snapshot.compliance"] != "compliance.pps" {
+		t.Errorf("expected only the snapshot of the benchmark matching the tag rules, got %v", res)
+	}
+}
+
+func TestFilterRuns(t *testing.T) {
+	workspaceResources := newTestResources()
+	getResource := func(name string) modconfig.ModTreeItem { return workspaceResources[name] }
+	runs := []*history.Run{
+		{Id: "run_1", Target: "mod.benchmark.compliance"},
+		{Id: "run_2", Target: "mod.benchmark.cost"},
+		{Id: "run_3", Target: "check.mod"},
+	}
+
+	if res := filterRuns(runs, newTestPrincipal(t), getResource); len(res) != 3 {
+		t.Errorf("expected all runs for a principal without tag rules, got %d", len(res))
+	}
+	res := filterRuns(runs, newTestPrincipal(t, "category=compliance"), getResource)
+	if len(res) != 1 || res[0].Id != "run_1" {
+		t.Errorf("expected only the run of the benchmark matching the tag rules, got %v", res)
+	}
+}
@@ -5,13 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
 	"reflect"
 	"slices"
 	"strings"
 	"sync"
+	"time"
 
-	typeHelpers "github.com/turbot/go-kit/types"
 	"github.com/turbot/pipe-fittings/v2/backend"
 	"github.com/turbot/pipe-fittings/v2/connection"
 	"github.com/turbot/pipe-fittings/v2/error_helpers"
@@ -20,7 +21,10 @@ import (
 	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
 	"github.com/turbot/powerpipe/internal/dashboardevents"
 	"github.com/turbot/powerpipe/internal/dashboardexecute"
+	"github.com/turbot/powerpipe/internal/history"
 	"github.com/turbot/powerpipe/internal/initialisation"
+	"github.com/turbot/powerpipe/internal/serverauth"
+	"github.com/turbot/powerpipe/internal/snapshot"
 	"github.com/turbot/powerpipe/internal/workspace"
 	"github.com/turbot/steampipe-plugin-sdk/v5/sperr"
 	"gopkg.in/olahol/melody.v1"
@@ -33,6 +37,9 @@ type Server struct {
 	workspace               *workspace.PowerpipeWorkspace
 	defaultDatabase         connection.ConnectionStringProvider
 	defaultSearchPathConfig backend.SearchPathConfig
+	// the run history store, opened when the first payload including recent runs is built
+	historyStore *history.Store
+	historyLock  sync.Mutex
 }
 
 func NewServer(ctx context.Context, initData *initialisation.InitData, webSocket *melody.Melody) (*Server, error) {
@@ -80,6 +87,15 @@ func (s *Server) Shutdown(ctx context.Context) {
 		slog.Debug("closed websocket")
 	}
 
+	s.historyLock.Lock()
+	if s.historyStore != nil {
+		if err := s.historyStore.Close(); err != nil {
+			slog.Warn("failed to close run history", "error", err)
+		}
+		s.historyStore = nil
+	}
+	s.historyLock.Unlock()
+
 	slog.Debug("Server shutdown complete")
 }
 
@@ -206,8 +222,10 @@ func (s *Server) HandleDashboardEvent(ctx context.Context, event dashboardevents
 			return
 		}
 
-		for k, v := range s.dashboardClients {
-			slog.Debug("WorkspaceEvents", "client", k, "event", typeHelpers.SafeString(v.Dashboard))
+		for k := range s.dashboardClients {
+			for executionId, execution := range s.getSessionExecutions(k) {
+				slog.Debug("WorkspaceEvents", "client", k, "execution", executionId, "event", execution.Dashboard)
+			}
 		}
 
 		// If) any deleted/new/changed dashboards, emit an available dashboards message to clients
@@ -215,31 +233,23 @@ func (s *Server) HandleDashboardEvent(ctx context.Context, event dashboardevents
 			OutputMessage(ctx, "Available Dashboards updated")
 
 			// Emit dashboard metadata event in case there is a new mod - else the UI won't know about this mod
-			payload, payloadError = s.buildServerMetadataPayload(s.workspace.GetModResources(), &steampipeconfig.PipesMetadata{})
+			// followed by the available dashboards event
+			// the payloads are written to each session, as they depend on the user of the session
+			payloadError = s.writeAvailableDashboardsToSessions(ctx)
 			if payloadError != nil {
 				return
 			}
-			_ = s.webSocket.Broadcast(payload)
-
-			// Emit available dashboards event
-			workspaceResources := s.workspace.GetPowerpipeModResources()
-			payload, payloadError = buildAvailableDashboardsPayload(workspaceResources)
-			if payloadError != nil {
-				return
-			}
-			_ = s.webSocket.Broadcast(payload)
 		}
 
 		var dashboardsBeingWatched []string
 
 		dashboardClients := s.getDashboardClients()
-		for _, dashboardClientInfo := range dashboardClients {
-			dashboardName := typeHelpers.SafeString(dashboardClientInfo.Dashboard)
-			if dashboardClientInfo.Dashboard != nil {
-				if slices.Contains(dashboardsBeingWatched, dashboardName) {
+		for sessionId := range dashboardClients {
+			for _, execution := range s.getSessionExecutions(sessionId) {
+				if slices.Contains(dashboardsBeingWatched, execution.Dashboard) {
 					continue
 				}
-				dashboardsBeingWatched = append(dashboardsBeingWatched, dashboardName)
+				dashboardsBeingWatched = append(dashboardsBeingWatched, execution.Dashboard)
 			}
 		}
 
@@ -275,10 +285,13 @@ func (s *Server) HandleDashboardEvent(ctx context.Context, event dashboardevents
 		for _, changedDashboardName := range changedDashboardNames {
 			sessionMap := s.getDashboardClients()
 			for sessionId, dashboardClientInfo := range sessionMap {
-				if typeHelpers.SafeString(dashboardClientInfo.Dashboard) == changedDashboardName {
-
-					if changedResource := s.getResource(changedDashboardName); changedResource != nil {
-						err := dashboardexecute.Executor.ExecuteDashboard(ctx, sessionId, changedResource, dashboardClientInfo.DashboardInputs, s.workspace)
+				for executionId, execution := range s.getSessionExecutions(sessionId) {
+					if execution.Dashboard != changedDashboardName {
+						continue
+					}
+					// the tags of the dashboard may have changed, so check the user may still access it
+					if changedResource := s.getResource(changedDashboardName); changedResource != nil && dashboardClientInfo.Principal.CanAccess(changedResource) {
+						err := dashboardexecute.Executor.ExecuteDashboard(ctx, sessionId, executionId, changedResource, execution.DashboardInputs, s.workspace)
 						if err != nil {
 							OutputError(ctx, sperr.WrapWithMessage(err, "error executing dashboard"))
 						}
@@ -301,9 +314,12 @@ func (s *Server) HandleDashboardEvent(ctx context.Context, event dashboardevents
 		sessionMap := s.getDashboardClients()
 		for _, newDashboardName := range newDashboardNames {
 			for sessionId, dashboardClientInfo := range sessionMap {
-				if typeHelpers.SafeString(dashboardClientInfo.Dashboard) == newDashboardName {
-					if newDashboard := s.getResource(newDashboardName); newDashboard != nil {
-						err := dashboardexecute.Executor.ExecuteDashboard(ctx, sessionId, newDashboard, dashboardClientInfo.DashboardInputs, s.workspace)
+				for executionId, execution := range s.getSessionExecutions(sessionId) {
+					if execution.Dashboard != newDashboardName {
+						continue
+					}
+					if newDashboard := s.getResource(newDashboardName); newDashboard != nil && dashboardClientInfo.Principal.CanAccess(newDashboard) {
+						err := dashboardexecute.Executor.ExecuteDashboard(ctx, sessionId, executionId, newDashboard, execution.DashboardInputs, s.workspace)
 						if err != nil {
 							OutputError(ctx, sperr.WrapWithMessage(err, "error executing dashboard"))
 						}
@@ -318,10 +334,15 @@ func (s *Server) HandleDashboardEvent(ctx context.Context, event dashboardevents
 			return
 		}
 
-		dashboardClients := s.getDashboardClients()
-		if sessionInfo, ok := dashboardClients[e.Session]; ok {
+		executions := s.getSessionExecutions(e.Session)
+		// the event execution id is the client execution id, unless the client did not supply one
+		execution, ok := executions[e.ExecutionId]
+		if !ok {
+			execution, ok = executions[""]
+		}
+		if ok && execution.DashboardInputs != nil {
 			for _, clearedInput := range e.ClearedInputs {
-				delete(sessionInfo.DashboardInputs.Inputs, clearedInput)
+				delete(execution.DashboardInputs.Inputs, clearedInput)
 			}
 		}
 		s.writePayloadToSession(e.Session, payload)
@@ -365,25 +386,30 @@ func (s *Server) handleMessageFunc(ctx context.Context) func(session *melody.Ses
 
 		switch request.Action {
 		case "get_server_metadata":
-			payload, err := s.buildServerMetadataPayload(s.workspace.GetModResources(), &steampipeconfig.PipesMetadata{})
+			payload, err := s.buildServerMetadataPayload(s.workspace.GetModResources(), &steampipeconfig.PipesMetadata{}, sessionPrincipal(session))
 			if err != nil {
 				OutputError(ctx, sperr.WrapWithMessage(err, "error building payload for get_metadata"))
 			}
 			_ = session.Write(payload)
 		case "get_available_dashboards":
-			payload, err := buildAvailableDashboardsPayload(s.workspace.GetPowerpipeModResources())
+			payload, err := s.buildAvailableDashboardsPayload(ctx, s.workspace.GetPowerpipeModResources(), sessionPrincipal(session))
 			if err != nil {
 				OutputError(ctx, sperr.WrapWithMessage(err, "error building payload for get_available_dashboards"))
 			}
 			_ = session.Write(payload)
-		case "select_dashboard":
+		case "select_dashboard", "refresh_dashboard":
 			dashboard := s.getResource(request.Payload.Dashboard.FullName)
 			if dashboard == nil {
 				return
 			}
+			if principal := sessionPrincipal(session); !principal.CanAccess(dashboard) {
+				slog.Warn("user is not permitted to select dashboard", "user", principal.Name, "dashboard", request.Payload.Dashboard.FullName)
+				writeAccessDeniedError(ctx, session, sessionId, request.Payload.ExecutionId, principal, request.Payload.Dashboard.FullName)
+				return
+			}
 
 			inputValues := request.Payload.InputValues()
-			s.setDashboardForSession(sessionId, request.Payload.Dashboard.FullName, inputValues)
+			s.setDashboardForSession(sessionId, request.Payload.ExecutionId, request.Payload.Dashboard.FullName, inputValues)
 
 			// was a search path passed into the execute command?
 			var opts []backend.BackendOption
@@ -393,7 +419,12 @@ func (s *Server) handleMessageFunc(ctx context.Context) func(session *melody.Ses
 					SearchPathPrefix: request.Payload.SearchPathPrefix,
 				}))
 			}
-			err := dashboardexecute.Executor.ExecuteDashboard(ctx, sessionId, dashboard, inputValues, s.workspace, opts...)
+			// a refresh re-executes the dashboard without using cached query results
+			executeCtx := ctx
+			if request.Action == "refresh_dashboard" {
+				executeCtx = dashboardexecute.WithQueryCacheRefresh(ctx)
+			}
+			err := dashboardexecute.Executor.ExecuteDashboard(executeCtx, sessionId, request.Payload.ExecutionId, dashboard, inputValues, s.workspace, opts...)
 			if err != nil {
 				OutputError(ctx, sperr.WrapWithMessage(err, "error executing dashboard"))
 			}
@@ -406,12 +437,18 @@ func (s *Server) handleMessageFunc(ctx context.Context) func(session *melody.Ses
 
 		case "select_snapshot":
 			snapshotName := request.Payload.Dashboard.FullName
-			s.setDashboardForSession(sessionId, snapshotName, request.Payload.InputValues())
 			snap, err := dashboardexecute.Executor.LoadSnapshot(ctx, sessionId, snapshotName, s.workspace)
 			// TACTICAL- handle with error message
 			error_helpers.FailOnError(err)
+			// the tag rules of the user are applied to the root resource of the snapshot
+			if principal := sessionPrincipal(session); !principal.CanAccess(s.getSnapshotRootResource(snap)) {
+				slog.Warn("user is not permitted to select snapshot", "user", principal.Name, "snapshot", snapshotName)
+				writeAccessDeniedError(ctx, session, sessionId, request.Payload.ExecutionId, principal, snapshotName)
+				return
+			}
+			s.setDashboardForSession(sessionId, request.Payload.ExecutionId, snapshotName, request.Payload.InputValues())
 			// error handling???
-			payload, err := buildDisplaySnapshotPayload(snap)
+			payload, err := buildDisplaySnapshotPayload(snap, request.Payload.ExecutionId)
 			// TACTICAL- handle with error message
 			error_helpers.FailOnError(err)
 
@@ -419,10 +456,25 @@ func (s *Server) handleMessageFunc(ctx context.Context) func(session *melody.Ses
 			OutputReady(ctx, fmt.Sprintf("Show snapshot complete: %s", snapshotName))
 		case "input_changed":
 			inputValues := request.Payload.InputValues()
-			s.setDashboardInputsForSession(sessionId, inputValues)
-			_ = dashboardexecute.Executor.OnInputChanged(ctx, sessionId, inputValues, request.Payload.ChangedInput)
+			s.setDashboardInputsForSession(sessionId, request.Payload.ExecutionId, inputValues)
+			_ = dashboardexecute.Executor.OnInputChanged(ctx, sessionId, request.Payload.ExecutionId, inputValues, request.Payload.ChangedInput)
+		case "get_table_page":
+			// page through the retained result of a paged table
+			data, pageErr := dashboardexecute.Executor.GetTablePage(sessionId, request.Payload.ExecutionId, request.Payload.Panel, request.Payload.Page)
+			payload, err := buildTablePagePayload(request.Payload.ExecutionId, request.Payload.Panel, data, pageErr)
+			if err != nil {
+				OutputError(ctx, sperr.WrapWithMessage(err, "error building payload for get_table_page"))
+				return
+			}
+			_ = session.Write(payload)
 		case "clear_dashboard":
-			s.setDashboardInputsForSession(sessionId, nil)
+			// if an execution id is given only that execution is cleared, otherwise all executions for the session are
+			if executionId := request.Payload.ExecutionId; executionId != "" {
+				s.clearDashboardForSession(sessionId, executionId)
+				dashboardexecute.Executor.CancelExecution(ctx, sessionId, executionId)
+				return
+			}
+			s.clearDashboardsForSession(sessionId)
 			dashboardexecute.Executor.CancelExecutionForSession(ctx, sessionId)
 		}
 	}
@@ -444,36 +496,143 @@ func (s *Server) addSession(session *melody.Session) {
 	sessionId := s.getSessionId(session)
 
 	clientSession := &DashboardClientInfo{
-		Session: session,
+		Session:    session,
+		Executions: make(map[string]*DashboardClientExecution),
+		Principal:  sessionPrincipal(session),
 	}
 
 	s.addDashboardClient(sessionId, clientSession)
 }
 
-func (s *Server) setDashboardInputsForSession(sessionId string, inputs *dashboardexecute.InputValues) {
-	dashboardClients := s.getDashboardClients()
-	if sessionInfo, ok := dashboardClients[sessionId]; ok {
-		sessionInfo.DashboardInputs = inputs
+func (s *Server) getSessionId(session *melody.Session) string {
+	return fmt.Sprintf("%p", session)
+}
+
+// sessionPrincipal returns the user authenticated when the session was connected
+// this is nil if authentication is not enabled
+func sessionPrincipal(session *melody.Session) *serverauth.Principal {
+	principal, _ := session.Get(serverauth.PrincipalKey)
+	p, _ := principal.(*serverauth.Principal)
+	return p
+}
+
+// writeAccessDeniedError writes an execution error to the session, reporting that the user may not access the named resource
+func writeAccessDeniedError(ctx context.Context, session *melody.Session, sessionId, executionId string, principal *serverauth.Principal, name string) {
+	payload, err := buildExecutionErrorPayload(&dashboardevents.ExecutionError{
+		Error:       fmt.Errorf("user '%s' is not permitted to access %s", principal.Name, name),
+		Session:     sessionId,
+		ExecutionId: executionId,
+		Timestamp:   time.Now(),
+	})
+	if err != nil {
+		OutputError(ctx, sperr.WrapWithMessage(err, "error building payload for execution error"))
+	}
+	_ = session.Write(payload)
+}
+
+// getSnapshotRootResource returns the workspace resource which is the root of the snapshot layout,
+// or nil if the snapshot root is not a resource in the workspace
+func (s *Server) getSnapshotRootResource(snap map[string]any) modconfig.ModTreeItem {
+	layout, _ := snap["layout"].(map[string]any)
+	name, _ := layout["name"].(string)
+	if name == "" {
+		return nil
 	}
+	return s.getResource(name)
 }
 
-func (s *Server) getSessionId(session *melody.Session) string {
-	return fmt.Sprintf("%p", session)
+// getSnapshotFileRootResource returns the root resource of the snapshot file at the given path,
+// or nil if it cannot be read or the resource is not in the workspace
+func (s *Server) getSnapshotFileRootResource(path string) modconfig.ModTreeItem {
+	snap, err := snapshot.ReadFile(path)
+	if err != nil {
+		slog.Warn("failed to read snapshot", "path", path, "error", err)
+		return nil
+	}
+	return s.getSnapshotRootResource(snap)
+}
+
+// writeAvailableDashboardsToSessions writes the server metadata and available dashboards payloads to each session
+func (s *Server) writeAvailableDashboardsToSessions(ctx context.Context) error {
+	metadata, err := s.buildServerMetadata(s.workspace.GetModResources(), &steampipeconfig.PipesMetadata{})
+	if err != nil {
+		return err
+	}
+	workspaceResources := s.workspace.GetPowerpipeModResources()
+
+	for sessionId, sessionInfo := range s.getDashboardClients() {
+		metadata.Metadata.User = sessionInfo.Principal
+		payload, err := json.Marshal(metadata)
+		if err != nil {
+			return err
+		}
+		s.writePayloadToSession(sessionId, payload)
+
+		payload, err = s.buildAvailableDashboardsPayload(ctx, workspaceResources, sessionInfo.Principal)
+		if err != nil {
+			return err
+		}
+		s.writePayloadToSession(sessionId, payload)
+	}
+	return nil
 }
 
 // functions providing locked access to member properties
 
-func (s *Server) setDashboardForSession(sessionId string, dashboardName string, inputs *dashboardexecute.InputValues) *DashboardClientInfo {
+func (s *Server) setDashboardForSession(sessionId, executionId, dashboardName string, inputs *dashboardexecute.InputValues) *DashboardClientInfo {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	dashboardClientInfo := s.dashboardClients[sessionId]
-	dashboardClientInfo.Dashboard = &dashboardName
-	dashboardClientInfo.DashboardInputs = inputs
+	dashboardClientInfo.Executions[executionId] = &DashboardClientExecution{
+		Dashboard:       dashboardName,
+		DashboardInputs: inputs,
+	}
 
 	return dashboardClientInfo
 }
 
+func (s *Server) setDashboardInputsForSession(sessionId, executionId string, inputs *dashboardexecute.InputValues) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if sessionInfo, ok := s.dashboardClients[sessionId]; ok {
+		if execution, ok := sessionInfo.Executions[executionId]; ok {
+			execution.DashboardInputs = inputs
+		}
+	}
+}
+
+func (s *Server) clearDashboardForSession(sessionId, executionId string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if sessionInfo, ok := s.dashboardClients[sessionId]; ok {
+		delete(sessionInfo.Executions, executionId)
+	}
+}
+
+func (s *Server) clearDashboardsForSession(sessionId string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if sessionInfo, ok := s.dashboardClients[sessionId]; ok {
+		sessionInfo.Executions = make(map[string]*DashboardClientExecution)
+	}
+}
+
+// getSessionExecutions returns a copy of the executions for the session, keyed by execution id
+func (s *Server) getSessionExecutions(sessionId string) map[string]*DashboardClientExecution {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sessionInfo, ok := s.dashboardClients[sessionId]
+	if !ok {
+		return nil
+	}
+	return maps.Clone(sessionInfo.Executions)
+}
+
 func (s *Server) writePayloadToSession(sessionId string, payload []byte) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -515,7 +674,8 @@ func (s *Server) getResource(name string) modconfig.ModTreeItem {
 		slog.Warn("changed resource not found in workspace", "resource", name)
 		return nil
 	}
-	return resource.(modconfig.ModTreeItem)
+	item, _ := resource.(modconfig.ModTreeItem)
+	return item
 }
 
 func getDashboardsInterestedInResourceChanges(dashboardsBeingWatched []string, existingChangedDashboardNames []string, changedItems []*modconfig.ModTreeItemDiffs) []string {
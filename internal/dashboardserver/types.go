@@ -8,6 +8,9 @@ import (
 	"github.com/turbot/pipe-fittings/v2/utils"
 	"github.com/turbot/powerpipe/internal/controlstatus"
 	"github.com/turbot/powerpipe/internal/dashboardexecute"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+	"github.com/turbot/powerpipe/internal/history"
+	"github.com/turbot/powerpipe/internal/serverauth"
 	"gopkg.in/olahol/melody.v1"
 )
 
@@ -76,9 +79,10 @@ type ControlEventPayload struct {
 }
 
 type ExecutionErrorPayload struct {
-	Action    string    `json:"action"`
-	Error     string    `json:"error"`
-	Timestamp time.Time `json:"timestamp"`
+	Action      string    `json:"action"`
+	Error       string    `json:"error"`
+	ExecutionId string    `json:"execution_id,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
 }
 
 var ExecutionCompletePayloadSchemaVersion int64 = 20240607
@@ -99,6 +103,14 @@ type DisplaySnapshotPayload struct {
 	ExecutionId string         `json:"execution_id"`
 }
 
+type TablePagePayload struct {
+	Action      string                   `json:"action"`
+	ExecutionId string                   `json:"execution_id"`
+	Panel       string                   `json:"panel"`
+	Data        *dashboardtypes.LeafData `json:"data,omitempty"`
+	Error       string                   `json:"error,omitempty"`
+}
+
 type InputValuesClearedPayload struct {
 	Action        string   `json:"action"`
 	ClearedInputs []string `json:"cleared_inputs"`
@@ -106,8 +118,16 @@ type InputValuesClearedPayload struct {
 }
 
 type DashboardClientInfo struct {
-	Session         *melody.Session
-	Dashboard       *string
+	Session *melody.Session
+	// the executions of the session, keyed by the client supplied execution id
+	// (clients which do not supply an execution id have a single execution with an empty id)
+	Executions map[string]*DashboardClientExecution
+	// the authenticated user of the session - nil if authentication is not enabled
+	Principal *serverauth.Principal
+}
+
+type DashboardClientExecution struct {
+	Dashboard       string
 	DashboardInputs *dashboardexecute.InputValues
 }
 
@@ -117,6 +137,8 @@ type ClientRequestDashboardPayload struct {
 
 type ClientRequestPayload struct {
 	Dashboard ClientRequestDashboardPayload `json:"dashboard"`
+	// the execution targeted by the request - this allows a client to run several executions at once
+	ExecutionId string `json:"execution_id"`
 
 	Inputs        map[string]interface{} `json:"inputs"`
 	DateTimeRange utils.TimeRange        `json:"datetime_range"`
@@ -124,6 +146,10 @@ type ClientRequestPayload struct {
 	ChangedInput     string   `json:"changed_input"`
 	SearchPath       []string `json:"search_path"`
 	SearchPathPrefix []string `json:"search_path_prefix"`
+
+	// the table panel and page requested by get_table_page
+	Panel string                             `json:"panel"`
+	Page  dashboardtypes.LeafDataPageRequest `json:"page"`
 }
 
 func (p *ClientRequestPayload) InputValues() *dashboardexecute.InputValues {
@@ -168,6 +194,7 @@ type AvailableDashboardsPayload struct {
 	Benchmarks          map[string]ModAvailableBenchmark `json:"benchmarks"`
 	DetectionBenchmarks map[string]ModAvailableBenchmark `json:"detection_benchmarks"`
 	Snapshots           map[string]string                `json:"snapshots"`
+	History             []*history.Run                   `json:"history,omitempty"`
 }
 
 type ModMetadata struct {
@@ -201,6 +228,8 @@ type ServerMetadata struct {
 	SearchPath         *SearchPathMetadata            `json:"search_path"`
 	SupportsSearchPath bool                           `json:"supports_search_path"`
 	SupportsTimeRange  bool                           `json:"supports_time_range"`
+	// the authenticated user of the session, if authentication is enabled
+	User *serverauth.Principal `json:"user,omitempty"`
 }
 
 type ServerMetadataPayload struct {
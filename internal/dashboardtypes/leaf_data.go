@@ -10,6 +10,13 @@ import (
 type LeafData struct {
 	Columns []*queryresult.ColumnDef `json:"columns"`
 	Rows    []map[string]interface{} `json:"rows"`
+	// for a page of a paged table, the offset of the first row and the total number of rows (after filtering)
+	Offset    *int `json:"offset,omitempty"`
+	TotalRows *int `json:"total_rows,omitempty"`
+	// set if the query returned more than the maximum number of rows, so the rows were truncated
+	Truncated bool `json:"truncated,omitempty"`
+	// the number of rows returned by the query, before truncation
+	queryRowCount int
 }
 
 func NewLeafData(result *queryresult.SyncQueryResult) (*LeafData, error) {
@@ -38,6 +45,27 @@ func NewLeafData(result *queryresult.SyncQueryResult) (*LeafData, error) {
 	return leafData, nil
 }
 
+// Truncate limits the rows to maxRows, setting Truncated if any rows are removed
+// if maxRows is zero, the rows are not limited
+func (leafData *LeafData) Truncate(maxRows int) {
+	if maxRows <= 0 || len(leafData.Rows) <= maxRows {
+		return
+	}
+	if !leafData.Truncated {
+		leafData.queryRowCount = len(leafData.Rows)
+	}
+	leafData.Rows = leafData.Rows[:maxRows]
+	leafData.Truncated = true
+}
+
+// QueryRowCount returns the number of rows returned by the query, including any rows removed by Truncate
+func (leafData *LeafData) QueryRowCount() int {
+	if leafData.Truncated {
+		return leafData.queryRowCount
+	}
+	return len(leafData.Rows)
+}
+
 func (leafData *LeafData) ensureUniqueColumnName() error {
 	// create a unique name generator
 	nameGenerator := utils.NewUniqueNameGenerator()
@@ -0,0 +1,175 @@
+package dashboardtypes
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	SortAscending  = "asc"
+	SortDescending = "desc"
+)
+
+// LeafDataPageRequest is a request for a page of the rows of a paged table
+type LeafDataPageRequest struct {
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+	// the column to sort by, and the direction (asc or desc)
+	SortColumn    string `json:"sort_column,omitempty"`
+	SortDirection string `json:"sort_direction,omitempty"`
+	// only include rows where any column contains this text (case-insensitive)
+	Search string `json:"search,omitempty"`
+	// only include rows where the given column contains the text (case-insensitive)
+	Filters map[string]string `json:"filters,omitempty"`
+}
+
+func (r *LeafDataPageRequest) Validate(columns []string) error {
+	if r.Offset < 0 || r.Limit < 0 {
+		return fmt.Errorf("offset and limit must not be negative")
+	}
+	if r.SortDirection != "" && r.SortDirection != SortAscending && r.SortDirection != SortDescending {
+		return fmt.Errorf("invalid sort direction '%s' - must be '%s' or '%s'", r.SortDirection, SortAscending, SortDescending)
+	}
+	if r.SortColumn != "" && !slices.Contains(columns, r.SortColumn) {
+		return fmt.Errorf("invalid sort column '%s'", r.SortColumn)
+	}
+	for column := range r.Filters {
+		if !slices.Contains(columns, column) {
+			return fmt.Errorf("invalid filter column '%s'", column)
+		}
+	}
+	return nil
+}
+
+// ClampLimit sets the limit to the given maximum page size if no limit has been requested, or the limit exceeds it
+func (r *LeafDataPageRequest) ClampLimit(maxLimit int) {
+	if r.Limit == 0 || r.Limit > maxLimit {
+		r.Limit = maxLimit
+	}
+}
+
+// Page returns the requested page of rows, after filtering and sorting
+// the returned data has the offset and total number of rows (after filtering) set
+// the rows are not copied, so neither the returned data nor the source data should be mutated
+func (leafData *LeafData) Page(req LeafDataPageRequest) (*LeafData, error) {
+	columns := make([]string, len(leafData.Columns))
+	for i, c := range leafData.Columns {
+		columns[i] = c.Name
+	}
+	if err := req.Validate(columns); err != nil {
+		return nil, err
+	}
+
+	rows := leafData.Rows
+	filtered := req.Search != "" || len(req.Filters) > 0
+	if filtered {
+		rows = slices.DeleteFunc(slices.Clone(rows), func(row map[string]any) bool {
+			return !rowMatches(row, columns, req.Search, req.Filters)
+		})
+	}
+	if req.SortColumn != "" {
+		// clone before sorting (unless already cloned by filtering) so the source rows are not reordered
+		if !filtered {
+			rows = slices.Clone(rows)
+		}
+		slices.SortStableFunc(rows, func(a, b map[string]any) int {
+			c := compareValues(a[req.SortColumn], b[req.SortColumn])
+			if req.SortDirection == SortDescending {
+				return -c
+			}
+			return c
+		})
+	}
+
+	totalRows := len(rows)
+	offset := min(req.Offset, totalRows)
+	end := totalRows
+	if req.Limit > 0 {
+		end = min(offset+req.Limit, totalRows)
+	}
+	return &LeafData{
+		Columns:   leafData.Columns,
+		Rows:      rows[offset:end],
+		Offset:    &offset,
+		TotalRows: &totalRows,
+		Truncated: leafData.Truncated,
+	}, nil
+}
+
+func rowMatches(row map[string]any, columns []string, search string, filters map[string]string) bool {
+	for column, filter := range filters {
+		if !containsFold(row[column], filter) {
+			return false
+		}
+	}
+	if search == "" {
+		return true
+	}
+	return slices.ContainsFunc(columns, func(column string) bool {
+		return containsFold(row[column], search)
+	})
+}
+
+func containsFold(value any, text string) bool {
+	if value == nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(fmt.Sprint(value)), strings.ToLower(text))
+}
+
+// compareValues compares two column values - nulls sort first, numbers and times are compared by value,
+// and all other values are compared as strings
+func compareValues(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	if fa, ok := toFloat(a); ok {
+		if fb, ok := toFloat(b); ok {
+			return cmp.Compare(fa, fb)
+		}
+	}
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return ta.Compare(tb)
+		}
+	}
+	return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
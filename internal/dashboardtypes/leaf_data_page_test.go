@@ -0,0 +1,110 @@
+package dashboardtypes
+
+import (
+	"testing"
+
+	"github.com/turbot/pipe-fittings/v2/queryresult"
+)
+
+func testLeafData() *LeafData {
+	return &LeafData{
+		Columns: []*queryresult.ColumnDef{{Name: "name"}, {Name: "region"}, {Name: "size"}},
+		Rows: []map[string]any{
+			{"name": "a", "region": "us-east-1", "size": int64(30)},
+			{"name": "b", "region": "eu-west-1", "size": int64(5)},
+			{"name": "c", "region": "us-west-2", "size": nil},
+			{"name": "d", "region": "US-EAST-2", "size": int64(100)},
+		},
+	}
+}
+
+func rowNames(data *LeafData) string {
+	var res string
+	for _, row := range data.Rows {
+		res += row["name"].(string)
+	}
+	return res
+}
+
+func TestLeafDataPage(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       LeafDataPageRequest
+		expected  string
+		totalRows int
+	}{
+		{"first page", LeafDataPageRequest{Limit: 2}, "ab", 4},
+		{"second page", LeafDataPageRequest{Offset: 2, Limit: 2}, "cd", 4},
+		{"offset beyond end", LeafDataPageRequest{Offset: 10, Limit: 2}, "", 4},
+		{"sort numeric", LeafDataPageRequest{SortColumn: "size"}, "cbad", 4},
+		{"sort descending", LeafDataPageRequest{SortColumn: "size", SortDirection: SortDescending}, "dabc", 4},
+		{"search", LeafDataPageRequest{Search: "us-east"}, "ad", 2},
+		{"filter", LeafDataPageRequest{Filters: map[string]string{"region": "WEST"}}, "bc", 2},
+		{"filter, sort and page", LeafDataPageRequest{Filters: map[string]string{"region": "us"}, SortColumn: "name", SortDirection: SortDescending, Limit: 2}, "dc", 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := testLeafData()
+			page, err := data.Page(test.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if names := rowNames(page); names != test.expected {
+				t.Errorf("expected rows %q, got %q", test.expected, names)
+			}
+			if *page.TotalRows != test.totalRows {
+				t.Errorf("expected %d total rows, got %d", test.totalRows, *page.TotalRows)
+			}
+			// the source rows must not be reordered
+			if names := rowNames(data); names != "abcd" {
+				t.Errorf("expected the source rows to be unchanged, got %q", names)
+			}
+		})
+	}
+}
+
+func TestLeafDataPageInvalid(t *testing.T) {
+	for name, req := range map[string]LeafDataPageRequest{
+		"negative offset": {Offset: -1},
+		"sort column":     {SortColumn: "missing"},
+		"sort direction":  {SortColumn: "name", SortDirection: "up"},
+		"filter column":   {Filters: map[string]string{"missing": "a"}},
+	} {
+		if _, err := testLeafData().Page(req); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLeafDataPageRequestClampLimit(t *testing.T) {
+	for name, test := range map[string]struct{ limit, expected int }{
+		"no limit":            {0, 25},
+		"within page size":    {10, 10},
+		"equal to page size":  {25, 25},
+		"exceeding page size": {1000000, 25},
+	} {
+		req := LeafDataPageRequest{Limit: test.limit}
+		req.ClampLimit(25)
+		if req.Limit != test.expected {
+			t.Errorf("%s: expected limit %d, got %d", name, test.expected, req.Limit)
+		}
+	}
+}
+
+func TestLeafDataTruncate(t *testing.T) {
+	data := testLeafData()
+	data.Truncate(0)
+	if len(data.Rows) != 4 || data.Truncated || data.QueryRowCount() != 4 {
+		t.Errorf("expected no truncation when there is no limit")
+	}
+	data.Truncate(3)
+	if len(data.Rows) != 3 || !data.Truncated {
+		t.Errorf("expected the rows to be truncated")
+	}
+	if count := data.QueryRowCount(); count != 4 {
+		t.Errorf("expected the query row count to include the truncated rows, got %d", count)
+	}
+	if page, _ := data.Page(LeafDataPageRequest{Limit: 1}); !page.Truncated {
+		t.Errorf("expected a page of truncated data to be marked as truncated")
+	}
+}
@@ -0,0 +1,57 @@
+package dashboardtypes
+
+import "time"
+
+// PanelTiming records the timing of the execution of a dashboard panel
+type PanelTiming struct {
+	// the time the panel execution was requested - the panel may then wait for its runtime dependencies
+	QueuedAt time.Time `json:"queued_at"`
+	// the time the panel query started
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	// the time the panel completed (or failed)
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// the duration of the query execution, in milliseconds
+	QueryDuration *int64 `json:"query_duration_ms,omitempty"`
+	RowCount      *int   `json:"row_count,omitempty"`
+	// the database the query was executed against
+	Database string `json:"database,omitempty"`
+	// whether the query result was retrieved from the query cache
+	Cached bool `json:"cached,omitempty"`
+}
+
+// GetQueryDuration returns the duration of the query execution
+func (t *PanelTiming) GetQueryDuration() time.Duration {
+	if t.QueryDuration == nil {
+		return 0
+	}
+	return time.Duration(*t.QueryDuration) * time.Millisecond
+}
+
+// GetWaitDuration returns the time between the panel execution being requested and the query starting
+func (t *PanelTiming) GetWaitDuration() time.Duration {
+	if t.StartedAt == nil {
+		return 0
+	}
+	return t.StartedAt.Sub(t.QueuedAt)
+}
+
+func (t *PanelTiming) SetStarted() {
+	now := time.Now()
+	t.StartedAt = &now
+}
+
+// SetQueryComplete records the duration and row count of the query
+func (t *PanelTiming) SetQueryComplete(rowCount int, cached bool) {
+	var duration int64
+	if t.StartedAt != nil {
+		duration = time.Since(*t.StartedAt).Milliseconds()
+	}
+	t.QueryDuration = &duration
+	t.RowCount = &rowCount
+	t.Cached = cached
+}
+
+func (t *PanelTiming) SetFinished() {
+	now := time.Now()
+	t.FinishedAt = &now
+}
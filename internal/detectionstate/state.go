@@ -0,0 +1,185 @@
+package detectionstate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/filepaths"
+)
+
+const stateFileName = "detection_state.json"
+
+// FingerprintRetention is how long the fingerprint of a result is retained after it was last seen
+// a result which recurs after this is reported again
+const FingerprintRetention = 90 * 24 * time.Hour
+
+// FingerprintRecord records when the result rows with a given fingerprint were first and last seen
+type FingerprintRecord struct {
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// State records the high-water mark of incremental detection runs,
+// i.e. the time up to which each detection has been evaluated, per connection,
+// and the fingerprints of the detection results which have been reported
+type State struct {
+	// map of detection name to connection to high-water mark
+	Detections map[string]map[string]time.Time `json:"detections"`
+	// map of detection name to fingerprint to the times the fingerprint was seen
+	Fingerprints map[string]map[string]*FingerprintRecord `json:"fingerprints,omitempty"`
+
+	path string
+	mut  sync.Mutex
+}
+
+// StatePath returns the path of the detection state file in the install dir
+func StatePath() string {
+	return filepath.Join(filepaths.GetInternalDir(), stateFileName)
+}
+
+// Load loads the detection state file from the install dir
+func Load() (*State, error) {
+	// ensure the internal dir exists
+	filepaths.EnsureInternalDir()
+	return LoadFromPath(StatePath())
+}
+
+// LoadFromPath loads the detection state file at the given path
+// if the file does not exist, an empty state is returned
+func LoadFromPath(path string) (*State, error) {
+	s := &State{
+		Detections:   make(map[string]map[string]time.Time),
+		Fingerprints: make(map[string]map[string]*FingerprintRecord),
+		path:         path,
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("failed to read detection state: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse detection state file %s: %w", path, err)
+	}
+	if s.Detections == nil {
+		s.Detections = make(map[string]map[string]time.Time)
+	}
+	if s.Fingerprints == nil {
+		s.Fingerprints = make(map[string]map[string]*FingerprintRecord)
+	}
+	return s, nil
+}
+
+// GetHighWaterMark returns the time up to which the detection has been evaluated for the given connection
+func (s *State) GetHighWaterMark(detection, connection string) (time.Time, bool) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	t, ok := s.Detections[detection][connection]
+	return t, ok
+}
+
+// SetHighWaterMark records the time up to which the detection has been evaluated for the given connection
+// the high-water mark is never moved backwards
+func (s *State) SetHighWaterMark(detection, connection string, t time.Time) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	connections, ok := s.Detections[detection]
+	if !ok {
+		connections = make(map[string]time.Time)
+		s.Detections[detection] = connections
+	}
+	if current, ok := connections[connection]; ok && current.After(t) {
+		return
+	}
+	connections[connection] = t.UTC()
+}
+
+// RecordFingerprint records that a result row of the detection with the given fingerprint was seen,
+// returning whether this is the first time the fingerprint has been seen
+func (s *State) RecordFingerprint(detection, fingerprint string, seen time.Time) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	fingerprints, ok := s.Fingerprints[detection]
+	if !ok {
+		fingerprints = make(map[string]*FingerprintRecord)
+		s.Fingerprints[detection] = fingerprints
+	}
+	seen = seen.UTC()
+	if record, ok := fingerprints[fingerprint]; ok {
+		if seen.After(record.LastSeen) {
+			record.LastSeen = seen
+		}
+		return false
+	}
+	fingerprints[fingerprint] = &FingerprintRecord{FirstSeen: seen, LastSeen: seen}
+	return true
+}
+
+// PruneFingerprints removes the fingerprints which have not been seen since the given time,
+// so the state does not grow without bound
+func (s *State) PruneFingerprints(lastSeenBefore time.Time) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for detection, fingerprints := range s.Fingerprints {
+		for fingerprint, record := range fingerprints {
+			if record.LastSeen.Before(lastSeenBefore) {
+				delete(fingerprints, fingerprint)
+			}
+		}
+		if len(fingerprints) == 0 {
+			delete(s.Fingerprints, detection)
+		}
+	}
+}
+
+// Reset removes the high-water marks and fingerprints of the given detections,
+// so they are evaluated in full, and all results are reported, on their next run
+func (s *State) Reset(detections ...string) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for _, detection := range detections {
+		delete(s.Detections, detection)
+		delete(s.Fingerprints, detection)
+	}
+}
+
+// Save writes the state to the file it was loaded from
+// the state is written to a temporary file which is then renamed, so a concurrent run never reads a partial file
+func (s *State) Save() error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), strings.TrimSuffix(filepath.Base(s.path), ".json")+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write detection state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write detection state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write detection state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write detection state: %w", err)
+	}
+	return nil
+}
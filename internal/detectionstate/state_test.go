@@ -0,0 +1,155 @@
+package detectionstate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStateSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), stateFileName)
+
+	s, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.GetHighWaterMark("mod.detection.a", "tailpipe"); ok {
+		t.Fatalf("expected no high-water mark for a new state")
+	}
+
+	mark := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s.SetHighWaterMark("mod.detection.a", "tailpipe", mark)
+	s.SetHighWaterMark("mod.detection.a", "other", mark.Add(time.Hour))
+	s.SetHighWaterMark("mod.detection.b", "tailpipe", mark)
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := loaded.GetHighWaterMark("mod.detection.a", "tailpipe"); !ok || !got.Equal(mark) {
+		t.Errorf("expected high-water mark %v, got %v", mark, got)
+	}
+	if got, ok := loaded.GetHighWaterMark("mod.detection.a", "other"); !ok || !got.Equal(mark.Add(time.Hour)) {
+		t.Errorf("expected the high-water mark to be recorded per connection, got %v", got)
+	}
+
+	// no temporary files should be left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the state file, got %d files", len(entries))
+	}
+}
+
+func TestStateHighWaterMarkNeverMovesBackwards(t *testing.T) {
+	s, err := LoadFromPath(filepath.Join(t.TempDir(), stateFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mark := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s.SetHighWaterMark("mod.detection.a", "tailpipe", mark)
+	s.SetHighWaterMark("mod.detection.a", "tailpipe", mark.Add(-time.Hour))
+	if got, _ := s.GetHighWaterMark("mod.detection.a", "tailpipe"); !got.Equal(mark) {
+		t.Errorf("expected high-water mark %v, got %v", mark, got)
+	}
+}
+
+func TestStateReset(t *testing.T) {
+	s, err := LoadFromPath(filepath.Join(t.TempDir(), stateFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mark := time.Now()
+	s.SetHighWaterMark("mod.detection.a", "tailpipe", mark)
+	s.SetHighWaterMark("mod.detection.b", "tailpipe", mark)
+
+	s.Reset("mod.detection.a")
+	if _, ok := s.GetHighWaterMark("mod.detection.a", "tailpipe"); ok {
+		t.Errorf("expected the high-water mark of the reset detection to be removed")
+	}
+	if _, ok := s.GetHighWaterMark("mod.detection.b", "tailpipe"); !ok {
+		t.Errorf("expected the high-water mark of other detections to be retained")
+	}
+}
+
+func TestLoadInvalidState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), stateFileName)
+	if err := os.WriteFile(path, []byte("not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFromPath(path); err == nil {
+		t.Errorf("expected an error loading an invalid state file")
+	}
+}
+
+func TestStateRecordFingerprint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), stateFileName)
+	s, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if !s.RecordFingerprint("mod.detection.a", "abc", first) {
+		t.Errorf("expected the fingerprint to be first seen")
+	}
+	if s.RecordFingerprint("mod.detection.a", "abc", first.Add(time.Hour)) {
+		t.Errorf("expected the fingerprint to have been seen")
+	}
+	if !s.RecordFingerprint("mod.detection.b", "abc", first) {
+		t.Errorf("expected fingerprints to be recorded per detection")
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, ok := loaded.Fingerprints["mod.detection.a"]["abc"]
+	if !ok {
+		t.Fatalf("expected the fingerprint to be saved")
+	}
+	if !record.FirstSeen.Equal(first) || !record.LastSeen.Equal(first.Add(time.Hour)) {
+		t.Errorf("expected first seen %v and last seen %v, got %v and %v", first, first.Add(time.Hour), record.FirstSeen, record.LastSeen)
+	}
+
+	if loaded.RecordFingerprint("mod.detection.a", "abc", first.Add(2*time.Hour)) {
+		t.Errorf("expected the saved fingerprint to have been seen")
+	}
+
+	loaded.Reset("mod.detection.a")
+	if !loaded.RecordFingerprint("mod.detection.a", "abc", first.Add(3*time.Hour)) {
+		t.Errorf("expected the fingerprints of the reset detection to be removed")
+	}
+}
+
+func TestStatePruneFingerprints(t *testing.T) {
+	s, err := LoadFromPath(filepath.Join(t.TempDir(), stateFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s.RecordFingerprint("mod.detection.a", "old", now.Add(-100*24*time.Hour))
+	s.RecordFingerprint("mod.detection.a", "recurring", now.Add(-100*24*time.Hour))
+	s.RecordFingerprint("mod.detection.a", "recurring", now.Add(-time.Hour))
+	s.RecordFingerprint("mod.detection.b", "old", now.Add(-100*24*time.Hour))
+
+	s.PruneFingerprints(now.Add(-FingerprintRetention))
+
+	if _, ok := s.Fingerprints["mod.detection.b"]; ok {
+		t.Errorf("expected a detection with no remaining fingerprints to be removed")
+	}
+	if !s.RecordFingerprint("mod.detection.a", "old", now) {
+		t.Errorf("expected the fingerprint not seen within the retention period to be pruned")
+	}
+	if s.RecordFingerprint("mod.detection.a", "recurring", now) {
+		t.Errorf("expected the fingerprint seen within the retention period to be retained")
+	}
+}
@@ -0,0 +1,45 @@
+package display
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+)
+
+// PrintDashboardTiming prints a table of the timing of each dashboard panel which executed a query,
+// with the slowest panels first
+func PrintDashboardTiming(w io.Writer, timings map[string]*dashboardtypes.PanelTiming) error {
+	names := slices.DeleteFunc(slices.Collect(maps.Keys(timings)), func(name string) bool {
+		return timings[name] == nil || timings[name].QueryDuration == nil
+	})
+	if len(names) == 0 {
+		return nil
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		if c := cmp.Compare(timings[b].GetQueryDuration(), timings[a].GetQueryDuration()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "\nPANEL\tQUERY TIME\tWAIT TIME\tROWS\tDATABASE")
+	for _, name := range names {
+		timing := timings[name]
+		queryTime := getDurationString(timing.GetQueryDuration())
+		if timing.Cached {
+			queryTime += " (cached)"
+		}
+		var rows int
+		if timing.RowCount != nil {
+			rows = *timing.RowCount
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", name, queryTime, getDurationString(timing.GetWaitDuration()), rows, timing.Database)
+	}
+	return tw.Flush()
+}
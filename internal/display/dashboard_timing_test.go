@@ -0,0 +1,37 @@
+package display
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+)
+
+func TestPrintDashboardTiming(t *testing.T) {
+	duration := func(ms int64) *int64 { return &ms }
+	timings := map[string]*dashboardtypes.PanelTiming{
+		"dashboard.d.card.fast":    {QueryDuration: duration(10), Database: "postgres://steampipe@localhost/steampipe"},
+		"dashboard.d.chart.slow":   {QueryDuration: duration(2500)},
+		"dashboard.d.table.cached": {QueryDuration: duration(0), Cached: true},
+		// panels which did not execute a query are not included
+		"dashboard.d.text.t": {},
+	}
+
+	var b strings.Builder
+	if err := PrintDashboardTiming(&b, timings); err != nil {
+		t.Fatal(err)
+	}
+	output := b.String()
+	slow := strings.Index(output, "dashboard.d.chart.slow")
+	fast := strings.Index(output, "dashboard.d.card.fast")
+	cached := strings.Index(output, "dashboard.d.table.cached")
+	if slow == -1 || fast == -1 || cached == -1 || !(slow < fast && fast < cached) {
+		t.Errorf("expected panels to be sorted by query time, got:\n%s", output)
+	}
+	if strings.Contains(output, "dashboard.d.text.t") {
+		t.Errorf("expected panels without a query to be excluded, got:\n%s", output)
+	}
+	if !strings.Contains(output, "2.5s") || !strings.Contains(output, "(cached)") {
+		t.Errorf("expected durations and cached panels to be shown, got:\n%s", output)
+	}
+}
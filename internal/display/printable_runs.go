@@ -0,0 +1,39 @@
+package display
+
+import (
+	"github.com/turbot/pipe-fittings/v2/printers"
+	"github.com/turbot/powerpipe/internal/history"
+)
+
+// PrintableRuns is a printers.PrintableResource for run history
+// unlike PrintableHclResource, the order of the runs is preserved (most recent first)
+type PrintableRuns struct {
+	Items []*history.Run
+}
+
+func NewPrintableRuns(items []*history.Run) *PrintableRuns {
+	return &PrintableRuns{
+		Items: items,
+	}
+}
+
+func (p PrintableRuns) GetItems() []*history.Run {
+	return p.Items
+}
+
+func (p PrintableRuns) GetTable() (*printers.Table, error) {
+	var rows []printers.TableRow
+	var columns []string
+	for _, item := range p.Items {
+		row := item.GetListData().GetRow()
+		if len(columns) == 0 {
+			columns = row.Columns
+		}
+		cleanRow(*row)
+		rows = append(rows, *row)
+	}
+	if len(rows) == 0 {
+		return printers.NewTable(), nil
+	}
+	return printers.NewTable().WithData(rows, columns), nil
+}
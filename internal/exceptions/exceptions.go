@@ -0,0 +1,189 @@
+package exceptions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/turbot/pipe-fittings/v2/error_helpers"
+	"github.com/turbot/powerpipe/internal/resources"
+	"gopkg.in/yaml.v3"
+)
+
+// the date format accepted for the 'expires' property (an RFC 3339 timestamp may also be used)
+const expiresDateFormat = "2006-01-02"
+
+// Exception is an accepted control alarm - alarms for the control which match the resource and/or
+// dimensions of the exception are reported with the 'suppressed' status until the exception expires
+type Exception struct {
+	Name       string            `json:"name,omitempty" yaml:"name" hcl:"name,label"`
+	Control    string            `json:"control" yaml:"control" hcl:"control"`
+	Resource   string            `json:"resource,omitempty" yaml:"resource" hcl:"resource,optional"`
+	Dimensions map[string]string `json:"dimensions,omitempty" yaml:"dimensions" hcl:"dimensions,optional"`
+	Reason     string            `json:"reason" yaml:"reason" hcl:"reason"`
+	Owner      string            `json:"owner" yaml:"owner" hcl:"owner"`
+	Expires    string            `json:"expires" yaml:"expires" hcl:"expires"`
+
+	// the time at which the exception expires, parsed from Expires
+	expiresAt time.Time
+}
+
+// String returns the name of the exception if it has one, otherwise the control and resource it applies to
+func (e *Exception) String() string {
+	if e.Name != "" {
+		return e.Name
+	}
+	if e.Resource != "" {
+		return fmt.Sprintf("%s (%s)", e.Control, e.Resource)
+	}
+	return e.Control
+}
+
+// Expired returns whether the exception has expired at the given time
+func (e *Exception) Expired(now time.Time) bool {
+	return !now.Before(e.expiresAt)
+}
+
+func (e *Exception) validate() error {
+	var missing []string
+	for _, p := range []struct{ name, value string }{
+		{"control", e.Control},
+		{"reason", e.Reason},
+		{"owner", e.Owner},
+		{"expires", e.Expires},
+	} {
+		if p.value == "" {
+			missing = append(missing, p.name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("exception '%s' is missing required properties: %s", e, strings.Join(missing, ", "))
+	}
+	if e.Resource == "" && len(e.Dimensions) == 0 {
+		return fmt.Errorf("exception '%s' must specify a resource or dimensions to match", e)
+	}
+
+	// an expiry date is inclusive, i.e. the exception expires at the end of the given day
+	if t, err := time.ParseInLocation(expiresDateFormat, e.Expires, time.Local); err == nil {
+		e.expiresAt = t.AddDate(0, 0, 1)
+	} else if t, err := time.Parse(time.RFC3339, e.Expires); err == nil {
+		e.expiresAt = t
+	} else {
+		return fmt.Errorf("exception '%s' has an invalid expiry '%s' - expected a date (YYYY-MM-DD) or an RFC 3339 timestamp", e, e.Expires)
+	}
+	return nil
+}
+
+// matches returns whether the exception applies to a result row for the given control, resource and dimensions
+func (e *Exception) matches(control *resources.Control, resource string, dimensions map[string]string) bool {
+	if e.Control != control.FullName && e.Control != control.UnqualifiedName {
+		return false
+	}
+	if e.Resource != "" && e.Resource != resource {
+		return false
+	}
+	for k, v := range e.Dimensions {
+		if dimensions[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Exceptions is the set of exceptions loaded from an exceptions file
+type Exceptions struct {
+	// the path the exceptions were loaded from
+	Path       string       `yaml:"-"`
+	Exceptions []*Exception `yaml:"exceptions"`
+}
+
+// Load loads exceptions from a YAML (.yml, .yaml) or HCL file
+//
+// HCL files contain one or more 'exception' blocks:
+//
+//	exception "logs_bucket" {
+//	  control  = "aws_compliance.control.s3_bucket_restrict_public_read_access"
+//	  resource = "arn:aws:s3:::logs"
+//	  reason   = "Public access is required by the log viewer"
+//	  owner    = "platform-team"
+//	  expires  = "2025-12-31"
+//	}
+//
+// YAML files contain a list of the same properties under a top level 'exceptions' key
+func Load(path string) (*Exceptions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exceptions file: %w", err)
+	}
+
+	res := &Exceptions{Path: path}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		err = res.loadYaml(data)
+	default:
+		err = res.loadHcl(path, data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse exceptions file '%s': %w", path, err)
+	}
+
+	for _, e := range res.Exceptions {
+		if err := e.validate(); err != nil {
+			return nil, fmt.Errorf("invalid exceptions file '%s': %w", path, err)
+		}
+	}
+	return res, nil
+}
+
+func (e *Exceptions) loadYaml(data []byte) error {
+	return yaml.Unmarshal(data, e)
+}
+
+func (e *Exceptions) loadHcl(path string, data []byte) error {
+	file, diags := hclparse.NewParser().ParseHCL(data, path)
+	if diags.HasErrors() {
+		return error_helpers.HclDiagsToError("Failed to parse exceptions", diags)
+	}
+
+	var content struct {
+		Exceptions []*Exception `hcl:"exception,block"`
+	}
+	if diags := gohcl.DecodeBody(file.Body, &hcl.EvalContext{}, &content); diags.HasErrors() {
+		return error_helpers.HclDiagsToError("Failed to decode exceptions", diags)
+	}
+	e.Exceptions = content.Exceptions
+	return nil
+}
+
+// Match returns the first unexpired exception which applies to a result row for the given control,
+// resource and dimensions, or nil if there is none
+func (e *Exceptions) Match(control *resources.Control, resource string, dimensions map[string]string, now time.Time) *Exception {
+	if e == nil {
+		return nil
+	}
+	for _, exception := range e.Exceptions {
+		if !exception.Expired(now) && exception.matches(control, resource, dimensions) {
+			return exception
+		}
+	}
+	return nil
+}
+
+// Expired returns the exceptions which have expired at the given time
+func (e *Exceptions) Expired(now time.Time) []*Exception {
+	if e == nil {
+		return nil
+	}
+	var res []*Exception
+	for _, exception := range e.Exceptions {
+		if exception.Expired(now) {
+			res = append(res, exception)
+		}
+	}
+	return res
+}
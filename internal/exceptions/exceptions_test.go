@@ -0,0 +1,140 @@
+package exceptions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/turbot/powerpipe/internal/resources"
+)
+
+const testHcl = `
+exception "logs_bucket" {
+  control  = "control.s3_public"
+  resource = "arn:aws:s3:::logs"
+  reason   = "public access is required by the log viewer"
+  owner    = "platform"
+  expires  = "2030-06-30"
+}
+
+exception "old" {
+  control    = "mod.control.s3_public"
+  dimensions = { region = "us-east-1" }
+  reason     = "legacy"
+  owner      = "security"
+  expires    = "2020-01-01"
+}
+`
+
+const testYaml = `
+exceptions:
+  - control: mod.control.s3_public
+    dimensions:
+      account: "123"
+      region: us-east-1
+    reason: shared account
+    owner: platform
+    expires: 2030-06-30
+`
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testControl() *resources.Control {
+	control := &resources.Control{}
+	control.FullName = "mod.control.s3_public"
+	control.UnqualifiedName = "control.s3_public"
+	return control
+}
+
+func TestLoadHcl(t *testing.T) {
+	e, err := Load(writeFile(t, "exceptions.pp", testHcl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.Exceptions) != 2 {
+		t.Fatalf("expected 2 exceptions, got %d", len(e.Exceptions))
+	}
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)
+	control := testControl()
+
+	// the unqualified control name matches
+	if match := e.Match(control, "arn:aws:s3:::logs", nil, now); match == nil || match.Name != "logs_bucket" {
+		t.Errorf("expected the logs_bucket exception to match, got %v", match)
+	}
+	if match := e.Match(control, "arn:aws:s3:::other", nil, now); match != nil {
+		t.Errorf("expected no match for a different resource, got %s", match)
+	}
+	// the second exception has expired, so does not match
+	if match := e.Match(control, "arn:aws:s3:::other", map[string]string{"region": "us-east-1"}, now); match != nil {
+		t.Errorf("expected an expired exception not to match, got %s", match)
+	}
+
+	expired := e.Expired(now)
+	if len(expired) != 1 || expired[0].Name != "old" {
+		t.Errorf("expected the 'old' exception to have expired, got %v", expired)
+	}
+}
+
+func TestLoadYaml(t *testing.T) {
+	e, err := Load(writeFile(t, "exceptions.yaml", testYaml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	control := testControl()
+
+	// the expiry date is inclusive
+	lastDay := time.Date(2030, 6, 30, 23, 0, 0, 0, time.Local)
+	if match := e.Match(control, "r1", map[string]string{"account": "123", "region": "us-east-1", "other": "x"}, lastDay); match == nil {
+		t.Error("expected the exception to match on its expiry date")
+	}
+	if match := e.Match(control, "r1", map[string]string{"account": "123", "region": "us-west-2"}, lastDay); match != nil {
+		t.Error("expected no match when a dimension differs")
+	}
+	if match := e.Match(control, "r1", map[string]string{"account": "123", "region": "us-east-1"}, lastDay.Add(2*time.Hour)); match != nil {
+		t.Error("expected no match after the expiry date")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		err     string
+	}{
+		"missing owner": {
+			content: "exceptions:\n  - control: control.c1\n    resource: r1\n    reason: r\n    expires: 2030-01-01\n",
+			err:     "missing required properties: owner",
+		},
+		"no matchers": {
+			content: "exceptions:\n  - control: control.c1\n    reason: r\n    owner: o\n    expires: 2030-01-01\n",
+			err:     "must specify a resource or dimensions",
+		},
+		"invalid expiry": {
+			content: "exceptions:\n  - control: control.c1\n    resource: r1\n    reason: r\n    owner: o\n    expires: next year\n",
+			err:     "invalid expiry 'next year'",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(writeFile(t, "exceptions.yml", test.content))
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("expected error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestNilExceptions(t *testing.T) {
+	var e *Exceptions
+	if match := e.Match(testControl(), "r1", nil, time.Now()); match != nil {
+		t.Errorf("expected no match, got %s", match)
+	}
+}
@@ -0,0 +1,98 @@
+package history
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/turbot/powerpipe/internal/controlexecute"
+	"github.com/turbot/powerpipe/internal/controlstatus"
+)
+
+// HistoryControlHooks is a struct which implements ControlHooks, and records the control results in the history store
+type HistoryControlHooks struct {
+	target    string
+	variables map[string]string
+
+	startTime time.Time
+	results   []*Result
+	resultMut sync.Mutex
+}
+
+func NewHistoryControlHooks(target string, variables map[string]string) *HistoryControlHooks {
+	return &HistoryControlHooks{
+		target:    target,
+		variables: variables,
+	}
+}
+
+func (h *HistoryControlHooks) OnStart(context.Context, *controlstatus.ControlProgress) {
+	h.startTime = time.Now()
+}
+
+func (h *HistoryControlHooks) OnControlStart(context.Context, controlstatus.ControlRunStatusProvider, *controlstatus.ControlProgress) {
+}
+
+func (h *HistoryControlHooks) OnControlComplete(_ context.Context, controlRun controlstatus.ControlRunStatusProvider, _ *controlstatus.ControlProgress) {
+	h.addResult(controlRun)
+}
+
+func (h *HistoryControlHooks) OnControlError(_ context.Context, controlRun controlstatus.ControlRunStatusProvider, _ *controlstatus.ControlProgress) {
+	h.addResult(controlRun)
+}
+
+func (h *HistoryControlHooks) OnComplete(ctx context.Context, progress *controlstatus.ControlProgress) {
+	h.resultMut.Lock()
+	defer h.resultMut.Unlock()
+
+	summary := *progress.StatusSummaries
+	run := &Run{
+		Id:        newRunId(),
+		Target:    h.target,
+		RunType:   RunTypeCheck,
+		Variables: h.variables,
+		StartTime: h.startTime,
+		EndTime:   time.Now(),
+		Summary:   &summary,
+		Results:   h.results,
+	}
+	// a failure to save history should not fail the run
+	// (save even if the run was cancelled, so that partial results are recorded)
+	if err := SaveRun(context.WithoutCancel(ctx), run); err != nil {
+		slog.Warn("failed to save run history", "target", h.target, "error", err)
+	}
+}
+
+func (h *HistoryControlHooks) addResult(controlRun controlstatus.ControlRunStatusProvider) {
+	res := &Result{
+		Name:    controlRun.GetControlId(),
+		Status:  controlRun.GetRunStatus(),
+		Summary: controlRun.GetStatusSummary(),
+	}
+	if run, ok := controlRun.(*controlexecute.ControlRun); ok {
+		res.Name = run.FullName
+		res.Rows = run.GetRows().ToLeafData(nil).Rows
+		if err := run.GetError(); err != nil {
+			res.Error = err.Error()
+		}
+	}
+
+	h.resultMut.Lock()
+	defer h.resultMut.Unlock()
+	h.results = append(h.results, res)
+}
+
+// SaveRun opens the history store and saves the run, pruning runs beyond the retention limits
+func SaveRun(ctx context.Context, run *Run) error {
+	store, err := OpenStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := store.Save(ctx, run); err != nil {
+		return err
+	}
+	return store.Prune(ctx, time.Now().Add(-RunRetention), MaxRuns)
+}
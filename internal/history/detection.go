@@ -0,0 +1,42 @@
+package history
+
+import (
+	"github.com/turbot/powerpipe/internal/dashboardexecute"
+)
+
+// NewDetectionRun builds a history Run from an executed detection (or detection benchmark) display tree
+// NOTE: detection runs do not raise control events, so unlike benchmarks they are not recorded using HistoryControlHooks
+func NewDetectionRun(tree *dashboardexecute.DetectionBenchmarkDisplayTree, target string, inputs map[string]any, variables map[string]string) *Run {
+	run := &Run{
+		Id:        newRunId(),
+		Target:    target,
+		RunType:   RunTypeDetection,
+		Inputs:    inputs,
+		Variables: variables,
+		StartTime: tree.StartTime,
+		EndTime:   tree.EndTime,
+	}
+	addDetectionResults(run, tree.Root)
+	return run
+}
+
+func addDetectionResults(run *Run, group *dashboardexecute.DetectionBenchmarkDisplay) {
+	if group == nil {
+		return
+	}
+	for _, d := range group.DetectionRuns {
+		res := &Result{
+			Name:   d.Name,
+			Status: d.Status,
+			Error:  d.ErrorString,
+		}
+		if d.Data != nil {
+			res.Rows = d.Data.Rows
+			run.RowCount += len(d.Data.Rows)
+		}
+		run.Results = append(run.Results, res)
+	}
+	for _, child := range group.Groups {
+		addDetectionResults(run, child)
+	}
+}
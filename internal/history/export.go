@@ -0,0 +1,62 @@
+package history
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+
+	"golang.org/x/exp/maps"
+)
+
+// WriteCSV writes the results of the run as CSV
+// there is a row for every result row - results which returned no rows are written as a single row
+func (r *Run) WriteCSV(w io.Writer, separator rune, header bool) error {
+	// build the set of row columns across all results
+	columnLookup := make(map[string]struct{})
+	for _, res := range r.Results {
+		for _, row := range res.Rows {
+			for k := range row {
+				columnLookup[k] = struct{}{}
+			}
+		}
+	}
+	rowColumns := maps.Keys(columnLookup)
+	slices.Sort(rowColumns)
+
+	writer := csv.NewWriter(w)
+	writer.Comma = separator
+
+	if header {
+		if err := writer.Write(append([]string{"run_id", "target", "name", "result_status", "error"}, rowColumns...)); err != nil {
+			return err
+		}
+	}
+	for _, res := range r.Results {
+		prefix := []string{r.Id, r.Target, res.Name, string(res.Status), res.Error}
+		if len(res.Rows) == 0 {
+			if err := writer.Write(append(prefix, make([]string, len(rowColumns))...)); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, row := range res.Rows {
+			record := slices.Clone(prefix)
+			for _, c := range rowColumns {
+				record = append(record, csvValue(row[c]))
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func csvValue(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
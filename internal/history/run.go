@@ -0,0 +1,103 @@
+package history
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/printers"
+	"github.com/turbot/powerpipe/internal/controlstatus"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+)
+
+const (
+	RunTypeCheck     = "check"
+	RunTypeDetection = "detection"
+)
+
+// Run is a single benchmark, control or detection execution stored in the history
+type Run struct {
+	Id        string                       `json:"id"`
+	Target    string                       `json:"target"`
+	RunType   string                       `json:"run_type"`
+	Inputs    map[string]any               `json:"inputs,omitempty"`
+	Variables map[string]string            `json:"variables,omitempty"`
+	StartTime time.Time                    `json:"start_time"`
+	EndTime   time.Time                    `json:"end_time"`
+	Summary   *controlstatus.StatusSummary `json:"summary,omitempty"`
+	// the number of rows returned by all detections (detection runs only)
+	RowCount int `json:"row_count"`
+	// inputs, variables and results are only populated when a single run is loaded
+	Results []*Result `json:"results,omitempty"`
+}
+
+// Result is the result of a single control or detection within a Run
+type Result struct {
+	Name    string                       `json:"name"`
+	Status  dashboardtypes.RunStatus     `json:"status"`
+	Summary *controlstatus.StatusSummary `json:"summary,omitempty"`
+	Error   string                       `json:"error,omitempty"`
+	Rows    []map[string]any             `json:"rows,omitempty"`
+}
+
+func (r *Run) Duration() time.Duration {
+	return r.EndTime.Sub(r.StartTime)
+}
+
+// GetListData implements printers.Listable
+func (r *Run) GetListData() *printers.RowData {
+	return printers.NewRowData(
+		printers.NewFieldValue("ID", r.Id),
+		printers.NewFieldValue("TARGET", r.Target),
+		printers.NewFieldValue("TYPE", r.RunType),
+		printers.NewFieldValue("START TIME", r.StartTime.Format(time.RFC3339)),
+		printers.NewFieldValue("DURATION", r.Duration().Round(time.Millisecond).String()),
+		printers.NewFieldValue("RESULT", r.resultString()),
+	)
+}
+
+// GetShowData implements printers.Showable
+func (r *Run) GetShowData() *printers.RowData {
+	return printers.NewRowData(
+		printers.NewFieldValue("ID", r.Id),
+		printers.NewFieldValue("Target", r.Target),
+		printers.NewFieldValue("Type", r.RunType),
+		printers.NewFieldValue("Start Time", r.StartTime.Format(time.RFC3339)),
+		printers.NewFieldValue("End Time", r.EndTime.Format(time.RFC3339)),
+		printers.NewFieldValue("Result", r.resultString()),
+		printers.NewFieldValue("Inputs", r.Inputs),
+		printers.NewFieldValue("Variables", r.Variables),
+		printers.NewFieldValue("Results", r.Results),
+	)
+}
+
+func (r *Run) resultString() string {
+	if r.RunType == RunTypeDetection || r.Summary == nil {
+		return fmt.Sprintf("%d rows", r.RowCount)
+	}
+	return summaryString(r.Summary)
+}
+
+// GetShowData implements printers.Showable
+func (r *Result) GetShowData() *printers.RowData {
+	res := printers.NewRowData(
+		printers.NewFieldValue("Name", r.Name, printers.WithListKey()),
+		printers.NewFieldValue("Status", string(r.Status)),
+	)
+	if r.Summary != nil {
+		res.AddField(printers.NewFieldValue("Result", summaryString(r.Summary)))
+	} else {
+		res.AddField(printers.NewFieldValue("Rows", len(r.Rows)))
+	}
+	if r.Error != "" {
+		res.AddField(printers.NewFieldValue("Error", r.Error))
+	}
+	return res
+}
+
+func summaryString(s *controlstatus.StatusSummary) string {
+	res := fmt.Sprintf("alarm: %d, ok: %d, info: %d, skip: %d, error: %d", s.Alarm, s.Ok, s.Info, s.Skip, s.Error)
+	if s.Suppressed > 0 {
+		res += fmt.Sprintf(", suppressed: %d", s.Suppressed)
+	}
+	return res
+}
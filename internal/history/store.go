@@ -0,0 +1,245 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/turbot/pipe-fittings/v2/filepaths"
+	"github.com/turbot/powerpipe/internal/controlstatus"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+
+	// sqlite driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const storeFileName = "history.db"
+
+const (
+	// the maximum number of runs retained - the oldest runs are pruned when a run is saved
+	MaxRuns = 1000
+	// runs which started longer ago than this are pruned when a run is saved
+	RunRetention = 90 * 24 * time.Hour
+)
+
+// ErrRunNotFound is returned by Store.Get if there is no run with the given id
+var ErrRunNotFound = errors.New("run not found")
+
+const createTablesSQL = `
+create table if not exists run (
+	id text primary key,
+	target text not null,
+	run_type text not null,
+	inputs text,
+	variables text,
+	start_time timestamp not null,
+	end_time timestamp not null,
+	summary text,
+	row_count integer not null default 0
+);
+create index if not exists run_start_time_idx on run(start_time);
+create table if not exists run_result (
+	run_id text not null references run(id) on delete cascade,
+	idx integer not null,
+	name text not null,
+	status text not null,
+	summary text,
+	error text,
+	rows text,
+	primary key (run_id, idx)
+);`
+
+// Store is a sqlite backed store of run history
+type Store struct {
+	db *sql.DB
+}
+
+// StorePath returns the path of the history database in the install dir
+func StorePath() string {
+	return filepath.Join(filepaths.GetInternalDir(), storeFileName)
+}
+
+// StoreExists returns whether the history database has been created
+func StoreExists() bool {
+	_, err := os.Stat(StorePath())
+	return err == nil
+}
+
+// OpenStore opens the history database in the install dir, creating it if necessary
+func OpenStore(ctx context.Context) (*Store, error) {
+	// ensure the internal dir exists
+	filepaths.EnsureInternalDir()
+	return OpenStoreAtPath(ctx, StorePath())
+}
+
+// OpenStoreAtPath opens (or creates) a history database at the given path
+func OpenStoreAtPath(ctx context.Context, path string) (*Store, error) {
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000", path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history store: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, createTablesSQL); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialise history store: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Save writes a run and its results to the store
+func (s *Store) Save(ctx context.Context, run *Run) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx,
+		`insert into run (id, target, run_type, inputs, variables, start_time, end_time, summary, row_count) values (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.Id, run.Target, run.RunType, toJSON(run.Inputs), toJSON(run.Variables), run.StartTime.UTC(), run.EndTime.UTC(), toJSON(run.Summary), run.RowCount)
+	if err != nil {
+		return fmt.Errorf("failed to save run %s: %w", run.Id, err)
+	}
+
+	for i, r := range run.Results {
+		_, err = tx.ExecContext(ctx,
+			`insert into run_result (run_id, idx, name, status, summary, error, rows) values (?, ?, ?, ?, ?, ?, ?)`,
+			run.Id, i, r.Name, string(r.Status), toJSON(r.Summary), r.Error, toJSON(r.Rows))
+		if err != nil {
+			return fmt.Errorf("failed to save result %s for run %s: %w", r.Name, run.Id, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Prune deletes runs (and their results) which started before the given time,
+// and the oldest runs beyond the maximum number of runs
+func (s *Store) Prune(ctx context.Context, startedBefore time.Time, maxRuns int) error {
+	_, err := s.db.ExecContext(ctx,
+		`delete from run where start_time < ? or id not in (select id from run order by start_time desc limit ?)`,
+		startedBefore.UTC(), maxRuns)
+	if err != nil {
+		return fmt.Errorf("failed to prune run history: %w", err)
+	}
+	return nil
+}
+
+// List returns the most recent runs, optionally filtered by target
+// the results, inputs and variables of the runs are not loaded - variables may hold sensitive values,
+// and the list is sent to every client of the dashboard server
+func (s *Store) List(ctx context.Context, target string, limit int) ([]*Run, error) {
+	query := `select id, target, run_type, null, null, start_time, end_time, summary, row_count from run`
+	var args []any
+	if target != "" {
+		query += ` where target = ?`
+		args = append(args, target)
+	}
+	query += ` order by start_time desc limit ?`
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []*Run{}
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, run)
+	}
+	return res, rows.Err()
+}
+
+// Get returns a run, including its results
+func (s *Store) Get(ctx context.Context, id string) (*Run, error) {
+	row := s.db.QueryRowContext(ctx, `select id, target, run_type, inputs, variables, start_time, end_time, summary, row_count from run where id = ?`, id)
+	run, err := scanRun(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRunNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `select name, status, summary, error, rows from run_result where run_id = ? order by idx`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r Result
+		var status string
+		var summary, errorString, resultRows sql.NullString
+		if err := rows.Scan(&r.Name, &status, &summary, &errorString, &resultRows); err != nil {
+			return nil, err
+		}
+		r.Status = dashboardtypes.RunStatus(status)
+		r.Error = errorString.String
+		if err := fromJSON(summary, &r.Summary); err != nil {
+			return nil, err
+		}
+		if err := fromJSON(resultRows, &r.Rows); err != nil {
+			return nil, err
+		}
+		run.Results = append(run.Results, &r)
+	}
+	return run, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRun(row scanner) (*Run, error) {
+	var run Run
+	var inputs, variables, summary sql.NullString
+	if err := row.Scan(&run.Id, &run.Target, &run.RunType, &inputs, &variables, &run.StartTime, &run.EndTime, &summary, &run.RowCount); err != nil {
+		return nil, err
+	}
+	if err := fromJSON(inputs, &run.Inputs); err != nil {
+		return nil, err
+	}
+	if err := fromJSON(variables, &run.Variables); err != nil {
+		return nil, err
+	}
+	var s *controlstatus.StatusSummary
+	if err := fromJSON(summary, &s); err != nil {
+		return nil, err
+	}
+	run.Summary = s
+	run.StartTime = run.StartTime.Local()
+	run.EndTime = run.EndTime.Local()
+	return &run, nil
+}
+
+func toJSON(v any) sql.NullString {
+	b, err := json.Marshal(v)
+	if err != nil || string(b) == "null" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(b), Valid: true}
+}
+
+func fromJSON(s sql.NullString, target any) error {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(s.String), target)
+}
+
+func newRunId() string {
+	return fmt.Sprintf("run_%s", xid.New().String())
+}
@@ -0,0 +1,163 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/turbot/powerpipe/internal/controlstatus"
+	"github.com/turbot/powerpipe/internal/dashboardtypes"
+)
+
+func TestStoreSaveListGet(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenStoreAtPath(ctx, filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	start := time.Now().Add(-time.Hour)
+	older := &Run{
+		Id:        newRunId(),
+		Target:    "mod.benchmark.one",
+		RunType:   RunTypeCheck,
+		Variables: map[string]string{"region": "us-east-1"},
+		StartTime: start,
+		EndTime:   start.Add(time.Minute),
+		Summary:   &controlstatus.StatusSummary{Alarm: 1, Ok: 2},
+		Results: []*Result{
+			{Name: "mod.control.a", Status: dashboardtypes.RunComplete, Summary: &controlstatus.StatusSummary{Alarm: 1}, Rows: []map[string]any{{"status": "alarm", "resource": "r1"}}},
+			{Name: "mod.control.b", Status: dashboardtypes.RunError, Error: "boom"},
+		},
+	}
+	newer := &Run{
+		Id:        newRunId(),
+		Target:    "mod.detection.two",
+		RunType:   RunTypeDetection,
+		StartTime: start.Add(time.Minute * 30),
+		EndTime:   start.Add(time.Minute * 31),
+		RowCount:  3,
+	}
+	for _, r := range []*Run{older, newer} {
+		if err := store.Save(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runs, err := store.List(ctx, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].Id != newer.Id || runs[1].Id != older.Id {
+		t.Fatalf("expected runs to be listed most recent first, got %v", runs)
+	}
+	if len(runs[1].Results) != 0 || runs[1].Variables != nil {
+		t.Errorf("expected List to not load results or variables")
+	}
+
+	runs, err = store.List(ctx, "mod.benchmark.one", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].Id != older.Id {
+		t.Fatalf("expected target filter to return 1 run, got %v", runs)
+	}
+
+	got, err := store.Get(ctx, older.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Summary == nil || got.Summary.Alarm != 1 || got.Summary.Ok != 2 {
+		t.Errorf("unexpected summary %v", got.Summary)
+	}
+	if got.Variables["region"] != "us-east-1" {
+		t.Errorf("unexpected variables %v", got.Variables)
+	}
+	if len(got.Results) != 2 || got.Results[0].Name != "mod.control.a" || got.Results[1].Error != "boom" {
+		t.Fatalf("unexpected results %v", got.Results)
+	}
+	if got.Results[0].Rows[0]["resource"] != "r1" {
+		t.Errorf("unexpected rows %v", got.Results[0].Rows)
+	}
+
+	if _, err := store.Get(ctx, "run_missing"); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("expected ErrRunNotFound, got %v", err)
+	}
+}
+
+func TestStorePrune(t *testing.T) {
+	ctx := context.Background()
+	store, err := OpenStoreAtPath(ctx, filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	expired := &Run{
+		Id:        newRunId(),
+		Target:    "mod.benchmark.one",
+		RunType:   RunTypeCheck,
+		StartTime: now.Add(-2 * time.Hour),
+		EndTime:   now.Add(-2 * time.Hour),
+		Results:   []*Result{{Name: "mod.control.a", Status: dashboardtypes.RunComplete}},
+	}
+	if err := store.Save(ctx, expired); err != nil {
+		t.Fatal(err)
+	}
+	var recent []*Run
+	for i := range 3 {
+		run := &Run{Id: newRunId(), Target: "mod.benchmark.one", RunType: RunTypeCheck, StartTime: now.Add(time.Duration(i) * time.Minute), EndTime: now}
+		if err := store.Save(ctx, run); err != nil {
+			t.Fatal(err)
+		}
+		recent = append(recent, run)
+	}
+
+	// the expired run is pruned by age, and the oldest recent run by count
+	if err := store.Prune(ctx, now.Add(-time.Hour), 2); err != nil {
+		t.Fatal(err)
+	}
+	runs, err := store.List(ctx, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].Id != recent[2].Id || runs[1].Id != recent[1].Id {
+		t.Fatalf("expected the 2 most recent runs to be retained, got %v", runs)
+	}
+	// the results of pruned runs are deleted with them
+	var resultCount int
+	if err := store.db.QueryRowContext(ctx, `select count(*) from run_result`).Scan(&resultCount); err != nil {
+		t.Fatal(err)
+	}
+	if resultCount != 0 {
+		t.Errorf("expected the results of pruned runs to be deleted, got %d", resultCount)
+	}
+}
+
+func TestRunWriteCSV(t *testing.T) {
+	run := &Run{
+		Id:     "run_1",
+		Target: "mod.benchmark.one",
+		Results: []*Result{
+			{Name: "mod.control.a", Status: dashboardtypes.RunComplete, Rows: []map[string]any{{"status": "alarm", "resource": "r1"}}},
+			{Name: "mod.control.b", Status: dashboardtypes.RunError, Error: "boom"},
+		},
+	}
+	var buf bytes.Buffer
+	if err := run.WriteCSV(&buf, ',', true); err != nil {
+		t.Fatal(err)
+	}
+	expected := `run_id,target,name,result_status,error,resource,status
+run_1,mod.benchmark.one,mod.control.a,complete,,r1,alarm
+run_1,mod.benchmark.one,mod.control.b,error,boom,,
+`
+	if got := buf.String(); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, strings.TrimSpace(got))
+	}
+}
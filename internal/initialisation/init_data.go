@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/turbot/pipe-fittings/v2/connection"
 	"log/slog"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -190,7 +191,8 @@ func (i *InitData) Init(ctx context.Context, args ...string) {
 
 	// create the dashboard executor, passing the default client inside a client map
 	clientMap := db_client.NewClientMap().Add(client, searchPathConfig)
-	dashboardexecute.Executor = dashboardexecute.NewDashboardExecutor(clientMap, i.DefaultDatabase, i.DefaultSearchPathConfig)
+	queryCacheTtl := time.Duration(viper.GetInt(localconstants.ArgQueryCacheTtl)) * time.Second
+	dashboardexecute.Executor = dashboardexecute.NewDashboardExecutor(clientMap, i.DefaultDatabase, i.DefaultSearchPathConfig, queryCacheTtl)
 }
 
 func validateModRequirementsRecursively(mod *modconfig.Mod, client *db_client.DbClient) []string {
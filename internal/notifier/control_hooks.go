@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/pipe-fittings/v2/error_helpers"
+	"github.com/turbot/powerpipe/internal/controlexecute"
+	"github.com/turbot/powerpipe/internal/controlstatus"
+)
+
+// NotifierControlHooks is a struct which implements ControlHooks, and notifies webhooks
+// of the controls which have alarms when the run completes
+type NotifierControlHooks struct {
+	target   string
+	webhooks []*Webhook
+
+	startTime  time.Time
+	controls   []*ControlResult
+	controlMut sync.Mutex
+}
+
+func NewNotifierControlHooks(target string, webhooks []*Webhook) *NotifierControlHooks {
+	return &NotifierControlHooks{
+		target:   target,
+		webhooks: webhooks,
+	}
+}
+
+func (h *NotifierControlHooks) OnStart(context.Context, *controlstatus.ControlProgress) {
+	h.startTime = time.Now()
+}
+
+func (h *NotifierControlHooks) OnControlStart(context.Context, controlstatus.ControlRunStatusProvider, *controlstatus.ControlProgress) {
+}
+
+func (h *NotifierControlHooks) OnControlComplete(_ context.Context, controlRun controlstatus.ControlRunStatusProvider, _ *controlstatus.ControlProgress) {
+	summary := controlRun.GetStatusSummary()
+	if summary == nil || summary.Alarm == 0 {
+		return
+	}
+
+	res := &ControlResult{
+		Name:  controlRun.GetControlId(),
+		Alarm: summary.Alarm,
+	}
+	if run, ok := controlRun.(*controlexecute.ControlRun); ok {
+		res.Name = run.FullName
+		res.Title = run.Title
+		res.Severity = run.Severity
+		for _, row := range run.GetRows() {
+			if row.Status == constants.ControlAlarm {
+				res.Alarms = append(res.Alarms, &AlarmRow{Resource: row.Resource, Reason: row.Reason})
+			}
+		}
+	}
+
+	h.controlMut.Lock()
+	defer h.controlMut.Unlock()
+	h.controls = append(h.controls, res)
+}
+
+func (h *NotifierControlHooks) OnControlError(context.Context, controlstatus.ControlRunStatusProvider, *controlstatus.ControlProgress) {
+}
+
+func (h *NotifierControlHooks) OnComplete(ctx context.Context, progress *controlstatus.ControlProgress) {
+	h.controlMut.Lock()
+	defer h.controlMut.Unlock()
+
+	summary := *progress.StatusSummaries
+	endTime := time.Now()
+	// controls complete in parallel - sort so the notification is stable
+	slices.SortFunc(h.controls, func(a, b *ControlResult) int { return strings.Compare(a.Name, b.Name) })
+	for _, webhook := range h.webhooks {
+		notification := &Notification{
+			Notifier:  webhook.Name(),
+			Target:    h.target,
+			RunType:   RunTypeCheck,
+			StartTime: h.startTime,
+			EndTime:   endTime,
+			Summary:   &summary,
+		}
+		for _, c := range h.controls {
+			if severityMeetsThreshold(c.Severity, webhook.config.MinSeverity) {
+				notification.Controls = append(notification.Controls, c)
+			}
+		}
+		if notification.IsEmpty() {
+			continue
+		}
+		notification.Text = notification.buildText()
+
+		// a failure to notify should not fail the run
+		// (notify even if the run was cancelled, so that partial results are reported)
+		if err := webhook.Notify(context.WithoutCancel(ctx), notification); err != nil {
+			error_helpers.ShowWarning(fmt.Sprintf("failed to send notification: %s", err.Error()))
+		}
+	}
+}
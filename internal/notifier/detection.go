@@ -0,0 +1,65 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+
+	typeHelpers "github.com/turbot/go-kit/types"
+	"github.com/turbot/powerpipe/internal/dashboardexecute"
+)
+
+// NotifyDetection notifies the webhooks of the detections which returned rows
+// NOTE: detection runs do not raise control events, so unlike benchmarks they do not use NotifierControlHooks
+func NotifyDetection(ctx context.Context, webhooks []*Webhook, tree *dashboardexecute.DetectionBenchmarkDisplayTree, target string) error {
+	var detections []*DetectionResult
+	addDetectionResults(&detections, tree.Root)
+
+	var errs []error
+	for _, webhook := range webhooks {
+		notification := &Notification{
+			Notifier:  webhook.Name(),
+			Target:    target,
+			RunType:   RunTypeDetection,
+			StartTime: tree.StartTime,
+			EndTime:   tree.EndTime,
+		}
+		for _, d := range detections {
+			if severityMeetsThreshold(d.Severity, webhook.config.MinSeverity) {
+				notification.Detections = append(notification.Detections, d)
+			}
+		}
+		if notification.IsEmpty() {
+			continue
+		}
+		notification.Text = notification.buildText()
+
+		if err := webhook.Notify(ctx, notification); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func addDetectionResults(results *[]*DetectionResult, group *dashboardexecute.DetectionBenchmarkDisplay) {
+	if group == nil {
+		return
+	}
+	for _, d := range group.DetectionRuns {
+		if d.Data == nil || len(d.Data.Rows) == 0 {
+			continue
+		}
+		res := &DetectionResult{
+			Name:     d.Name,
+			Title:    d.Title,
+			RowCount: len(d.Data.Rows),
+			Rows:     d.Data.Rows,
+		}
+		if d.Resource != nil {
+			res.Severity = typeHelpers.SafeString(d.Resource.Severity)
+		}
+		*results = append(*results, res)
+	}
+	for _, child := range group.Groups {
+		addDetectionResults(results, child)
+	}
+}
@@ -0,0 +1,122 @@
+package notifier
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/utils"
+	"github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/controlstatus"
+)
+
+const (
+	RunTypeCheck     = "check"
+	RunTypeDetection = "detection"
+)
+
+// the maximum number of controls or detections listed in the notification text
+const maxTextItems = 10
+
+// Notification is the data passed to the notifier payload template
+type Notification struct {
+	Notifier  string                       `json:"notifier"`
+	Target    string                       `json:"target"`
+	RunType   string                       `json:"run_type"`
+	StartTime time.Time                    `json:"start_time"`
+	EndTime   time.Time                    `json:"end_time"`
+	Summary   *controlstatus.StatusSummary `json:"summary,omitempty"`
+	// controls with alarms (benchmark and control runs only)
+	Controls []*ControlResult `json:"controls,omitempty"`
+	// detections which returned rows (detection runs only)
+	Detections []*DetectionResult `json:"detections,omitempty"`
+	// a human readable summary of the notification
+	Text string `json:"text"`
+}
+
+// ControlResult is a control which has alarms
+type ControlResult struct {
+	Name     string      `json:"name"`
+	Title    string      `json:"title,omitempty"`
+	Severity string      `json:"severity,omitempty"`
+	Alarm    int         `json:"alarm"`
+	Alarms   []*AlarmRow `json:"alarms,omitempty"`
+}
+
+// AlarmRow is a single alarm result of a control
+type AlarmRow struct {
+	Resource string `json:"resource"`
+	Reason   string `json:"reason"`
+}
+
+// DetectionResult is a detection which returned rows
+type DetectionResult struct {
+	Name     string           `json:"name"`
+	Title    string           `json:"title,omitempty"`
+	Severity string           `json:"severity,omitempty"`
+	RowCount int              `json:"row_count"`
+	Rows     []map[string]any `json:"rows,omitempty"`
+}
+
+// IsEmpty returns whether there is nothing to notify
+func (n *Notification) IsEmpty() bool {
+	return len(n.Controls) == 0 && len(n.Detections) == 0
+}
+
+func (n *Notification) buildText() string {
+	var b strings.Builder
+	switch n.RunType {
+	case RunTypeCheck:
+		alarms := 0
+		for _, c := range n.Controls {
+			alarms += c.Alarm
+		}
+		fmt.Fprintf(&b, "Powerpipe run of %s: %d %s in alarm (%d %s)", n.Target, len(n.Controls), utils.Pluralize("control", len(n.Controls)), alarms, utils.Pluralize("alarm", alarms))
+		for i, c := range n.Controls {
+			if i == maxTextItems {
+				fmt.Fprintf(&b, "\n• and %d more", len(n.Controls)-maxTextItems)
+				break
+			}
+			fmt.Fprintf(&b, "\n• %s%s: %d %s", displayName(c.Name, c.Title), severitySuffix(c.Severity), c.Alarm, utils.Pluralize("alarm", c.Alarm))
+		}
+	case RunTypeDetection:
+		fmt.Fprintf(&b, "Powerpipe run of %s: %d %s returned rows", n.Target, len(n.Detections), utils.Pluralize("detection", len(n.Detections)))
+		for i, d := range n.Detections {
+			if i == maxTextItems {
+				fmt.Fprintf(&b, "\n• and %d more", len(n.Detections)-maxTextItems)
+				break
+			}
+			fmt.Fprintf(&b, "\n• %s%s: %d %s", displayName(d.Name, d.Title), severitySuffix(d.Severity), d.RowCount, utils.Pluralize("row", d.RowCount))
+		}
+	}
+	return b.String()
+}
+
+// severityMeetsThreshold returns whether the severity is at least the given minimum severity
+// an empty threshold matches all severities
+func severityMeetsThreshold(severity, minSeverity string) bool {
+	if minSeverity == "" {
+		return true
+	}
+	return severityRank(severity) >= severityRank(minSeverity)
+}
+
+// severityRank returns the position of the severity in the list of severities (unknown severities rank as 'none')
+func severityRank(severity string) int {
+	return max(slices.Index(constants.Severities, strings.ToLower(severity)), 0)
+}
+
+func displayName(name, title string) string {
+	if title != "" {
+		return title
+	}
+	return name
+}
+
+func severitySuffix(severity string) string {
+	if severity == "" || severity == "none" {
+		return ""
+	}
+	return fmt.Sprintf(" [%s]", severity)
+}
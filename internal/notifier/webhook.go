@@ -0,0 +1,127 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/turbot/powerpipe/internal/controldisplay"
+	"github.com/turbot/powerpipe/internal/powerpipeconfig"
+)
+
+// the default payload templates for each notifier format
+var defaultTemplates = map[string]string{
+	powerpipeconfig.NotifierFormatJson:  `{{ toJson . }}`,
+	powerpipeconfig.NotifierFormatSlack: `{"text": {{ toJson .Text }}}`,
+}
+
+// Webhook sends notifications to the url of a notifier
+type Webhook struct {
+	config   *powerpipeconfig.Notifier
+	template *template.Template
+	client   *http.Client
+}
+
+// NewWebhook creates a Webhook for the notifier, parsing the payload template
+func NewWebhook(config *powerpipeconfig.Notifier) (*Webhook, error) {
+	templateText := config.Template
+	if templateText == "" {
+		templateText = defaultTemplates[config.Format]
+	}
+	t, err := template.New(config.Name).Funcs(controldisplay.TemplateFuncs()).Parse(templateText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template for notifier '%s': %w", config.Name, err)
+	}
+	return &Webhook{
+		config:   config,
+		template: t,
+		client:   &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// GetWebhooks creates webhooks for the named notifiers in the config
+func GetWebhooks(config *powerpipeconfig.PowerpipeConfig, names []string) ([]*Webhook, error) {
+	var res []*Webhook
+	for _, name := range names {
+		notifierConfig, ok := config.Notifiers[name]
+		if !ok {
+			return nil, fmt.Errorf("notifier '%s' is not defined in the config", name)
+		}
+		webhook, err := NewWebhook(notifierConfig)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, webhook)
+	}
+	return res, nil
+}
+
+func (w *Webhook) Name() string {
+	return w.config.Name
+}
+
+// Notify renders the notification payload and posts it to the webhook url
+// failed requests are retried, up to the configured retry count
+func (w *Webhook) Notify(ctx context.Context, notification *Notification) error {
+	var payload bytes.Buffer
+	if err := w.template.Execute(&payload, notification); err != nil {
+		return fmt.Errorf("failed to render payload for notifier '%s': %w", w.config.Name, err)
+	}
+
+	var err error
+	for attempt := 0; attempt <= w.config.Retries; attempt++ {
+		if attempt > 0 {
+			// back off exponentially between retries
+			backoff := w.config.RetryInterval * time.Duration(1<<(attempt-1))
+			slog.Debug("retrying notification", "notifier", w.config.Name, "attempt", attempt, "backoff", backoff, "error", err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+		}
+
+		var retryable bool
+		retryable, err = w.post(ctx, payload.Bytes())
+		if err == nil || !retryable {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("notifier '%s' failed: %w", w.config.Name, err)
+	}
+	return nil
+}
+
+// post sends the payload, returning an error and whether the request may be retried
+func (w *Webhook) post(ctx context.Context, payload []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.Url, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		// network errors and timeouts may be retried
+		return true, err
+	}
+	defer resp.Body.Close()
+	// read (and discard) the body so the connection may be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	// retry server errors and rate limiting, but not other client errors
+	retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retryable, fmt.Errorf("unexpected response status %s", resp.Status)
+}
@@ -0,0 +1,194 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/turbot/powerpipe/internal/controlexecute"
+	"github.com/turbot/powerpipe/internal/controlstatus"
+	"github.com/turbot/powerpipe/internal/powerpipeconfig"
+)
+
+// webhookStub is a local HTTP server which records the requests it receives,
+// responding with the given status codes in turn (and 200 once they are exhausted)
+type webhookStub struct {
+	server   *httptest.Server
+	statuses []int
+
+	mut      sync.Mutex
+	requests []*http.Request
+	bodies   []string
+}
+
+func newWebhookStub(t *testing.T, statuses ...int) *webhookStub {
+	s := &webhookStub{statuses: statuses}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		s.mut.Lock()
+		defer s.mut.Unlock()
+		s.requests = append(s.requests, r)
+		s.bodies = append(s.bodies, string(body))
+		status := http.StatusOK
+		if idx := len(s.requests) - 1; idx < len(s.statuses) {
+			status = s.statuses[idx]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func (s *webhookStub) notifierConfig(format, minSeverity string) *powerpipeconfig.Notifier {
+	return &powerpipeconfig.Notifier{
+		Name:          "test",
+		Url:           s.server.URL,
+		Format:        format,
+		Headers:       map[string]string{"X-Token": "secret"},
+		MinSeverity:   minSeverity,
+		Retries:       2,
+		RetryInterval: time.Millisecond,
+		Timeout:       time.Second,
+	}
+}
+
+func testNotification() *Notification {
+	n := &Notification{
+		Notifier: "test",
+		Target:   "mod.benchmark.b1",
+		RunType:  RunTypeCheck,
+		Controls: []*ControlResult{{Name: "mod.control.c1", Title: "Control 1", Severity: "high", Alarm: 2}},
+	}
+	n.Text = n.buildText()
+	return n
+}
+
+func TestWebhookNotifyRetries(t *testing.T) {
+	stub := newWebhookStub(t, http.StatusInternalServerError, http.StatusTooManyRequests)
+	webhook, err := NewWebhook(stub.notifierConfig(powerpipeconfig.NotifierFormatJson, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := webhook.Notify(context.Background(), testNotification()); err != nil {
+		t.Fatalf("expected notification to succeed after retrying, got: %v", err)
+	}
+	if len(stub.requests) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(stub.requests))
+	}
+	if got := stub.requests[0].Header.Get("X-Token"); got != "secret" {
+		t.Errorf("expected configured header to be sent, got %q", got)
+	}
+
+	var payload Notification
+	if err := json.Unmarshal([]byte(stub.bodies[2]), &payload); err != nil {
+		t.Fatalf("expected a json payload: %v\n%s", err, stub.bodies[2])
+	}
+	if payload.Target != "mod.benchmark.b1" || len(payload.Controls) != 1 || payload.Controls[0].Alarm != 2 {
+		t.Errorf("unexpected payload: %s", stub.bodies[2])
+	}
+}
+
+func TestWebhookNotifyDoesNotRetryClientErrors(t *testing.T) {
+	stub := newWebhookStub(t, http.StatusBadRequest)
+	webhook, err := NewWebhook(stub.notifierConfig(powerpipeconfig.NotifierFormatJson, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := webhook.Notify(context.Background(), testNotification()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(stub.requests) != 1 {
+		t.Errorf("expected 1 request, got %d", len(stub.requests))
+	}
+}
+
+func TestWebhookCustomTemplate(t *testing.T) {
+	stub := newWebhookStub(t)
+	config := stub.notifierConfig(powerpipeconfig.NotifierFormatJson, "")
+	config.Template = `{"target": {{ toJson .Target }}, "first": {{ (index .Controls 0).Name | upper | quote }}}`
+	webhook, err := NewWebhook(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := webhook.Notify(context.Background(), testNotification()); err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"target": "mod.benchmark.b1", "first": "MOD.CONTROL.C1"}`
+	if stub.bodies[0] != expected {
+		t.Errorf("expected payload %s, got %s", expected, stub.bodies[0])
+	}
+}
+
+func TestNotifierControlHooks(t *testing.T) {
+	highStub := newWebhookStub(t)
+	criticalStub := newWebhookStub(t)
+	high, err := NewWebhook(highStub.notifierConfig(powerpipeconfig.NotifierFormatSlack, "high"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	critical, err := NewWebhook(criticalStub.notifierConfig(powerpipeconfig.NotifierFormatSlack, "critical"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hooks := NewNotifierControlHooks("mod.benchmark.b1", []*Webhook{high, critical})
+	ctx := context.Background()
+	progress := controlstatus.NewControlProgress(3)
+	hooks.OnStart(ctx, progress)
+	for _, run := range []*controlexecute.ControlRun{
+		{
+			FullName: "mod.control.high",
+			Title:    "High control",
+			Severity: "high",
+			Summary:  &controlstatus.StatusSummary{Alarm: 1, Ok: 1},
+			Rows: controlexecute.ResultRows{
+				{Status: "alarm", Resource: "r1", Reason: "r1 is bad"},
+				{Status: "ok", Resource: "r2", Reason: "r2 is fine"},
+			},
+		},
+		{
+			FullName: "mod.control.low",
+			Severity: "low",
+			Summary:  &controlstatus.StatusSummary{Alarm: 3},
+		},
+		{
+			FullName: "mod.control.ok",
+			Severity: "critical",
+			Summary:  &controlstatus.StatusSummary{Ok: 1},
+		},
+	} {
+		hooks.OnControlComplete(ctx, run, progress)
+	}
+	hooks.OnComplete(ctx, progress)
+
+	// the critical notifier has no controls at or above its threshold
+	if len(criticalStub.requests) != 0 {
+		t.Errorf("expected no notification for the critical notifier, got %d", len(criticalStub.requests))
+	}
+	if len(highStub.requests) != 1 {
+		t.Fatalf("expected 1 notification for the high notifier, got %d", len(highStub.requests))
+	}
+
+	var payload struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal([]byte(highStub.bodies[0]), &payload); err != nil {
+		t.Fatalf("expected a slack payload: %v\n%s", err, highStub.bodies[0])
+	}
+	if !strings.Contains(payload.Text, "1 control in alarm (1 alarm)") || !strings.Contains(payload.Text, "High control [high]: 1 alarm") {
+		t.Errorf("unexpected notification text: %s", payload.Text)
+	}
+	if strings.Contains(payload.Text, "mod.control.low") {
+		t.Errorf("expected the low severity control to be filtered: %s", payload.Text)
+	}
+}
@@ -0,0 +1,137 @@
+package powerpipeconfig
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/turbot/pipe-fittings/v2/funcs"
+	"github.com/turbot/pipe-fittings/v2/hclhelpers"
+	"github.com/turbot/powerpipe/internal/constants"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	NotifierFormatJson  = "json"
+	NotifierFormatSlack = "slack"
+)
+
+const (
+	defaultNotifierRetries       = 3
+	defaultNotifierRetryInterval = time.Second
+	defaultNotifierTimeout       = 10 * time.Second
+)
+
+// Notifier is a webhook which is notified when a benchmark run has alarms,
+// or a detection run returns rows
+type Notifier struct {
+	Name string
+	Url  string
+	// the payload format - either json or slack
+	// ignored if a Template is specified
+	Format string
+	// an optional template used to build the payload
+	Template string
+	Headers  map[string]string
+	// only controls and detections with at least this severity trigger a notification
+	// if not set, all controls and detections trigger notifications
+	MinSeverity   string
+	Retries       int
+	RetryInterval time.Duration
+	Timeout       time.Duration
+
+	DeclRange hcl.Range
+}
+
+// the HCL representation of a notifier block
+type notifierBlock struct {
+	Url           string            `hcl:"url"`
+	Format        *string           `hcl:"format,optional"`
+	Template      *string           `hcl:"template,optional"`
+	Headers       map[string]string `hcl:"headers,optional"`
+	MinSeverity   *string           `hcl:"min_severity,optional"`
+	Retries       *int              `hcl:"retries,optional"`
+	RetryInterval *string           `hcl:"retry_interval,optional"`
+	Timeout       *string           `hcl:"timeout,optional"`
+}
+
+func decodeNotifier(configPath string, block *hcl.Block) (*Notifier, hcl.Diagnostics) {
+	if len(block.Labels) != 1 {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("invalid notifier block - expected 1 label, found %d", len(block.Labels)),
+			Subject:  &block.DefRange,
+		}}
+	}
+
+	// build an eval context just containing functions
+	evalCtx := &hcl.EvalContext{
+		Functions: funcs.ContextFunctions(configPath),
+		Variables: make(map[string]cty.Value),
+	}
+
+	var decoded notifierBlock
+	diags := gohcl.DecodeBody(block.Body, evalCtx, &decoded)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	notifier := &Notifier{
+		Name:          block.Labels[0],
+		Url:           decoded.Url,
+		Format:        NotifierFormatJson,
+		Headers:       decoded.Headers,
+		Retries:       defaultNotifierRetries,
+		RetryInterval: defaultNotifierRetryInterval,
+		Timeout:       defaultNotifierTimeout,
+		DeclRange:     hclhelpers.BlockRange(block),
+	}
+	if decoded.Format != nil {
+		notifier.Format = *decoded.Format
+	}
+	if decoded.Template != nil {
+		notifier.Template = *decoded.Template
+	}
+	if decoded.MinSeverity != nil {
+		notifier.MinSeverity = strings.ToLower(*decoded.MinSeverity)
+	}
+	if decoded.Retries != nil {
+		notifier.Retries = *decoded.Retries
+	}
+
+	var err error
+	if decoded.RetryInterval != nil {
+		if notifier.RetryInterval, err = time.ParseDuration(*decoded.RetryInterval); err != nil {
+			diags = append(diags, notifierDiag(block, "invalid retry_interval: %s", err.Error()))
+		}
+	}
+	if decoded.Timeout != nil {
+		if notifier.Timeout, err = time.ParseDuration(*decoded.Timeout); err != nil {
+			diags = append(diags, notifierDiag(block, "invalid timeout: %s", err.Error()))
+		}
+	}
+	if !slices.Contains([]string{NotifierFormatJson, NotifierFormatSlack}, notifier.Format) {
+		diags = append(diags, notifierDiag(block, "invalid format '%s' - must be one of: %s, %s", notifier.Format, NotifierFormatJson, NotifierFormatSlack))
+	}
+	if notifier.MinSeverity != "" && !slices.Contains(constants.Severities, notifier.MinSeverity) {
+		diags = append(diags, notifierDiag(block, "invalid min_severity '%s' - must be one of: %s", notifier.MinSeverity, strings.Join(constants.Severities, ", ")))
+	}
+	if notifier.Retries < 0 {
+		diags = append(diags, notifierDiag(block, "retries must not be negative"))
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return notifier, diags
+}
+
+func notifierDiag(block *hcl.Block, format string, args ...any) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  fmt.Sprintf("notifier '%s': %s", block.Labels[0], fmt.Sprintf(format, args...)),
+		Subject:  &block.DefRange,
+	}
+}
@@ -14,6 +14,10 @@ type PowerpipeConfig struct {
 	ConfigPaths []string
 
 	PipelingConnections map[string]connection.PipelingConnection
+	// schedules run by powerpipe server, keyed by name
+	Schedules map[string]*Schedule
+	// webhook notifiers, keyed by name
+	Notifiers map[string]*Notifier
 
 	// cache the connection strings for cloud workspaces (is this ok???
 	cloudConnectionStrings map[string]string
@@ -32,6 +36,8 @@ func NewPowerpipeConfig() *PowerpipeConfig {
 
 	return &PowerpipeConfig{
 		PipelingConnections:       defaultPipelingConnections,
+		Schedules:                 make(map[string]*Schedule),
+		Notifiers:                 make(map[string]*Notifier),
 		cloudConnectionStringLock: &sync.RWMutex{},
 
 		cloudConnectionStrings: make(map[string]string),
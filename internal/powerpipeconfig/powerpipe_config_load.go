@@ -9,11 +9,28 @@ import (
 	"github.com/turbot/pipe-fittings/v2/filepaths"
 	"github.com/turbot/pipe-fittings/v2/parse"
 	"github.com/turbot/pipe-fittings/v2/schema"
+	"github.com/turbot/powerpipe/internal/constants"
 	"log/slog"
+	"slices"
 )
 
 var GlobalConfig *PowerpipeConfig
 
+// powerpipeConfigBlockSchema adds the powerpipe specific config blocks to the pipe-fittings config schema
+var powerpipeConfigBlockSchema = &hcl.BodySchema{
+	Attributes: parse.PowerpipeConfigBlockSchema.Attributes,
+	Blocks: append(slices.Clone(parse.PowerpipeConfigBlockSchema.Blocks),
+		hcl.BlockHeaderSchema{
+			Type:       constants.BlockTypeSchedule,
+			LabelNames: []string{"name"},
+		},
+		hcl.BlockHeaderSchema{
+			Type:       constants.BlockTypeNotifier,
+			LabelNames: []string{"name"},
+		},
+	),
+}
+
 type loadConfigOptions struct {
 	include []string
 }
@@ -93,7 +110,7 @@ func (c *PowerpipeConfig) loadPowerpipeConfigBlocks(configPath string, opts *loa
 	}
 
 	// do a partial decode
-	content, diags := body.Content(parse.PowerpipeConfigBlockSchema)
+	content, diags := body.Content(powerpipeConfigBlockSchema)
 	if diags.HasErrors() {
 		return diags
 	}
@@ -109,6 +126,22 @@ func (c *PowerpipeConfig) loadPowerpipeConfigBlocks(configPath string, opts *loa
 				continue
 			}
 			c.PipelingConnections[conn.Name()] = conn
+		case constants.BlockTypeSchedule:
+			schedule, moreDiags := decodeSchedule(configPath, block)
+			if len(moreDiags) > 0 {
+				diags = append(diags, moreDiags...)
+				slog.Debug("failed to decode schedule block")
+				continue
+			}
+			c.Schedules[schedule.Name] = schedule
+		case constants.BlockTypeNotifier:
+			notifier, moreDiags := decodeNotifier(configPath, block)
+			if len(moreDiags) > 0 {
+				diags = append(diags, moreDiags...)
+				slog.Debug("failed to decode notifier block")
+				continue
+			}
+			c.Notifiers[notifier.Name] = notifier
 		}
 	}
 
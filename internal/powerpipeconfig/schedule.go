@@ -0,0 +1,78 @@
+package powerpipeconfig
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/turbot/pipe-fittings/v2/funcs"
+	"github.com/turbot/pipe-fittings/v2/hclhelpers"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Schedule is a benchmark, dashboard or detection which is run periodically by powerpipe server,
+// with the resulting snapshot written to OutputDir
+type Schedule struct {
+	Name string
+	// the full name of the benchmark, dashboard or detection to run
+	Target string
+	// a standard 5 field cron expression (or a descriptor such as @daily)
+	Cron string
+	// input values to pass to the execution, keyed by input name
+	Inputs map[string]any
+	// the directory snapshots are written to
+	OutputDir string
+
+	DeclRange hcl.Range
+}
+
+// the HCL representation of a schedule block
+type scheduleBlock struct {
+	Target    string    `hcl:"target"`
+	Cron      string    `hcl:"cron"`
+	Inputs    cty.Value `hcl:"inputs,optional"`
+	OutputDir string    `hcl:"output_dir"`
+}
+
+func decodeSchedule(configPath string, block *hcl.Block) (*Schedule, hcl.Diagnostics) {
+	if len(block.Labels) != 1 {
+		return nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("invalid schedule block - expected 1 label, found %d", len(block.Labels)),
+			Subject:  &block.DefRange,
+		}}
+	}
+
+	// build an eval context just containing functions
+	evalCtx := &hcl.EvalContext{
+		Functions: funcs.ContextFunctions(configPath),
+		Variables: make(map[string]cty.Value),
+	}
+
+	var decoded scheduleBlock
+	diags := gohcl.DecodeBody(block.Body, evalCtx, &decoded)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	schedule := &Schedule{
+		Name:      block.Labels[0],
+		Target:    decoded.Target,
+		Cron:      decoded.Cron,
+		OutputDir: decoded.OutputDir,
+		DeclRange: hclhelpers.BlockRange(block),
+	}
+
+	if !decoded.Inputs.IsNull() {
+		inputs, err := hclhelpers.CtyToGoMapInterface(decoded.Inputs)
+		if err != nil {
+			return nil, hcl.Diagnostics{{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("invalid inputs for schedule '%s': %s", schedule.Name, err.Error()),
+				Subject:  &block.DefRange,
+			}}
+		}
+		schedule.Inputs = inputs
+	}
+	return schedule, diags
+}
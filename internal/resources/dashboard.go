@@ -32,6 +32,8 @@ type Dashboard struct {
 	Inputs  []*DashboardInput `cty:"inputs" json:"inputs,omitempty"`
 	UrlPath string            `cty:"url_path"  json:"url_path,omitempty"`
 	Base    *Dashboard        `hcl:"base" json:"-"`
+	// if set, overrides the server query cache ttl (in seconds) for all panels of the dashboard - 0 disables caching
+	CacheTtl *int `cty:"cache_ttl" hcl:"cache_ttl" json:"cache_ttl,omitempty"`
 	// store children in a way which can be serialised via cty
 	ChildNames []string `cty:"children" json:"children,omitempty"`
 	// map of all inputs in our resource tree
@@ -179,10 +181,19 @@ func (d *Dashboard) Diff(other *Dashboard) *modconfig.ModTreeItemDiffs {
 		res.AddPropertyDiff("Documentation")
 	}
 
+	if !utils.SafeIntEqual(d.CacheTtl, other.CacheTtl) {
+		res.AddPropertyDiff("CacheTtl")
+	}
+
 	res.PopulateChildDiffs(d, other)
 	return res
 }
 
+// GetCacheTtl implements CacheTtlProvider
+func (d *Dashboard) GetCacheTtl() *int {
+	return d.CacheTtl
+}
+
 func (d *Dashboard) AddChild(child modconfig.ModTreeItem) hcl.Diagnostics {
 	var diags hcl.Diagnostics
 	d.ModTreeItemImpl.AddChild(child)
@@ -395,6 +406,10 @@ func (d *Dashboard) SetBaseProperties() hcl.Diagnostics {
 		d.Width = d.Base.Width
 	}
 
+	if d.CacheTtl == nil {
+		d.CacheTtl = d.Base.CacheTtl
+	}
+
 	if len(d.GetChildren()) == 0 {
 		d.Children = d.Base.Children
 		d.ChildNames = d.Base.ChildNames
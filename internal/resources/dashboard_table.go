@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"fmt"
+
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/pipe-fittings/v2/constants"
 	"github.com/turbot/pipe-fittings/v2/cty_helpers"
@@ -23,6 +25,8 @@ type DashboardTable struct {
 	ColumnList DashboardTableColumnList         `cty:"column_list" hcl:"column,block" json:"columns,omitempty"`
 	Columns    map[string]*DashboardTableColumn `cty:"columns" snapshot:"columns"`
 	Base       *DashboardTable                  `hcl:"base" json:"-"`
+	// if set, the server sends the table rows in pages of this size, rather than all at once
+	PageSize *int `cty:"page_size" hcl:"page_size" snapshot:"page_size" json:"page_size,omitempty"`
 }
 
 func NewDashboardTable(block *hcl.Block, mod *modconfig.Mod, shortName string) modconfig.HclResource {
@@ -74,6 +78,13 @@ func (t *DashboardTable) Equals(other *DashboardTable) bool {
 // OnDecoded implements HclResource
 func (t *DashboardTable) OnDecoded(block *hcl.Block, resourceMapProvider modconfig.ModResourcesProvider) hcl.Diagnostics {
 	t.SetBaseProperties()
+	if t.PageSize != nil && *t.PageSize <= 0 {
+		return hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Table '%s' page_size must be greater than zero", t.Name()),
+			Subject:  &t.DeclRange,
+		}}
+	}
 	// populate columns map
 	if len(t.ColumnList) > 0 {
 		t.Columns = make(map[string]*DashboardTableColumn, len(t.ColumnList))
@@ -94,6 +105,10 @@ func (t *DashboardTable) Diff(other *DashboardTable) *modconfig.ModTreeItemDiffs
 		res.AddPropertyDiff("Type")
 	}
 
+	if !utils.SafeIntEqual(t.PageSize, other.PageSize) {
+		res.AddPropertyDiff("PageSize")
+	}
+
 	if len(t.ColumnList) != len(other.ColumnList) {
 		res.AddPropertyDiff("Columns")
 	} else {
@@ -138,6 +153,10 @@ func (t *DashboardTable) SetBaseProperties() {
 		t.Display = t.Base.Display
 	}
 
+	if t.PageSize == nil {
+		t.PageSize = t.Base.PageSize
+	}
+
 	if t.ColumnList == nil {
 		t.ColumnList = t.Base.ColumnList
 	} else {
@@ -151,6 +170,7 @@ func (t *DashboardTable) GetShowData() *printers.RowData {
 		printers.NewFieldValue("Width", t.Width),
 		printers.NewFieldValue("Type", t.Type),
 		printers.NewFieldValue("Display", t.Display),
+		printers.NewFieldValue("PageSize", t.PageSize),
 		printers.NewFieldValue("Columns", t.ColumnList),
 	)
 	// merge fields from base, putting base fields first
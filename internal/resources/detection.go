@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"slices"
+
 	"github.com/hashicorp/hcl/v2"
 	"github.com/turbot/pipe-fittings/v2/cty_helpers"
 	"github.com/turbot/pipe-fittings/v2/modconfig"
@@ -19,6 +21,8 @@ type Detection struct {
 	Remain         hcl.Body `hcl:",remain" json:"-"`
 	Severity       *string  `cty:"severity" hcl:"severity"  snapshot:"severity" json:"severity,omitempty"`
 	DisplayColumns []string `cty:"display_columns" hcl:"display_columns,optional" json:"display_columns,omitempty" snapshot:"display_columns"`
+	// the columns used to compute the fingerprint of a result row (all columns if not set)
+	FingerprintColumns []string `cty:"fingerprint_columns" hcl:"fingerprint_columns,optional" json:"fingerprint_columns,omitempty" snapshot:"fingerprint_columns"`
 
 	Base *Detection `hcl:"base" json:"-"`
 }
@@ -52,6 +56,10 @@ func (t *Detection) Diff(other *Detection) *modconfig.ModTreeItemDiffs {
 		res.AddPropertyDiff("Type")
 	}
 
+	if !slices.Equal(t.FingerprintColumns, other.FingerprintColumns) {
+		res.AddPropertyDiff("FingerprintColumns")
+	}
+
 	res.PopulateChildDiffs(t, other)
 	res.Merge(t.QueryProviderImpl.Diff(other))
 	res.Merge(dashboardLeafNodeDiff(t, other))
@@ -85,6 +93,10 @@ func (t *Detection) SetBaseProperties() {
 	if t.Display == nil {
 		t.Display = t.Base.Display
 	}
+
+	if t.FingerprintColumns == nil {
+		t.FingerprintColumns = t.Base.FingerprintColumns
+	}
 }
 
 // GetShowData implements printers.Showable
@@ -93,6 +105,7 @@ func (t *Detection) GetShowData() *printers.RowData {
 		printers.NewFieldValue("Width", t.Width),
 		printers.NewFieldValue("Type", t.Type),
 		printers.NewFieldValue("Display", t.Display),
+		printers.NewFieldValue("FingerprintColumns", t.FingerprintColumns),
 	)
 	// merge fields from base, putting base fields first
 	res.Merge(t.QueryProviderImpl.GetShowData())
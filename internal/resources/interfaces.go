@@ -50,6 +50,11 @@ type QueryProvider interface {
 	ArgsInheritedFromBase() bool
 }
 
+// CacheTtlProvider is implemented by resources which may override the query cache ttl (Dashboard and all QueryProviders)
+type CacheTtlProvider interface {
+	GetCacheTtl() *int
+}
+
 // DashboardLeafNode must be implemented by resources may be a leaf node in the dashboard execution tree
 type DashboardLeafNode interface {
 	modconfig.ModTreeItem
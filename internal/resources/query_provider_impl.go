@@ -24,6 +24,8 @@ type QueryProviderImpl struct {
 	Args      *QueryArgs            `cty:"args" json:"args,omitempty"`
 	Params    []*modconfig.ParamDef `cty:"params" json:"params,omitempty"`
 	QueryName *string               `json:"query,omitempty"`
+	// if set, overrides the server query cache ttl (in seconds) for this resource - 0 disables caching
+	CacheTtl *int `cty:"cache_ttl" hcl:"cache_ttl" json:"cache_ttl,omitempty"`
 
 	disableCtySerialise bool
 	// flags to indicate if params and args were inherited from base resource
@@ -60,6 +62,11 @@ func (q *QueryProviderImpl) GetQuery() *Query {
 	return q.Query
 }
 
+// GetCacheTtl implements CacheTtlProvider
+func (q *QueryProviderImpl) GetCacheTtl() *int {
+	return q.CacheTtl
+}
+
 // SetArgs implements QueryProvider
 func (q *QueryProviderImpl) SetArgs(args *QueryArgs) {
 	q.Args = args
@@ -181,6 +188,9 @@ func (q *QueryProviderImpl) SetBaseProperties() {
 		q.Params = q.getBaseImpl().Params
 		q.paramsInheritedFromBase = true
 	}
+	if q.CacheTtl == nil {
+		q.CacheTtl = q.getBaseImpl().CacheTtl
+	}
 }
 
 func (q *QueryProviderImpl) getBaseImpl() *QueryProviderImpl {
@@ -223,6 +233,10 @@ func (q *QueryProviderImpl) Diff(other QueryProvider) *modconfig.ModTreeItemDiff
 		d.AddPropertyDiff("SQL")
 	}
 
+	if !utils.SafeIntEqual(q.CacheTtl, other.GetQueryProviderImpl().CacheTtl) {
+		d.AddPropertyDiff("CacheTtl")
+	}
+
 	// args
 	if lArgs := q.GetArgs(); lArgs == nil {
 		if other.GetArgs() != nil {
@@ -0,0 +1,176 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronDescriptors maps the supported predefined schedules to their cron expression
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// CronExpression is a parsed standard 5 field cron expression:
+// minute, hour, day of month, month and day of week
+type CronExpression struct {
+	minutes     map[int]bool
+	hours       map[int]bool
+	daysOfMonth map[int]bool
+	months      map[int]bool
+	daysOfWeek  map[int]bool
+	// if both day of month and day of week are restricted, a time matches if EITHER matches
+	domRestricted bool
+	dowRestricted bool
+}
+
+// ParseCron parses a 5 field cron expression, or one of the descriptors @yearly, @monthly, @weekly, @daily or @hourly
+func ParseCron(expr string) (*CronExpression, error) {
+	expr = strings.TrimSpace(expr)
+	if descriptor, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = descriptor
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression '%s' - expected 5 fields, found %d", expr, len(fields))
+	}
+
+	var res = &CronExpression{
+		domRestricted: fields[2] != "*",
+		dowRestricted: fields[4] != "*",
+	}
+	var err error
+	if res.minutes, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid minute field in cron expression '%s': %w", expr, err)
+	}
+	if res.hours, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid hour field in cron expression '%s': %w", expr, err)
+	}
+	if res.daysOfMonth, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("invalid day of month field in cron expression '%s': %w", expr, err)
+	}
+	if res.months, err = parseCronField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("invalid month field in cron expression '%s': %w", expr, err)
+	}
+	// allow 7 as an alias for sunday
+	if res.daysOfWeek, err = parseCronField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, fmt.Errorf("invalid day of week field in cron expression '%s': %w", expr, err)
+	}
+	if res.daysOfWeek[7] {
+		res.daysOfWeek[0] = true
+	}
+	return res, nil
+}
+
+// Next returns the first time after t which matches the expression
+// NOTE: the result is truncated to the minute
+func (c *CronExpression) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// if nothing matches within 5 years, the expression can never match (e.g. 30 Feb)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if !c.months[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.hours[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c *CronExpression) matchesDay(t time.Time) bool {
+	domMatch := c.daysOfMonth[t.Day()]
+	dowMatch := c.daysOfWeek[int(t.Weekday())]
+	if c.domRestricted && c.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// parseCronField parses a comma separated list of values, ranges (a-b) and steps (*/n, a-b/n)
+func parseCronField(field string, minValue, maxValue int, names map[string]int) (map[int]bool, error) {
+	res := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if rangePart, stepPart, ok := strings.Cut(part, "/"); ok {
+			s, err := strconv.Atoi(stepPart)
+			if err != nil || s <= 0 {
+				return nil, fmt.Errorf("invalid step '%s'", stepPart)
+			}
+			step = s
+			part = rangePart
+		}
+
+		var start, end int
+		switch {
+		case part == "*":
+			start, end = minValue, maxValue
+		case strings.Contains(part, "-"):
+			startPart, endPart, _ := strings.Cut(part, "-")
+			var err error
+			if start, err = parseCronValue(startPart, names); err != nil {
+				return nil, err
+			}
+			if end, err = parseCronValue(endPart, names); err != nil {
+				return nil, err
+			}
+		default:
+			v, err := parseCronValue(part, names)
+			if err != nil {
+				return nil, err
+			}
+			start, end = v, v
+			// a single value with a step runs from the value to the max (e.g. 5/15)
+			if step > 1 {
+				end = maxValue
+			}
+		}
+
+		if start < minValue || end > maxValue || start > end {
+			return nil, fmt.Errorf("value '%s' out of range %d-%d", part, minValue, maxValue)
+		}
+		for v := start; v <= end; v += step {
+			res[v] = true
+		}
+	}
+	return res, nil
+}
+
+func parseCronValue(s string, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value '%s'", s)
+	}
+	return v, nil
+}
@@ -0,0 +1,59 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// a Wednesday
+	from := time.Date(2024, 5, 15, 10, 30, 20, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		want time.Time
+	}{
+		{"every minute", "* * * * *", time.Date(2024, 5, 15, 10, 31, 0, 0, time.UTC)},
+		{"step", "*/15 * * * *", time.Date(2024, 5, 15, 10, 45, 0, 0, time.UTC)},
+		{"daily", "@daily", time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC)},
+		{"hour list", "0 9,17 * * *", time.Date(2024, 5, 15, 17, 0, 0, 0, time.UTC)},
+		{"weekdays", "0 2 * * mon-fri", time.Date(2024, 5, 16, 2, 0, 0, 0, time.UTC)},
+		{"sunday as 7", "0 0 * * 7", time.Date(2024, 5, 19, 0, 0, 0, 0, time.UTC)},
+		{"month name", "0 0 1 jan *", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// if both day of month and day of week are restricted, either may match
+		{"dom or dow", "0 0 1 * fri", time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC)},
+		{"leap day", "0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"never", "0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cron, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) error: %v", tt.expr, err)
+			}
+			if got := cron.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * * someday",
+		"@every 5m",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) expected an error", expr)
+		}
+	}
+}
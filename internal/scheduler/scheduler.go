@@ -0,0 +1,228 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/export"
+	"github.com/turbot/pipe-fittings/v2/modconfig"
+	"github.com/turbot/pipe-fittings/v2/schema"
+	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+	"github.com/turbot/powerpipe/internal/dashboardevents"
+	"github.com/turbot/powerpipe/internal/dashboardexecute"
+	"github.com/turbot/powerpipe/internal/dashboardserver"
+	"github.com/turbot/powerpipe/internal/powerpipeconfig"
+	"github.com/turbot/powerpipe/internal/workspace"
+)
+
+// sessionPrefix is the prefix of the execution session ids used for scheduled runs
+// - this is used to identify the dashboard events raised by scheduled runs
+const sessionPrefix = "schedule."
+
+// Scheduler runs the configured schedules in the background of powerpipe server
+// Targets are executed using the shared dashboard executor, so they reuse its database clients
+type Scheduler struct {
+	workspace *workspace.PowerpipeWorkspace
+	schedules []*scheduledTarget
+
+	// channels used to wait for the result of in progress runs, keyed by session id
+	runs    map[string]chan runResult
+	runsMut sync.Mutex
+}
+
+type scheduledTarget struct {
+	*powerpipeconfig.Schedule
+	cron *CronExpression
+}
+
+type runResult struct {
+	snapshot *steampipeconfig.SteampipeSnapshot
+	err      error
+}
+
+// NewScheduler validates the given schedules and creates a Scheduler to run them
+func NewScheduler(w *workspace.PowerpipeWorkspace, schedules map[string]*powerpipeconfig.Schedule) (*Scheduler, error) {
+	s := &Scheduler{
+		workspace: w,
+		runs:      make(map[string]chan runResult),
+	}
+	for _, schedule := range schedules {
+		cron, err := ParseCron(schedule.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("schedule '%s' (%s): %w", schedule.Name, schedule.DeclRange, err)
+		}
+		s.schedules = append(s.schedules, &scheduledTarget{Schedule: schedule, cron: cron})
+	}
+	return s, nil
+}
+
+// Start registers the scheduler dashboard event handler and starts a goroutine for each schedule
+// The schedules are stopped when the context is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	if len(s.schedules) == 0 {
+		return
+	}
+	s.workspace.RegisterDashboardEventHandler(ctx, s.handleDashboardEvent)
+
+	for _, schedule := range s.schedules {
+		next := schedule.cron.Next(time.Now())
+		dashboardserver.OutputMessage(ctx, fmt.Sprintf("Scheduled %s (%s), next run at %s", schedule.Target, schedule.Name, next.Format(time.RFC3339)))
+		go s.runSchedule(ctx, schedule)
+	}
+}
+
+func (s *Scheduler) runSchedule(ctx context.Context, schedule *scheduledTarget) {
+	for {
+		// calculate the next run time AFTER the previous run has completed
+		// - if a run overruns the next scheduled time, that run is skipped
+		next := schedule.cron.Next(time.Now())
+		if next.IsZero() {
+			slog.Warn("schedule will never run", "schedule", schedule.Name, "cron", schedule.Cron)
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		path, err := s.Run(ctx, schedule.Schedule)
+		if err != nil {
+			dashboardserver.OutputError(ctx, fmt.Errorf("scheduled run '%s' failed: %w", schedule.Name, err))
+			continue
+		}
+		dashboardserver.OutputReady(ctx, fmt.Sprintf("Scheduled run '%s' complete, snapshot written to %s", schedule.Name, path))
+	}
+}
+
+// Run executes the schedule target and writes the resulting snapshot to the schedule output dir,
+// returning the snapshot path
+func (s *Scheduler) Run(ctx context.Context, schedule *powerpipeconfig.Schedule) (string, error) {
+	// resolve the target each run, as the workspace may have been reloaded
+	target, err := s.getTarget(schedule.Target)
+	if err != nil {
+		return "", err
+	}
+
+	inputs := dashboardexecute.NewInputValues()
+	for name, v := range schedule.Inputs {
+		// add `input.` to start of name
+		key := name
+		if !strings.HasPrefix(name, "input.") {
+			key = modconfig.BuildModResourceName(schema.BlockTypeInput, name)
+		}
+		inputs.Inputs[key] = v
+	}
+
+	// use a unique session id for each run so events from a previous run can never be mistaken for this one
+	sessionId := fmt.Sprintf("%s%s.%d", sessionPrefix, schedule.Name, time.Now().UnixNano())
+	resultChan := s.addRun(sessionId)
+	defer func() {
+		s.removeRun(sessionId)
+		// remove the execution from the executor
+		dashboardexecute.Executor.CancelExecutionForSession(ctx, sessionId)
+	}()
+
+	// all inputs must be provided by the schedule
+	if err := dashboardexecute.Executor.ExecuteDashboardBatch(ctx, sessionId, target, inputs, s.workspace); err != nil {
+		return "", err
+	}
+
+	var result runResult
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case result = <-resultChan:
+	}
+	if result.err != nil {
+		return "", result.err
+	}
+
+	result.snapshot.FileNameRoot = target.Name()
+	// name the snapshot file after the schedule, so schedules with the same target do not collide
+	return writeSnapshot(result.snapshot, schedule.OutputDir, schedule.Name)
+}
+
+func (s *Scheduler) getTarget(name string) (modconfig.ModTreeItem, error) {
+	parsedName, err := modconfig.ParseResourceName(name)
+	if err != nil {
+		return nil, err
+	}
+	switch parsedName.ItemType {
+	case schema.BlockTypeDashboard, schema.BlockTypeBenchmark, schema.BlockTypeDetection:
+	default:
+		return nil, fmt.Errorf("invalid schedule target '%s' - only dashboards, benchmarks and detections may be scheduled", name)
+	}
+
+	resource, ok := s.workspace.GetResource(parsedName)
+	if !ok {
+		return nil, fmt.Errorf("schedule target '%s' not found in workspace", name)
+	}
+	return resource.(modconfig.ModTreeItem), nil
+}
+
+// handleDashboardEvent passes the completion events of scheduled runs to the waiting run
+func (s *Scheduler) handleDashboardEvent(_ context.Context, event dashboardevents.DashboardEvent) {
+	var sessionId string
+	var result runResult
+	switch e := event.(type) {
+	case *dashboardevents.ExecutionError:
+		sessionId = e.Session
+		result.err = e.Error
+	case *dashboardevents.ExecutionComplete:
+		sessionId = e.Session
+		result.snapshot = dashboardexecute.ExecutionCompleteToSnapshot(e)
+	default:
+		return
+	}
+	if !strings.HasPrefix(sessionId, sessionPrefix) {
+		return
+	}
+
+	s.runsMut.Lock()
+	defer s.runsMut.Unlock()
+	if resultChan, ok := s.runs[sessionId]; ok {
+		// the channel is buffered - do not block the event handler if a result has already been sent
+		select {
+		case resultChan <- result:
+		default:
+		}
+	}
+}
+
+func (s *Scheduler) addRun(sessionId string) chan runResult {
+	s.runsMut.Lock()
+	defer s.runsMut.Unlock()
+
+	resultChan := make(chan runResult, 1)
+	s.runs[sessionId] = resultChan
+	return resultChan
+}
+
+func (s *Scheduler) removeRun(sessionId string) {
+	s.runsMut.Lock()
+	defer s.runsMut.Unlock()
+
+	delete(s.runs, sessionId)
+}
+
+func writeSnapshot(snapshot *steampipeconfig.SteampipeSnapshot, outputDir, fileNameRoot string) (string, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create snapshot directory %s: %w", outputDir, err)
+	}
+	exporter := &export.SnapshotExporter{}
+	path := filepath.Join(outputDir, export.GenerateDefaultExportFileName(fileNameRoot, exporter.FileExtension()))
+	if err := exporter.Export(context.Background(), snapshot, path); err != nil {
+		return "", fmt.Errorf("failed to write snapshot %s: %w", path, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,200 @@
+package serverauth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/turbot/pipe-fittings/v2/modconfig"
+	pfworkspace "github.com/turbot/pipe-fittings/v2/workspace"
+	"github.com/turbot/powerpipe/internal/workspace"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	AuthMethodToken = "token"
+	AuthMethodBasic = "basic"
+	AuthMethodProxy = "proxy"
+)
+
+// PrincipalKey is the key under which the authenticated Principal is stored in the gin context
+// and the websocket session keys
+const PrincipalKey = "principal"
+
+var (
+	ErrNotAuthenticated   = errors.New("authentication required")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal is an authenticated user of the server
+type Principal struct {
+	Name       string   `json:"name"`
+	AuthMethod string   `json:"auth_method"`
+	Tags       []string `json:"tags,omitempty"`
+
+	filter *pfworkspace.ResourceFilter
+}
+
+func newPrincipal(name, authMethod string, tags []string) *Principal {
+	p := &Principal{Name: name, AuthMethod: authMethod, Tags: tags}
+	if len(tags) > 0 {
+		filter := workspace.ResourceFilterFromTagArgs(tags)
+		p.filter = &filter
+	}
+	return p
+}
+
+// CanAccess returns whether the tag rules of the principal allow access to the resource
+// a resource may be accessed if it, or any of its ancestors, satisfies the rules - so the children
+// of a permitted benchmark may be run
+// a nil principal (i.e. authentication is not enabled) may access all resources
+// a nil resource (e.g. the root of a snapshot which is not in the workspace) may only be accessed by a principal with no tag rules
+func (p *Principal) CanAccess(item modconfig.ModTreeItem) bool {
+	if p == nil || p.filter == nil {
+		return true
+	}
+	if item == nil {
+		return false
+	}
+	if _, ok := item.(*modconfig.Mod); ok {
+		return false
+	}
+	if p.filter.WherePredicate(item) {
+		return true
+	}
+	for _, parent := range item.GetParents() {
+		if p.CanAccess(parent) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTagRules returns whether the access of the principal is restricted by tag rules
+func (p *Principal) HasTagRules() bool {
+	return p != nil && p.filter != nil
+}
+
+// Authenticator authenticates server requests using the tokens, users and proxy of a Config
+type Authenticator struct {
+	config *Config
+	users  map[string]*User
+
+	// bcrypt is deliberately slow - cache the credentials which have been verified,
+	// as the browser sends basic auth credentials with every request
+	verified     map[string][sha256.Size]byte
+	verifiedLock sync.RWMutex
+}
+
+func NewAuthenticator(config *Config) *Authenticator {
+	a := &Authenticator{
+		config:   config,
+		users:    make(map[string]*User),
+		verified: make(map[string][sha256.Size]byte),
+	}
+	for _, u := range config.Users {
+		a.users[u.Name] = u
+	}
+	return a
+}
+
+// SupportsBasicAuth returns whether any users may authenticate using HTTP basic auth
+func (a *Authenticator) SupportsBasicAuth() bool {
+	for _, u := range a.config.Users {
+		if u.PasswordHash != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticate returns the principal authenticated by the request
+//
+// the proxy header is checked first (if the request is from a trusted proxy), followed by the
+// Authorization header, which may contain basic auth credentials or a token (optionally with a Bearer prefix)
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if proxy := a.config.Proxy; proxy != nil {
+		if name := r.Header.Get(proxy.Header); name != "" && a.isTrustedProxy(r.RemoteAddr) {
+			return a.principalForUser(name, AuthMethodProxy, nil), nil
+		}
+	}
+
+	authorization := r.Header.Get("Authorization")
+	if authorization == "" {
+		return nil, ErrNotAuthenticated
+	}
+	if name, password, ok := r.BasicAuth(); ok {
+		if !a.verifyPassword(name, password) {
+			return nil, ErrInvalidCredentials
+		}
+		return a.principalForUser(name, AuthMethodBasic, nil), nil
+	}
+
+	value := strings.TrimSpace(authorization)
+	if prefix, token, ok := strings.Cut(value, " "); ok && strings.EqualFold(prefix, "bearer") {
+		value = strings.TrimSpace(token)
+	}
+	for _, t := range a.config.Tokens {
+		if subtle.ConstantTimeCompare([]byte(t.Value), []byte(value)) == 1 {
+			return a.principalForUser(t.User, AuthMethodToken, t.Tags), nil
+		}
+	}
+	return nil, ErrInvalidCredentials
+}
+
+// principalForUser builds the principal for an authenticated user name
+// if no tag rules are given, the rules of the configured user with the same name (if any) are used
+func (a *Authenticator) principalForUser(name, authMethod string, tags []string) *Principal {
+	if len(tags) == 0 {
+		if u, ok := a.users[name]; ok {
+			tags = u.Tags
+		}
+	}
+	return newPrincipal(name, authMethod, tags)
+}
+
+func (a *Authenticator) verifyPassword(name, password string) bool {
+	u, ok := a.users[name]
+	if !ok || u.PasswordHash == "" {
+		return false
+	}
+	hash := sha256.Sum256([]byte(password))
+
+	a.verifiedLock.RLock()
+	verified, ok := a.verified[name]
+	a.verifiedLock.RUnlock()
+	if ok && subtle.ConstantTimeCompare(verified[:], hash[:]) == 1 {
+		return true
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) != nil {
+		return false
+	}
+	a.verifiedLock.Lock()
+	a.verified[name] = hash
+	a.verifiedLock.Unlock()
+	return true
+}
+
+func (a *Authenticator) isTrustedProxy(remoteAddr string) bool {
+	// if there are no trusted networks (i.e. the config was not validated), no proxy is trusted
+	networks := a.config.Proxy.trustedNetworks
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, n := range networks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package serverauth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/turbot/powerpipe/internal/resources"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func passwordHash(t *testing.T, password string) string {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(hash)
+}
+
+func testConfigHcl(t *testing.T) string {
+	t.Setenv("TEST_CI_TOKEN", "ci-secret")
+	return fmt.Sprintf(`
+token "ci" {
+  value = env("TEST_CI_TOKEN")
+}
+
+token "auditor" {
+  value = "audit-secret"
+  tags  = ["category=compliance"]
+}
+
+user "alice" {
+  password_hash = "%s"
+  tags          = ["team=platform"]
+}
+
+user "bob" {
+  tags = ["env!=prod"]
+}
+
+proxy {
+  header          = "X-Forwarded-User"
+  trusted_proxies = ["10.0.0.0/8", "127.0.0.1"]
+}
+`, passwordHash(t, "alice-password"))
+}
+
+func request(remoteAddr string, headers map[string]string) *http.Request {
+	r, _ := http.NewRequest(http.MethodGet, "/api/v0/dashboard", nil)
+	r.RemoteAddr = remoteAddr
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	return r
+}
+
+func TestLoad(t *testing.T) {
+	c, err := Load(writeFile(t, "auth.ppc", testConfigHcl(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Tokens) != 2 || c.Tokens[0].Value != "ci-secret" {
+		t.Errorf("expected 2 tokens with the first read from the environment, got %+v", c.Tokens)
+	}
+	if len(c.Users) != 2 || c.Proxy == nil || len(c.Proxy.trustedNetworks) != 2 {
+		t.Errorf("expected 2 users and a proxy with 2 trusted networks")
+	}
+
+	yamlConfig := `
+tokens:
+  - user: ci
+    value: ci-secret
+users:
+  - name: alice
+    password_hash: "` + passwordHash(t, "alice-password") + `"
+`
+	c, err = Load(writeFile(t, "auth.yml", yamlConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Tokens) != 1 || len(c.Users) != 1 || c.Proxy != nil {
+		t.Errorf("unexpected yaml config %+v", c)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := map[string]string{
+		"empty":          ``,
+		"invalid hash":   `user "alice" { password_hash = "secret" }`,
+		"invalid tag":    `user "alice" { tags = ["compliance"] }`,
+		"duplicate user": "user \"alice\" {}\nuser \"alice\" {}",
+		"invalid proxy":  `proxy { header = "X-User" trusted_proxies = ["not an ip"] }`,
+		"duplicate token": `
+token "a" { value = "secret" }
+token "b" { value = "secret" }`,
+	}
+	for name, config := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Load(writeFile(t, "auth.ppc", config)); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	c, err := Load(writeFile(t, "auth.ppc", testConfigHcl(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewAuthenticator(c)
+	if !a.SupportsBasicAuth() {
+		t.Errorf("expected basic auth to be supported")
+	}
+
+	basic := func(user, password string) string {
+		r := request("", nil)
+		r.SetBasicAuth(user, password)
+		return r.Header.Get("Authorization")
+	}
+
+	tests := []struct {
+		name       string
+		request    *http.Request
+		user       string
+		authMethod string
+		tags       []string
+		err        error
+	}{
+		{"no credentials", request("192.168.0.1:1234", nil), "", "", nil, ErrNotAuthenticated},
+		{"bearer token", request("192.168.0.1:1234", map[string]string{"Authorization": "Bearer ci-secret"}), "ci", AuthMethodToken, nil, nil},
+		{"token", request("192.168.0.1:1234", map[string]string{"Authorization": "audit-secret"}), "auditor", AuthMethodToken, []string{"category=compliance"}, nil},
+		{"invalid token", request("192.168.0.1:1234", map[string]string{"Authorization": "Bearer wrong"}), "", "", nil, ErrInvalidCredentials},
+		{"basic", request("192.168.0.1:1234", map[string]string{"Authorization": basic("alice", "alice-password")}), "alice", AuthMethodBasic, []string{"team=platform"}, nil},
+		{"basic wrong password", request("192.168.0.1:1234", map[string]string{"Authorization": basic("alice", "wrong")}), "", "", nil, ErrInvalidCredentials},
+		{"basic user without password", request("192.168.0.1:1234", map[string]string{"Authorization": basic("bob", "")}), "", "", nil, ErrInvalidCredentials},
+		{"trusted proxy", request("10.1.2.3:1234", map[string]string{"X-Forwarded-User": "bob"}), "bob", AuthMethodProxy, []string{"env!=prod"}, nil},
+		{"trusted proxy address", request("127.0.0.1:1234", map[string]string{"X-Forwarded-User": "carol"}), "carol", AuthMethodProxy, nil, nil},
+		{"untrusted proxy", request("192.168.0.1:1234", map[string]string{"X-Forwarded-User": "bob"}), "", "", nil, ErrNotAuthenticated},
+		{"untrusted proxy with token", request("192.168.0.1:1234", map[string]string{"X-Forwarded-User": "alice", "Authorization": "audit-secret"}), "auditor", AuthMethodToken, []string{"category=compliance"}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// authenticate twice, to use the verified credentials cache
+			for i := 0; i < 2; i++ {
+				p, err := a.Authenticate(test.request)
+				if test.err != nil {
+					if !errors.Is(err, test.err) {
+						t.Fatalf("expected error %v, got %v", test.err, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if p.Name != test.user || p.AuthMethod != test.authMethod || strings.Join(p.Tags, ",") != strings.Join(test.tags, ",") {
+					t.Errorf("expected %s (%s) with tags %v, got %s (%s) with tags %v", test.user, test.authMethod, test.tags, p.Name, p.AuthMethod, p.Tags)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthenticateProxyWithoutTrustedProxies(t *testing.T) {
+	c, err := Load(writeFile(t, "auth.ppc", `proxy { header = "X-Forwarded-User" }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewAuthenticator(c)
+
+	// with no trusted proxies configured, only a proxy on the same host is trusted
+	for _, remoteAddr := range []string{"127.0.0.1:1234", "[::1]:1234"} {
+		p, err := a.Authenticate(request(remoteAddr, map[string]string{"X-Forwarded-User": "bob"}))
+		if err != nil {
+			t.Fatalf("%s: %v", remoteAddr, err)
+		}
+		if p.Name != "bob" || p.AuthMethod != AuthMethodProxy {
+			t.Errorf("%s: expected bob (%s), got %s (%s)", remoteAddr, AuthMethodProxy, p.Name, p.AuthMethod)
+		}
+	}
+	for _, remoteAddr := range []string{"192.168.0.1:1234", "10.1.2.3:1234", "[2001:db8::1]:1234", "not an address"} {
+		if p, err := a.Authenticate(request(remoteAddr, map[string]string{"X-Forwarded-User": "bob"})); !errors.Is(err, ErrNotAuthenticated) {
+			t.Errorf("%s: expected the proxy header to be ignored, got %+v, %v", remoteAddr, p, err)
+		}
+	}
+}
+
+func TestCanAccess(t *testing.T) {
+	compliance := &resources.Benchmark{}
+	compliance.Tags = map[string]string{"category": "compliance"}
+	child := &resources.Benchmark{}
+	child.Tags = map[string]string{"category": "other"}
+	if err := child.AddParent(compliance); err != nil {
+		t.Fatal(err)
+	}
+	dashboard := &resources.Dashboard{}
+	dashboard.Tags = map[string]string{"category": "cost"}
+
+	var unauthenticated *Principal
+	if !unauthenticated.CanAccess(dashboard) {
+		t.Errorf("expected a nil principal to access all resources")
+	}
+	if !newPrincipal("ci", AuthMethodToken, nil).CanAccess(dashboard) {
+		t.Errorf("expected a principal without tag rules to access all resources")
+	}
+
+	p := newPrincipal("auditor", AuthMethodToken, []string{"category=compliance"})
+	if !p.CanAccess(compliance) {
+		t.Errorf("expected access to a resource which matches the tag rules")
+	}
+	if !p.CanAccess(child) {
+		t.Errorf("expected access to the child of a resource which matches the tag rules")
+	}
+	if p.CanAccess(dashboard) {
+		t.Errorf("expected no access to a resource which does not match the tag rules")
+	}
+	if p.CanAccess(nil) {
+		t.Errorf("expected no access to an unknown resource for a principal with tag rules")
+	}
+	if !newPrincipal("ci", AuthMethodToken, nil).CanAccess(nil) {
+		t.Errorf("expected a principal without tag rules to access an unknown resource")
+	}
+}
@@ -0,0 +1,224 @@
+package serverauth
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/turbot/pipe-fittings/v2/error_helpers"
+	"github.com/turbot/pipe-fittings/v2/funcs"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// Token is a static API token - requests presenting the token are authenticated as the named user
+type Token struct {
+	User  string `yaml:"user" hcl:"user,label"`
+	Value string `yaml:"value" hcl:"value"`
+	// tag rules limiting the dashboards and benchmarks the user may access (see User.Tags)
+	Tags []string `yaml:"tags" hcl:"tags,optional"`
+}
+
+// User is a user of the server
+//
+// users with a password hash may authenticate using HTTP basic auth
+// users authenticated by a trusted proxy are matched to a user by name, to apply the tag rules of the user
+type User struct {
+	Name string `yaml:"name" hcl:"name,label"`
+	// a bcrypt hash of the user password, e.g. as generated by 'htpasswd -nbB'
+	PasswordHash string `yaml:"password_hash" hcl:"password_hash,optional"`
+	// tag rules limiting the dashboards and benchmarks the user may access, in the same format
+	// as the --tag argument, e.g. "category=compliance" or "env!=prod"
+	// if there are no rules the user may access all resources
+	Tags []string `yaml:"tags" hcl:"tags,optional"`
+}
+
+// Proxy configures authentication by a trusted reverse proxy, which passes the name of the
+// authenticated user in a request header
+type Proxy struct {
+	Header string `yaml:"header" hcl:"header"`
+	// the addresses or CIDR ranges of the proxies which are trusted to set the header
+	// if not set, the header is only trusted from loopback addresses (i.e. a proxy on the same host)
+	// the header is ignored on requests from any other address
+	TrustedProxies []string `yaml:"trusted_proxies" hcl:"trusted_proxies,optional"`
+
+	trustedNetworks []*net.IPNet
+}
+
+// Config is the server authentication config loaded from an auth config file
+type Config struct {
+	// the path the config was loaded from
+	Path   string   `yaml:"-"`
+	Tokens []*Token `yaml:"tokens"`
+	Users  []*User  `yaml:"users"`
+	Proxy  *Proxy   `yaml:"proxy"`
+}
+
+// Load loads the server authentication config from a YAML (.yml, .yaml) or HCL file
+//
+// HCL files contain 'token', 'user' and 'proxy' blocks:
+//
+//	token "ci" {
+//	  value = env("POWERPIPE_CI_TOKEN")
+//	}
+//
+//	user "alice" {
+//	  password_hash = "$2y$10$..."
+//	  tags          = ["category=compliance"]
+//	}
+//
+//	proxy {
+//	  header          = "X-Forwarded-User"
+//	  trusted_proxies = ["10.0.0.0/8"]
+//	}
+//
+// YAML files contain the same properties under top level 'tokens', 'users' and 'proxy' keys
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth config file: %w", err)
+	}
+
+	res := &Config{Path: path}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		err = res.loadYaml(data)
+	default:
+		err = res.loadHcl(path, data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse auth config file '%s': %w", path, err)
+	}
+
+	if err := res.validate(); err != nil {
+		return nil, fmt.Errorf("invalid auth config file '%s': %w", path, err)
+	}
+	return res, nil
+}
+
+func (c *Config) loadYaml(data []byte) error {
+	return yaml.Unmarshal(data, c)
+}
+
+func (c *Config) loadHcl(path string, data []byte) error {
+	file, diags := hclparse.NewParser().ParseHCL(data, path)
+	if diags.HasErrors() {
+		return error_helpers.HclDiagsToError("Failed to parse auth config", diags)
+	}
+
+	var content struct {
+		Tokens []*Token `hcl:"token,block"`
+		Users  []*User  `hcl:"user,block"`
+		Proxy  []*Proxy `hcl:"proxy,block"`
+	}
+	// build an eval context just containing functions, so tokens may be read from the environment or a file
+	evalCtx := &hcl.EvalContext{
+		Functions: funcs.ContextFunctions(filepath.Dir(path)),
+		Variables: make(map[string]cty.Value),
+	}
+	if diags := gohcl.DecodeBody(file.Body, evalCtx, &content); diags.HasErrors() {
+		return error_helpers.HclDiagsToError("Failed to decode auth config", diags)
+	}
+	if len(content.Proxy) > 1 {
+		return fmt.Errorf("only one proxy block may be defined")
+	}
+	c.Tokens = content.Tokens
+	c.Users = content.Users
+	if len(content.Proxy) == 1 {
+		c.Proxy = content.Proxy[0]
+	}
+	return nil
+}
+
+func (c *Config) validate() error {
+	if len(c.Tokens) == 0 && len(c.Users) == 0 && c.Proxy == nil {
+		return fmt.Errorf("no tokens, users or proxy defined")
+	}
+
+	tokens := make(map[string]bool)
+	for _, t := range c.Tokens {
+		if t.User == "" || t.Value == "" {
+			return fmt.Errorf("tokens must have a user and a value")
+		}
+		if tokens[t.Value] {
+			return fmt.Errorf("token for user '%s' is not unique", t.User)
+		}
+		tokens[t.Value] = true
+		if err := validateTags(t.Tags); err != nil {
+			return fmt.Errorf("token for user '%s' %w", t.User, err)
+		}
+	}
+
+	users := make(map[string]bool)
+	for _, u := range c.Users {
+		if u.Name == "" {
+			return fmt.Errorf("users must have a name")
+		}
+		if users[u.Name] {
+			return fmt.Errorf("user '%s' is defined more than once", u.Name)
+		}
+		users[u.Name] = true
+		if u.PasswordHash != "" {
+			if _, err := bcrypt.Cost([]byte(u.PasswordHash)); err != nil {
+				return fmt.Errorf("user '%s' has an invalid password_hash - expected a bcrypt hash", u.Name)
+			}
+		}
+		if err := validateTags(u.Tags); err != nil {
+			return fmt.Errorf("user '%s' %w", u.Name, err)
+		}
+	}
+
+	if c.Proxy != nil {
+		if c.Proxy.Header == "" {
+			return fmt.Errorf("proxy must specify a header")
+		}
+		trustedProxies := c.Proxy.TrustedProxies
+		if len(trustedProxies) == 0 {
+			trustedProxies = loopbackNetworks
+		}
+		for _, p := range trustedProxies {
+			network, err := parseNetwork(p)
+			if err != nil {
+				return fmt.Errorf("proxy has an invalid trusted proxy '%s' - expected an IP address or CIDR range", p)
+			}
+			c.Proxy.trustedNetworks = append(c.Proxy.trustedNetworks, network)
+		}
+	}
+	return nil
+}
+
+// the networks trusted to set the proxy header if no trusted proxies are configured
+var loopbackNetworks = []string{"127.0.0.0/8", "::1"}
+
+func validateTags(tags []string) error {
+	for _, t := range tags {
+		if !strings.Contains(t, "=") {
+			return fmt.Errorf("has an invalid tag rule '%s' - expected key=value or key!=value", t)
+		}
+	}
+	return nil
+}
+
+// parseNetwork parses a CIDR range, or an IP address which is treated as a single address range
+func parseNetwork(s string) (*net.IPNet, error) {
+	if strings.Contains(s, "/") {
+		_, network, err := net.ParseCIDR(s)
+		return network, err
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address '%s'", s)
+	}
+	bits := 8 * net.IPv6len
+	if ip.To4() != nil {
+		ip = ip.To4()
+		bits = 8 * net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"path"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,6 +24,8 @@ import (
 	"github.com/turbot/pipe-fittings/v2/constants"
 	"github.com/turbot/pipe-fittings/v2/filepaths"
 	"github.com/turbot/powerpipe/internal/dashboardserver"
+	"github.com/turbot/powerpipe/internal/db_client"
+	"github.com/turbot/powerpipe/internal/serverauth"
 	"github.com/turbot/powerpipe/internal/service/api/common"
 	pworkspace "github.com/turbot/powerpipe/internal/workspace"
 	"gopkg.in/olahol/melody.v1"
@@ -74,6 +78,14 @@ type APIService struct {
 
 	// the loaded workspace
 	workspace *pworkspace.PowerpipeWorkspace
+	// the default database client, used to run benchmarks and controls
+	client *db_client.DbClient
+	// benchmark and control runs started using the API
+	checkExecutions *checkExecutions
+	// if set, all requests (other than the public service endpoint) must be authenticated
+	authenticator *serverauth.Authenticator
+	// if set, the service serves HTTPS on the HTTP port
+	tlsConfig *TLSConfig
 }
 
 // APIServiceOption defines a type of function to configures the APIService.
@@ -93,6 +105,29 @@ func WithWorkspace(workspace *pworkspace.PowerpipeWorkspace) APIServiceOption {
 	}
 }
 
+func WithDefaultClient(client *db_client.DbClient) APIServiceOption {
+	return func(api *APIService) error {
+		api.client = client
+		return nil
+	}
+}
+
+// WithAuthenticator enables authentication of API and websocket requests.
+func WithAuthenticator(authenticator *serverauth.Authenticator) APIServiceOption {
+	return func(api *APIService) error {
+		api.authenticator = authenticator
+		return nil
+	}
+}
+
+// WithTLSConfig serves HTTPS rather than HTTP.
+func WithTLSConfig(tlsConfig *TLSConfig) APIServiceOption {
+	return func(api *APIService) error {
+		api.tlsConfig = tlsConfig
+		return nil
+	}
+}
+
 // WithHTTPPortAndListenConfig sets the HTTP port and listen type for the API service.
 func WithHTTPPortAndListenConfig(listenPort dashboardserver.ListenPort, listenType dashboardserver.ListenType) APIServiceOption {
 	return func(api *APIService) error {
@@ -106,8 +141,9 @@ func WithHTTPPortAndListenConfig(listenPort dashboardserver.ListenPort, listenTy
 func NewAPIService(ctx context.Context, opts ...APIServiceOption) (*APIService, error) {
 	// Defaults
 	api := &APIService{
-		ctx:    ctx,
-		Status: "initialized",
+		ctx:             ctx,
+		Status:          "initialized",
+		checkExecutions: newCheckExecutions(),
 	}
 
 	// Set options
@@ -128,6 +164,10 @@ func (api *APIService) Start() error {
 
 	// Initialize gin
 	router := gin.New()
+	// authentication must be added before any groups are created, as group handlers are copied from the router
+	if api.authenticator != nil {
+		router.Use(api.authenticate)
+	}
 
 	apiPrefixGroup := router.Group(common.APIPrefix())
 	apiPrefixGroup.Use(common.ValidateAPIVersion)
@@ -158,6 +198,9 @@ func (api *APIService) Start() error {
 	apiLimiter.SetBurst(viper.GetInt("web.rate.burst"))
 
 	RegisterPublicAPI(apiPrefixGroup)
+	api.registerCheckAPI(apiPrefixGroup)
+	api.registerResourceAPI(apiPrefixGroup)
+	api.registerMetricsAPI(router)
 
 	// put in handing for the dashboard for the mod
 	assetsDirectory := filepaths.EnsureDashboardAssetsDir()
@@ -165,7 +208,12 @@ func (api *APIService) Start() error {
 	router.Use(static.Serve("/", static.LocalFile(assetsDirectory, true)))
 	if api.webSocket != nil {
 		router.GET("/ws", func(c *gin.Context) {
-			if err := api.webSocket.HandleRequest(c.Writer, c.Request); err != nil {
+			// the session keys make the authenticated principal available to the dashboard server
+			var keys map[string]any
+			if principal := requestPrincipal(c); principal != nil {
+				keys = map[string]any{serverauth.PrincipalKey: principal}
+			}
+			if err := api.webSocket.HandleRequestWithKeys(c.Writer, c.Request, keys); err != nil {
 				_ = c.AbortWithError(http.StatusInternalServerError, err)
 			}
 		})
@@ -216,6 +264,10 @@ func (api *APIService) Start() error {
 		listenHost = "localhost"
 	}
 
+	if api.tlsConfig != nil {
+		return api.startTLS(router, listenHost)
+	}
+
 	// Server setup with graceful shutdown
 	api.httpServer = &http.Server{
 		// Use listenHost (derived from api.HTTPListenType) and api.HTTPListenPort (the integer port)
@@ -224,12 +276,6 @@ func (api *APIService) Start() error {
 		ReadHeaderTimeout: 60 * time.Second,
 	}
 
-	api.httpsServer = &http.Server{
-		Addr:              fmt.Sprintf("%s:%s", api.HTTPSHost, api.HTTPSPort),
-		Handler:           router,
-		ReadHeaderTimeout: 60 * time.Second,
-	}
-
 	// Initializing the server in a goroutine so that
 	// it won't block the graceful shutdown handling below
 	go func() {
@@ -243,3 +289,43 @@ func (api *APIService) Start() error {
 
 	return nil
 }
+
+// startTLS starts the HTTPS server on the HTTP port and, if a redirect port is configured,
+// an HTTP server which redirects requests to HTTPS
+func (api *APIService) startTLS(router *gin.Engine, listenHost string) error {
+	tlsConfig, err := api.tlsConfig.build(api.ctx)
+	if err != nil {
+		return err
+	}
+
+	api.HTTPSHost = listenHost
+	api.HTTPSPort = strconv.Itoa(int(api.HTTPPort))
+	api.httpsServer = &http.Server{
+		Addr:              net.JoinHostPort(api.HTTPSHost, api.HTTPSPort),
+		Handler:           router,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: 60 * time.Second,
+	}
+	go func() {
+		// the certificate is provided by the TLS config
+		if err := api.httpsServer.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("listen: %s\n", err)
+		}
+	}()
+
+	if api.tlsConfig.RedirectPort != 0 {
+		api.httpServer = &http.Server{
+			Addr:              net.JoinHostPort(listenHost, strconv.Itoa(api.tlsConfig.RedirectPort)),
+			Handler:           httpsRedirectHandler(int(api.HTTPPort)),
+			ReadHeaderTimeout: 60 * time.Second,
+		}
+		go func() {
+			if err := api.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("listen: %s\n", err)
+			}
+		}()
+	}
+
+	api.Status = "running"
+	return nil
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/turbot/pipe-fittings/v2/perr"
+	"github.com/turbot/powerpipe/internal/serverauth"
+	"github.com/turbot/powerpipe/internal/service/api/common"
+)
+
+// authenticate is middleware which authenticates every request, storing the principal in the context
+// the public service endpoint is not authenticated, so it may be used for health checks
+func (api *APIService) authenticate(c *gin.Context) {
+	if c.FullPath() == common.APIPrefix()+"/service" {
+		c.Next()
+		return
+	}
+
+	principal, err := api.authenticator.Authenticate(c.Request)
+	if err != nil {
+		// prompt browsers for credentials
+		if api.authenticator.SupportsBasicAuth() {
+			c.Header("WWW-Authenticate", `Basic realm="powerpipe", charset="UTF-8"`)
+		}
+		common.AbortWithError(c, perr.UnauthorizedWithMessage(err.Error()))
+		return
+	}
+	c.Set(serverauth.PrincipalKey, principal)
+	c.Next()
+}
+
+// requestPrincipal returns the principal authenticated for the request
+// this is nil if authentication is not enabled
+func requestPrincipal(c *gin.Context) *serverauth.Principal {
+	principal, _ := c.Get(serverauth.PrincipalKey)
+	p, _ := principal.(*serverauth.Principal)
+	return p
+}
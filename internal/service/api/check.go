@@ -0,0 +1,335 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/xid"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/pipe-fittings/v2/modconfig"
+	"github.com/turbot/pipe-fittings/v2/perr"
+	pfworkspace "github.com/turbot/pipe-fittings/v2/workspace"
+	"github.com/turbot/powerpipe/internal/cmdconfig"
+	"github.com/turbot/powerpipe/internal/controldisplay"
+	"github.com/turbot/powerpipe/internal/controlexecute"
+	"github.com/turbot/powerpipe/internal/resources"
+	"github.com/turbot/powerpipe/internal/serverauth"
+	"github.com/turbot/powerpipe/internal/service/api/common"
+	"github.com/turbot/powerpipe/internal/types"
+	"github.com/turbot/powerpipe/internal/workspace"
+	"golang.org/x/exp/maps"
+)
+
+const (
+	CheckExecutionStatusRunning  = "running"
+	CheckExecutionStatusComplete = "complete"
+	CheckExecutionStatusError    = "error"
+)
+
+const (
+	// finished executions are retained for this long, so their status and output may be fetched
+	checkExecutionRetention = time.Hour
+	// the maximum number of finished executions retained - the oldest are evicted first
+	maxFinishedCheckExecutions = 100
+)
+
+// CheckExecution tracks a benchmark or control run started through the API
+type CheckExecution struct {
+	ExecutionId string                       `json:"execution_id"`
+	Target      string                       `json:"target"`
+	Status      string                       `json:"status"`
+	StartTime   time.Time                    `json:"start_time"`
+	EndTime     *time.Time                   `json:"end_time,omitempty"`
+	Summary     *controlexecute.GroupSummary `json:"summary,omitempty"`
+	Error       string                       `json:"error,omitempty"`
+
+	target modconfig.ModTreeItem
+	tree   *controlexecute.ExecutionTree
+}
+
+// checkExecutions is the in-memory store of API initiated check executions, keyed by execution id
+type checkExecutions struct {
+	executions map[string]*CheckExecution
+	lock       sync.RWMutex
+}
+
+func newCheckExecutions() *checkExecutions {
+	return &checkExecutions{executions: make(map[string]*CheckExecution)}
+}
+
+func (e *checkExecutions) add(execution *CheckExecution) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.executions[execution.ExecutionId] = execution
+	e.evict(time.Now())
+}
+
+// get returns a copy of the execution, so the caller can read it without holding the lock
+func (e *checkExecutions) get(executionId string) (CheckExecution, bool) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	execution, ok := e.executions[executionId]
+	if !ok {
+		return CheckExecution{}, false
+	}
+	return *execution, true
+}
+
+func (e *checkExecutions) setComplete(executionId string, err error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	execution, ok := e.executions[executionId]
+	if !ok {
+		return
+	}
+	endTime := time.Now()
+	defer e.evict(endTime)
+	execution.EndTime = &endTime
+	if err != nil {
+		execution.Status = CheckExecutionStatusError
+		execution.Error = err.Error()
+		return
+	}
+	execution.Status = CheckExecutionStatusComplete
+	if execution.tree.Root != nil {
+		execution.Summary = execution.tree.Root.Summary
+	}
+}
+
+// evict removes finished executions which ended before the retention period,
+// and the oldest finished executions beyond the maximum number retained
+// the latest completed execution of each target is always retained, as it is reported by the metrics endpoint
+// the caller must hold the lock
+func (e *checkExecutions) evict(now time.Time) {
+	latest := e.latestComplete(nil)
+	var finished []*CheckExecution
+	for _, execution := range e.executions {
+		if execution.EndTime == nil || latest[execution.Target] == execution {
+			continue
+		}
+		if now.Sub(*execution.EndTime) > checkExecutionRetention {
+			delete(e.executions, execution.ExecutionId)
+			continue
+		}
+		finished = append(finished, execution)
+	}
+	if len(finished) <= maxFinishedCheckExecutions {
+		return
+	}
+	slices.SortFunc(finished, func(a, b *CheckExecution) int { return a.EndTime.Compare(*b.EndTime) })
+	for _, execution := range finished[:len(finished)-maxFinishedCheckExecutions] {
+		delete(e.executions, execution.ExecutionId)
+	}
+}
+
+// latestComplete returns the most recently completed execution of each target which the principal may access,
+// keyed by target - the caller must hold the lock
+func (e *checkExecutions) latestComplete(principal *serverauth.Principal) map[string]*CheckExecution {
+	latest := make(map[string]*CheckExecution)
+	for _, execution := range e.executions {
+		if execution.Status != CheckExecutionStatusComplete || !principal.CanAccess(execution.target) {
+			continue
+		}
+		if current, ok := latest[execution.Target]; !ok || execution.EndTime.After(*current.EndTime) {
+			latest[execution.Target] = execution
+		}
+	}
+	return latest
+}
+
+// latestTrees returns the execution tree of the most recently completed execution of each target
+// which the principal may access
+func (e *checkExecutions) latestTrees(principal *serverauth.Principal) []*controlexecute.ExecutionTree {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	latest := e.latestComplete(principal)
+	targets := maps.Keys(latest)
+	slices.Sort(targets)
+	res := make([]*controlexecute.ExecutionTree, len(targets))
+	for i, target := range targets {
+		res[i] = latest[target].tree
+	}
+	return res
+}
+
+func (api *APIService) registerCheckAPI(router *gin.RouterGroup) {
+	router.POST("/benchmark/:name/run", api.benchmarkRun)
+	router.POST("/control/:name/run", api.controlRun)
+	router.GET("/execution/:execution_id", api.executionGet)
+	router.GET("/execution/:execution_id/output", api.executionOutputGet)
+}
+
+func (api *APIService) benchmarkRun(c *gin.Context) {
+	checkRun[*resources.Benchmark](api, c)
+}
+
+func (api *APIService) controlRun(c *gin.Context) {
+	checkRun[*resources.Control](api, c)
+}
+
+func checkRun[T modconfig.ModTreeItem](api *APIService, c *gin.Context) {
+	var uri types.ResourceRequestURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+	var body types.CheckRunRequestBody
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			common.AbortWithError(c, err)
+			return
+		}
+	}
+	// if both 'where' and 'tag' have been used, then it's an error (as with 'benchmark run')
+	if body.Where != "" && len(body.Tags) > 0 {
+		common.AbortWithError(c, perr.BadRequestWithMessage("only 1 of 'where' and 'tag' may be set"))
+		return
+	}
+	if api.workspace == nil || api.client == nil {
+		common.AbortWithError(c, perr.InternalWithMessage("the API service has no workspace or database client"))
+		return
+	}
+
+	targets, err := cmdconfig.ResolveTargets[T]([]string{uri.Name}, api.workspace)
+	if err != nil {
+		common.AbortWithError(c, perr.NotFoundWithMessage(err.Error()))
+		return
+	}
+	target := targets[0]
+	// resources the user may not access are reported as not found
+	if !requestPrincipal(c).CanAccess(target) {
+		common.AbortWithError(c, perr.NotFound(resources.GenericTypeToBlockType[T](), uri.Name))
+		return
+	}
+	// detection benchmarks are not executed using an ExecutionTree
+	if _, ok := target.(*resources.DetectionBenchmark); ok {
+		common.AbortWithError(c, perr.BadRequestWithMessage(fmt.Sprintf("%s is a detection benchmark", target.Name())))
+		return
+	}
+
+	var controlFilter pfworkspace.ResourceFilter
+	if len(body.Tags) > 0 {
+		controlFilter = workspace.ResourceFilterFromTagArgs(body.Tags)
+	} else if body.Where != "" {
+		controlFilter = pfworkspace.ResourceFilter{Where: body.Where}
+	}
+
+	tree, err := controlexecute.NewExecutionTree(api.ctx, api.workspace, api.client, controlFilter, target)
+	if err != nil {
+		common.AbortWithError(c, perr.BadRequestWithMessage(fmt.Sprintf("could not create execution tree for %s: %s", target.Name(), err.Error())))
+		return
+	}
+
+	execution := &CheckExecution{
+		ExecutionId: fmt.Sprintf("exec_%s", xid.New().String()),
+		Target:      target.Name(),
+		Status:      CheckExecutionStatusRunning,
+		StartTime:   time.Now(),
+		target:      target,
+		tree:        tree,
+	}
+	api.checkExecutions.add(execution)
+	// take a copy for the response before the execution starts updating it
+	res := *execution
+
+	go api.executeCheck(api.ctx, execution)
+
+	c.JSON(http.StatusCreated, res)
+}
+
+func (api *APIService) executeCheck(ctx context.Context, execution *CheckExecution) {
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = helpers.ToError(r)
+		}
+		if err != nil {
+			slog.Warn("check execution failed", "execution_id", execution.ExecutionId, "error", err)
+			execution.tree.EnsureConsistentStateForExport()
+		}
+		api.checkExecutions.setComplete(execution.ExecutionId, err)
+	}()
+
+	err = execution.tree.Execute(ctx)
+	if err != nil {
+		return
+	}
+	// populate the control run instances - these are required by the csv template
+	execution.tree.PopulateControlRunInstances()
+}
+
+func (api *APIService) executionGet(c *gin.Context) {
+	var uri types.ExecutionRequestURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+	execution, ok := api.checkExecutions.get(uri.ExecutionId)
+	if !ok || !requestPrincipal(c).CanAccess(execution.target) {
+		common.AbortWithError(c, perr.NotFound("execution", uri.ExecutionId))
+		return
+	}
+	c.JSON(http.StatusOK, execution)
+}
+
+func (api *APIService) executionOutputGet(c *gin.Context) {
+	var uri types.ExecutionRequestURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+	var query types.ExecutionOutputRequestQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+	execution, ok := api.checkExecutions.get(uri.ExecutionId)
+	if !ok || !requestPrincipal(c).CanAccess(execution.target) {
+		common.AbortWithError(c, perr.NotFound("execution", uri.ExecutionId))
+		return
+	}
+	if execution.Status == CheckExecutionStatusRunning {
+		common.AbortWithError(c, perr.ConflictWithMessage(fmt.Sprintf("execution %s is still running", execution.ExecutionId)))
+		return
+	}
+
+	format := constants.OutputFormatJSON
+	if query.Format != nil {
+		format = *query.Format
+	}
+	formatResolver, err := controldisplay.NewFormatResolver(execution.target)
+	if err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+	formatter, err := formatResolver.GetFormatter(format)
+	if err != nil {
+		common.AbortWithError(c, perr.BadRequestWithMessage(err.Error()))
+		return
+	}
+	reader, err := formatter.Format(c.Request.Context(), execution.tree)
+	if err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+
+	contentType := "application/json"
+	if format == constants.OutputFormatCSV {
+		contentType = "text/csv"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s%s", execution.ExecutionId, formatter.FileExtension()))
+	c.Header("Content-Type", contentType)
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, reader); err != nil {
+		slog.Warn("failed to write execution output", "execution_id", execution.ExecutionId, "error", err)
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/turbot/powerpipe/internal/controlexecute"
+	"github.com/turbot/powerpipe/internal/resources"
+	"github.com/turbot/powerpipe/internal/serverauth"
+)
+
+func newTestCheckExecution(id, target string, tags map[string]string, endTime time.Time) *CheckExecution {
+	benchmark := &resources.Benchmark{}
+	benchmark.FullName = target
+	benchmark.Tags = tags
+	return &CheckExecution{
+		ExecutionId: id,
+		Target:      target,
+		Status:      CheckExecutionStatusComplete,
+		EndTime:     &endTime,
+		target:      benchmark,
+		tree:        &controlexecute.ExecutionTree{},
+	}
+}
+
+func TestLatestTreesAppliesTagRules(t *testing.T) {
+	now := time.Now()
+	executions := newCheckExecutions()
+	compliance := newTestCheckExecution("a", "mod.benchmark.compliance", map[string]string{"category": "compliance"}, now)
+	cost := newTestCheckExecution("b", "mod.benchmark.cost", map[string]string{"category": "cost"}, now)
+	executions.add(compliance)
+	executions.add(cost)
+
+	if trees := executions.latestTrees(nil); len(trees) != 2 {
+		t.Errorf("expected the runs of both benchmarks without authentication, got %d", len(trees))
+	}
+
+	authenticator := serverauth.NewAuthenticator(&serverauth.Config{
+		Tokens: []*serverauth.Token{{User: "auditor", Value: "secret", Tags: []string{"category=compliance"}}},
+	})
+	request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	request.Header.Set("Authorization", "Bearer secret")
+	principal, err := authenticator.Authenticate(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trees := executions.latestTrees(principal)
+	if len(trees) != 1 || trees[0] != compliance.tree {
+		t.Errorf("expected only the run of the benchmark matching the tag rules, got %d runs", len(trees))
+	}
+}
+
+func TestCheckExecutionsEviction(t *testing.T) {
+	now := time.Now()
+	expired := now.Add(-2 * checkExecutionRetention)
+	executions := newCheckExecutions()
+	// the latest completed execution of a target is retained, however old
+	executions.add(newTestCheckExecution("latest", "mod.benchmark.a", nil, expired))
+	executions.add(newTestCheckExecution("old", "mod.benchmark.a", nil, expired.Add(-time.Minute)))
+	executions.add(&CheckExecution{ExecutionId: "running", Target: "mod.benchmark.a", Status: CheckExecutionStatusRunning})
+
+	for _, id := range []string{"latest", "running"} {
+		if _, ok := executions.get(id); !ok {
+			t.Errorf("expected execution '%s' to be retained", id)
+		}
+	}
+	if _, ok := executions.get("old"); ok {
+		t.Errorf("expected the expired execution to be evicted")
+	}
+
+	// beyond the maximum, the oldest finished executions are evicted
+	for i := range maxFinishedCheckExecutions + 5 {
+		execution := newTestCheckExecution(fmt.Sprintf("error%d", i), "mod.benchmark.b", nil, now.Add(time.Duration(i)*time.Second))
+		execution.Status = CheckExecutionStatusError
+		executions.add(execution)
+	}
+	if _, ok := executions.get("error0"); ok {
+		t.Errorf("expected the oldest finished execution to be evicted")
+	}
+	if _, ok := executions.get(fmt.Sprintf("error%d", maxFinishedCheckExecutions+4)); !ok {
+		t.Errorf("expected the newest finished execution to be retained")
+	}
+	// the finished executions, plus the latest completed and running executions
+	if count := len(executions.executions); count != maxFinishedCheckExecutions+2 {
+		t.Errorf("expected %d executions, got %d", maxFinishedCheckExecutions+2, count)
+	}
+}
@@ -1,42 +1,56 @@
 package common
 
-// import (
-// 	"encoding/base64"
-
-// 	"github.com/gin-gonic/gin"
-// 	"github.com/spf13/viper"
-// 	"github.com/turbot/flowpipe/internal/types"
-// )
-
-// func ListPagingRequest(c *gin.Context) (nextToken string, limit int, err error) {
-
-// 	// Validate and extract paging data from the query string
-// 	uri := types.ListRequestQuery{}
-// 	if e := c.ShouldBindQuery(&uri); e != nil {
-// 		err = e
-// 		return
-// 	}
-
-// 	// Because limit is optional, and could be zero (which is matched by
-// 	// omitempty), specifically catch the zero case here and use the default
-// 	// instead.
-// 	if uri.Limit == nil {
-// 		limit = viper.GetInt("api.list.limit.default")
-// 	} else {
-// 		limit = *uri.Limit
-// 	}
-
-// 	// next_token is a base64 encoded version of the last matched ID. If not
-// 	// provided then next_token is "", which means to start at the beginning.
-// 	// If the decoding fails, then
-// 	if uri.NextToken != "" {
-// 		data, e := base64.StdEncoding.DecodeString(uri.NextToken)
-// 		if e != nil {
-// 			err = e
-// 			return
-// 		}
-// 		nextToken = string(data)
-// 	}
-
-// 	return
-// }
+import (
+	"encoding/base64"
+
+	"github.com/gin-gonic/gin"
+	"github.com/turbot/pipe-fittings/v2/perr"
+	"github.com/turbot/powerpipe/internal/types"
+)
+
+// DefaultListLimit is the page size used by list endpoints if no limit is provided
+const DefaultListLimit = 100
+
+// MaxListLimit is the largest page size accepted by list endpoints
+const MaxListLimit = 1000
+
+func ListPagingRequest(c *gin.Context) (nextToken string, limit int, err error) {
+
+	// Validate and extract paging data from the query string
+	uri := types.ListRequestQuery{}
+	if e := c.ShouldBindQuery(&uri); e != nil {
+		err = e
+		return
+	}
+
+	// Because limit is optional, and could be zero (which is matched by
+	// omitempty), specifically catch the zero case here and use the default
+	// instead.
+	if uri.Limit == nil {
+		limit = DefaultListLimit
+	} else {
+		limit = *uri.Limit
+	}
+	if limit < 1 || limit > MaxListLimit {
+		err = perr.BadRequestWithMessage("limit must be between 1 and 1000")
+		return
+	}
+
+	// next_token is a base64 encoded version of the last matched ID. If not
+	// provided then next_token is "", which means to start at the beginning.
+	if uri.NextToken != "" {
+		data, e := base64.StdEncoding.DecodeString(uri.NextToken)
+		if e != nil {
+			err = perr.BadRequestWithMessage("invalid next_token")
+			return
+		}
+		nextToken = string(data)
+	}
+
+	return
+}
+
+// ListPagingToken returns the next_token for a page ending with the given ID
+func ListPagingToken(lastID string) string {
+	return base64.StdEncoding.EncodeToString([]byte(lastID))
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/turbot/powerpipe/internal/controldisplay"
+	"github.com/turbot/powerpipe/internal/service/api/common"
+)
+
+// registerMetricsAPI adds the /metrics endpoint, which exposes the results of the most recent
+// API run of each benchmark and control in the OpenMetrics text format
+// only the runs of the benchmarks and controls which the user may access are included
+func (api *APIService) registerMetricsAPI(router *gin.Engine) {
+	router.GET("/metrics", api.metricsGet)
+}
+
+func (api *APIService) metricsGet(c *gin.Context) {
+	w := controldisplay.NewOpenMetricsWriter()
+	for _, tree := range api.checkExecutions.latestTrees(requestPrincipal(c)) {
+		w.Add(tree)
+	}
+
+	var buf bytes.Buffer
+	if err := w.Write(&buf); err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, controldisplay.OpenMetricsContentType, buf.Bytes())
+}
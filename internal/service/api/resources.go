@@ -0,0 +1,150 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/turbot/pipe-fittings/v2/modconfig"
+	"github.com/turbot/pipe-fittings/v2/perr"
+	"github.com/turbot/pipe-fittings/v2/sanitize"
+	pfworkspace "github.com/turbot/pipe-fittings/v2/workspace"
+	"github.com/turbot/powerpipe/internal/cmdconfig"
+	"github.com/turbot/powerpipe/internal/resources"
+	"github.com/turbot/powerpipe/internal/service/api/common"
+	"github.com/turbot/powerpipe/internal/types"
+	"github.com/turbot/powerpipe/internal/workspace"
+)
+
+func (api *APIService) registerResourceAPI(router *gin.RouterGroup) {
+	registerResourceRoutes[*resources.Benchmark](api, router)
+	registerResourceRoutes[*resources.Control](api, router)
+	registerResourceRoutes[*resources.Dashboard](api, router)
+	registerResourceRoutes[*resources.Query](api, router)
+	registerResourceRoutes[*resources.Detection](api, router)
+	registerResourceRoutes[*modconfig.Variable](api, router)
+}
+
+// registerResourceRoutes adds the list and get routes for a resource type, e.g. GET /benchmark and GET /benchmark/:name
+func registerResourceRoutes[T modconfig.ModTreeItem](api *APIService, router *gin.RouterGroup) {
+	typeName := resources.GenericTypeToBlockType[T]()
+	router.GET(fmt.Sprintf("/%s", typeName), func(c *gin.Context) { resourceList[T](api, c) })
+	router.GET(fmt.Sprintf("/%s/:name", typeName), func(c *gin.Context) { resourceGet[T](api, c) })
+}
+
+func resourceList[T modconfig.ModTreeItem](api *APIService, c *gin.Context) {
+	nextToken, limit, err := common.ListPagingRequest(c)
+	if err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+	var query types.ResourceListRequestQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+	if api.workspace == nil {
+		common.AbortWithError(c, perr.InternalWithMessage("the API service has no workspace"))
+		return
+	}
+
+	var resourceFilter pfworkspace.ResourceFilter
+	if len(query.Tags) > 0 {
+		resourceFilter = workspace.ResourceFilterFromTagArgs(query.Tags)
+	}
+
+	items, err := filterResources[T](api.workspace, resourceFilter)
+	if err != nil {
+		common.AbortWithError(c, perr.BadRequestWithMessage(err.Error()))
+		return
+	}
+	// only list the resources the user may access
+	principal := requestPrincipal(c)
+	items = slices.DeleteFunc(items, func(item modconfig.ModTreeItem) bool { return !principal.CanAccess(item) })
+
+	// sort by name so that paging is stable
+	slices.SortFunc(items, func(a, b modconfig.ModTreeItem) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	// skip everything up to and including the item identified by the next_token
+	if nextToken != "" {
+		idx, _ := slices.BinarySearchFunc(items, nextToken, func(item modconfig.ModTreeItem, name string) int {
+			return strings.Compare(item.Name(), name)
+		})
+		if idx < len(items) && items[idx].Name() == nextToken {
+			idx++
+		}
+		items = items[idx:]
+	}
+
+	res := types.ListResponse[modconfig.ModTreeItem]{Items: []modconfig.ModTreeItem{}}
+	if len(items) > limit {
+		items = items[:limit]
+		token := common.ListPagingToken(items[limit-1].Name())
+		res.NextToken = &token
+	}
+	res.Items = append(res.Items, items...)
+
+	writeSanitizedJSON(c, http.StatusOK, res)
+}
+
+func resourceGet[T modconfig.ModTreeItem](api *APIService, c *gin.Context) {
+	var uri types.ResourceRequestURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+	if api.workspace == nil {
+		common.AbortWithError(c, perr.InternalWithMessage("the API service has no workspace"))
+		return
+	}
+
+	targets, err := cmdconfig.ResolveTargets[T]([]string{uri.Name}, api.workspace)
+	// resources the user may not access are reported as not found
+	if err != nil || len(targets) != 1 || !requestPrincipal(c).CanAccess(targets[0]) {
+		common.AbortWithError(c, perr.NotFound(resources.GenericTypeToBlockType[T](), uri.Name))
+		return
+	}
+
+	writeSanitizedJSON(c, http.StatusOK, targets[0])
+}
+
+// filterResources returns the workspace resources of type T which satisfy the filter
+// TACTICAL: as with 'benchmark list', detection benchmarks are included in the benchmark list
+// https://github.com/turbot/powerpipe/issues/609
+func filterResources[T modconfig.ModTreeItem](w *workspace.PowerpipeWorkspace, filter pfworkspace.ResourceFilter) ([]modconfig.ModTreeItem, error) {
+	resourceMap, err := pfworkspace.FilterWorkspaceResourcesOfType[T](&w.Workspace, filter)
+	if err != nil {
+		return nil, err
+	}
+	var res []modconfig.ModTreeItem
+	for _, r := range resourceMap {
+		res = append(res, r)
+	}
+
+	var empty T
+	if _, ok := any(empty).(*resources.Benchmark); ok {
+		detectionBenchmarks, err := pfworkspace.FilterWorkspaceResourcesOfType[*resources.DetectionBenchmark](&w.Workspace, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range detectionBenchmarks {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+// writeSanitizedJSON writes the object as JSON, sanitized in the same way as the '--output json' printer
+func writeSanitizedJSON(c *gin.Context, code int, obj any) {
+	s, err := json.Marshal(obj)
+	if err != nil {
+		common.AbortWithError(c, err)
+		return
+	}
+	c.Data(code, "application/json", []byte(sanitize.Instance.SanitizeString(string(s))))
+}
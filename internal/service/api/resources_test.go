@@ -0,0 +1,199 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/turbot/pipe-fittings/v2/modconfig"
+	pfworkspace "github.com/turbot/pipe-fittings/v2/workspace"
+	"github.com/turbot/powerpipe/internal/resources"
+	"github.com/turbot/powerpipe/internal/serverauth"
+	"github.com/turbot/powerpipe/internal/service/api/common"
+	"github.com/turbot/powerpipe/internal/workspace"
+)
+
+// newTestResourceAPI creates an API service whose workspace contains the benchmarks b00...b<count-1>,
+// alternately tagged with the categories compliance and cost, and the detection benchmark d00
+func newTestResourceAPI(count int) *APIService {
+	modconfig.AppSpecificNewModResourcesFunc = resources.NewModResources
+
+	mod := modconfig.NewMod("test_mod", ".", hcl.Range{})
+	modResources := resources.NewModResources(mod).(*resources.PowerpipeModResources)
+	for i := range count {
+		benchmark := resources.NewBenchmark(&hcl.Block{Type: "benchmark"}, mod, fmt.Sprintf("b%02d", i)).(*resources.Benchmark)
+		benchmark.Tags = map[string]string{"category": []string{"compliance", "cost"}[i%2]}
+		modResources.ControlBenchmarks[benchmark.Name()] = benchmark
+	}
+	detectionBenchmark := resources.NewDetectionBenchmark(&hcl.Block{Type: "benchmark"}, mod, "d00").(*resources.DetectionBenchmark)
+	detectionBenchmark.Tags = map[string]string{"category": "compliance"}
+	modResources.DetectionBenchmarks[detectionBenchmark.Name()] = detectionBenchmark
+	mod.Resources = modResources
+
+	return &APIService{
+		workspace: &workspace.PowerpipeWorkspace{Workspace: pfworkspace.Workspace{Mod: mod}},
+	}
+}
+
+// serveResourceRequest serves a request to the benchmark routes, as the given principal (if any)
+func serveResourceRequest(api *APIService, principal *serverauth.Principal, path string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	group := router.Group("/api", func(c *gin.Context) {
+		if principal != nil {
+			c.Set(serverauth.PrincipalKey, principal)
+		}
+		c.Next()
+	})
+	registerResourceRoutes[*resources.Benchmark](api, group)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+// listResourceNames lists the benchmarks, returning the names of the listed items and the next token
+func listResourceNames(t *testing.T, api *APIService, principal *serverauth.Principal, query url.Values) ([]string, string) {
+	w := serveResourceRequest(api, principal, "/api/benchmark?"+query.Encode())
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var res struct {
+		Items []struct {
+			Name string `json:"qualified_name"`
+		} `json:"items"`
+		NextToken *string `json:"next_token"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, item := range res.Items {
+		names = append(names, item.Name)
+	}
+	var nextToken string
+	if res.NextToken != nil {
+		nextToken = *res.NextToken
+	}
+	return names, nextToken
+}
+
+func newTestResourcePrincipal(t *testing.T, tags ...string) *serverauth.Principal {
+	authenticator := serverauth.NewAuthenticator(&serverauth.Config{
+		Tokens: []*serverauth.Token{{User: "auditor", Value: "secret", Tags: tags}},
+	})
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("Authorization", "Bearer secret")
+	principal, err := authenticator.Authenticate(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return principal
+}
+
+func TestResourceList(t *testing.T) {
+	api := newTestResourceAPI(3)
+	names, nextToken := listResourceNames(t, api, nil, url.Values{})
+	// detection benchmarks are included in the benchmark list
+	expected := "test_mod.benchmark.b00,test_mod.benchmark.b01,test_mod.benchmark.b02,test_mod.benchmark.d00"
+	if got := strings.Join(names, ","); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+	if nextToken != "" {
+		t.Errorf("expected no next token for a single page, got %s", nextToken)
+	}
+
+	names, _ = listResourceNames(t, api, nil, url.Values{"tag": {"category=cost"}})
+	if got := strings.Join(names, ","); got != "test_mod.benchmark.b01" {
+		t.Errorf("expected only the benchmarks matching the tag filter, got %s", got)
+	}
+}
+
+func TestResourceListPaging(t *testing.T) {
+	api := newTestResourceAPI(common.DefaultListLimit + 10)
+
+	// page through all the benchmarks, using the default limit and then a limit of 7
+	var all []string
+	query := url.Values{}
+	for pages := 0; ; pages++ {
+		if pages > common.DefaultListLimit {
+			t.Fatalf("paging did not terminate")
+		}
+		names, nextToken := listResourceNames(t, api, nil, query)
+		if pages == 0 && len(names) != common.DefaultListLimit {
+			t.Errorf("expected the first page to have %d items, got %d", common.DefaultListLimit, len(names))
+		}
+		all = append(all, names...)
+		if nextToken == "" {
+			break
+		}
+		query = url.Values{"next_token": {nextToken}, "limit": {"7"}}
+	}
+	if len(all) != common.DefaultListLimit+11 {
+		t.Fatalf("expected %d items across all pages, got %d", common.DefaultListLimit+11, len(all))
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i-1] >= all[i] {
+			t.Errorf("expected items to be sorted and not repeated across pages, got %s before %s", all[i-1], all[i])
+		}
+	}
+
+	// a next token which does not match an item resumes from the following item
+	names, _ := listResourceNames(t, api, nil, url.Values{"next_token": {common.ListPagingToken("test_mod.benchmark.b50a")}, "limit": {"1"}})
+	if len(names) != 1 || names[0] != "test_mod.benchmark.b51" {
+		t.Errorf("expected to resume after the next token, got %v", names)
+	}
+}
+
+func TestResourceListInvalidRequest(t *testing.T) {
+	api := newTestResourceAPI(3)
+	for name, query := range map[string]string{
+		"invalid next token": "next_token=!!!",
+		"zero limit":         "limit=0",
+		"negative limit":     "limit=-1",
+		"limit too large":    fmt.Sprintf("limit=%d", common.MaxListLimit+1),
+	} {
+		if w := serveResourceRequest(api, nil, "/api/benchmark?"+query); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", name, http.StatusBadRequest, w.Code)
+		}
+	}
+	if w := serveResourceRequest(api, nil, fmt.Sprintf("/api/benchmark?limit=%d", common.MaxListLimit)); w.Code != http.StatusOK {
+		t.Errorf("expected the maximum limit to be accepted, got status %d", w.Code)
+	}
+}
+
+func TestResourceGet(t *testing.T) {
+	api := newTestResourceAPI(3)
+	for _, name := range []string{"test_mod.benchmark.b01", "b01"} {
+		w := serveResourceRequest(api, nil, "/api/benchmark/"+name)
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"qualified_name":"test_mod.benchmark.b01"`) {
+			t.Errorf("expected benchmark %s to be returned, got status %d: %s", name, w.Code, w.Body.String())
+		}
+	}
+	if w := serveResourceRequest(api, nil, "/api/benchmark/missing"); w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for a missing benchmark, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestResourceRestrictedPrincipal(t *testing.T) {
+	api := newTestResourceAPI(3)
+	principal := newTestResourcePrincipal(t, "category=cost")
+
+	names, _ := listResourceNames(t, api, principal, url.Values{})
+	if got := strings.Join(names, ","); got != "test_mod.benchmark.b01" {
+		t.Errorf("expected only the benchmarks the principal may access, got %s", got)
+	}
+	// resources the principal may not access are reported as not found
+	if w := serveResourceRequest(api, principal, "/api/benchmark/test_mod.benchmark.b00"); w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for an inaccessible benchmark, got %d", http.StatusNotFound, w.Code)
+	}
+	if w := serveResourceRequest(api, principal, "/api/benchmark/test_mod.benchmark.b01"); w.Code != http.StatusOK {
+		t.Errorf("expected status %d for an accessible benchmark, got %d", http.StatusOK, w.Code)
+	}
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the interval at which the certificate files are checked for changes
+const certificateReloadInterval = 10 * time.Second
+
+// TLSConfig configures the API service to serve HTTPS (and WSS) rather than HTTP
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// if set, clients must present a certificate signed by one of the CAs in this PEM file
+	ClientCAFile string
+	// if set, an HTTP server is started on this port which redirects all requests to HTTPS
+	RedirectPort int
+}
+
+// Validate checks the certificate, key and client CA files can be loaded
+func (c *TLSConfig) Validate() error {
+	if c.CertFile == "" || c.KeyFile == "" {
+		return fmt.Errorf("both a TLS certificate and key must be specified")
+	}
+	if _, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile); err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	if c.ClientCAFile != "" {
+		if _, err := loadCertPool(c.ClientCAFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// build returns the tls.Config used by the HTTPS server
+// the certificate is reloaded when the certificate or key file changes, until the context is cancelled
+func (c *TLSConfig) build(ctx context.Context) (*tls.Config, error) {
+	reloader, err := newCertificateReloader(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	go reloader.watch(ctx, certificateReloadInterval)
+
+	res := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.getCertificate,
+	}
+	if c.ClientCAFile != "" {
+		pool, err := loadCertPool(c.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		res.ClientCAs = pool
+		res.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return res, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TLS client CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("TLS client CA file '%s' does not contain any PEM encoded certificates", path)
+	}
+	return pool, nil
+}
+
+// certificateReloader serves a certificate which is reloaded when its files change,
+// so renewed certificates are used without restarting the server
+type certificateReloader struct {
+	certFile string
+	keyFile  string
+
+	certificate *tls.Certificate
+	modTimes    [2]time.Time
+	lock        sync.RWMutex
+}
+
+func newCertificateReloader(certFile, keyFile string) (*certificateReloader, error) {
+	r := &certificateReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certificateReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.certificate, nil
+}
+
+func (r *certificateReloader) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			// if the files are being rewritten the pair may be inconsistent - keep the current
+			// certificate and retry on the next tick
+			if err := r.reload(); err != nil {
+				slog.Warn("failed to reload TLS certificate", "error", err)
+				continue
+			}
+			slog.Info("reloaded TLS certificate", "cert", r.certFile)
+		}
+	}
+}
+
+// changed returns whether the modification time of either file differs from when the certificate was loaded
+func (r *certificateReloader) changed() bool {
+	modTimes, err := r.fileModTimes()
+	if err != nil {
+		return false
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return !modTimes[0].Equal(r.modTimes[0]) || !modTimes[1].Equal(r.modTimes[1])
+}
+
+func (r *certificateReloader) reload() error {
+	modTimes, err := r.fileModTimes()
+	if err != nil {
+		return err
+	}
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.certificate = &certificate
+	r.modTimes = modTimes
+	return nil
+}
+
+func (r *certificateReloader) fileModTimes() ([2]time.Time, error) {
+	var res [2]time.Time
+	for i, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return res, err
+		}
+		res[i] = info.ModTime()
+	}
+	return res, nil
+}
+
+// httpsRedirectHandler redirects requests to the same host and path on the HTTPS port
+func httpsRedirectHandler(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.Trim(host, "[]")
+		target := "https://" + net.JoinHostPort(host, strconv.Itoa(httpsPort)) + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
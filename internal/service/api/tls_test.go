@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCertificate writes a self signed certificate and key for the given common name
+func writeCertificate(t *testing.T, dir, commonName string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, "server.crt")
+	keyFile := filepath.Join(dir, "server.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func certificateCommonName(t *testing.T, r *certificateReloader) string {
+	cert, err := r.getCertificate(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Subject.CommonName
+}
+
+func TestTLSConfigValidate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCertificate(t, dir, "powerpipe")
+
+	if err := (&TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: certFile}).Validate(); err != nil {
+		t.Errorf("expected a valid config, got %s", err)
+	}
+	for name, c := range map[string]*TLSConfig{
+		"no key":            {CertFile: certFile},
+		"missing cert":      {CertFile: filepath.Join(dir, "missing.crt"), KeyFile: keyFile},
+		"key as cert":       {CertFile: keyFile, KeyFile: keyFile},
+		"invalid client ca": {CertFile: certFile, KeyFile: keyFile, ClientCAFile: keyFile},
+	} {
+		if err := c.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestCertificateReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCertificate(t, dir, "first")
+
+	r, err := newCertificateReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := certificateCommonName(t, r); name != "first" {
+		t.Fatalf("expected the first certificate, got %s", name)
+	}
+	if r.changed() {
+		t.Errorf("expected the files to be unchanged")
+	}
+
+	writeCertificate(t, dir, "second")
+	// ensure the modification times differ on file systems with a coarse resolution
+	later := time.Now().Add(time.Minute)
+	for _, f := range []string{certFile, keyFile} {
+		if err := os.Chtimes(f, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !r.changed() {
+		t.Fatalf("expected the files to be changed")
+	}
+	if err := r.reload(); err != nil {
+		t.Fatal(err)
+	}
+	if name := certificateCommonName(t, r); name != "second" {
+		t.Errorf("expected the reloaded certificate, got %s", name)
+	}
+}
+
+func TestHttpsRedirectHandler(t *testing.T) {
+	tests := map[string]string{
+		"localhost:9034": "https://localhost:9033/api/v0/dashboard?tag=a",
+		"powerpipe.corp": "https://powerpipe.corp:9033/api/v0/dashboard?tag=a",
+		"[::1]:9034":     "https://[::1]:9033/api/v0/dashboard?tag=a",
+		"10.0.0.1":       "https://10.0.0.1:9033/api/v0/dashboard?tag=a",
+	}
+	for host, expected := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/api/v0/dashboard?tag=a", nil)
+		r.Host = host
+		w := httptest.NewRecorder()
+		httpsRedirectHandler(9033).ServeHTTP(w, r)
+		if w.Code != http.StatusPermanentRedirect {
+			t.Errorf("%s: expected status %d, got %d", host, http.StatusPermanentRedirect, w.Code)
+		}
+		if location := w.Header().Get("Location"); location != expected {
+			t.Errorf("%s: expected redirect to %s, got %s", host, expected, location)
+		}
+	}
+}
+
+func TestTLSConfigClientCertificates(t *testing.T) {
+	serverCert, serverKey := writeCertificate(t, t.TempDir(), "localhost")
+	clientCert, clientKey := writeCertificate(t, t.TempDir(), "client")
+
+	c := &TLSConfig{CertFile: serverCert, KeyFile: serverKey, ClientCAFile: clientCert}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tlsConfig, err := c.build(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	get := func(certificates ...tls.Certificate) error {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true, //nolint:gosec // the test server certificate is self signed
+			Certificates:       certificates,
+		}}}
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	if err := get(); err == nil {
+		t.Errorf("expected a request without a client certificate to fail")
+	}
+	certificate, err := tls.LoadX509KeyPair(clientCert, clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := get(certificate); err != nil {
+		t.Errorf("expected a request with a client certificate to succeed, got %s", err)
+	}
+}
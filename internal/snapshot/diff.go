@@ -0,0 +1,191 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/v2/schema"
+	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+)
+
+const (
+	PanelAdded    = "added"
+	PanelRemoved  = "removed"
+	PanelModified = "modified"
+)
+
+// PanelDiff is the difference between a panel in two snapshots
+type PanelDiff struct {
+	Name      string   `json:"name"`
+	PanelType string   `json:"panel_type"`
+	Title     string   `json:"title,omitempty"`
+	Change    string   `json:"change"`
+	Details   []string `json:"details,omitempty"`
+}
+
+// Diff compares two snapshots loaded by Load, panel by panel
+// the snapshots must have the same root panel, i.e. be snapshots of the same dashboard or benchmark
+// panels are returned in layout order, with panels only in the second snapshot last
+func Diff(from, to *steampipeconfig.SteampipeSnapshot) ([]*PanelDiff, error) {
+	if from.Layout.Name != to.Layout.Name {
+		return nil, fmt.Errorf("snapshots are not of the same dashboard or benchmark: '%s' and '%s'", from.Layout.Name, to.Layout.Name)
+	}
+
+	var names []string
+	for _, s := range []*steampipeconfig.SteampipeSnapshot{from, to} {
+		for _, name := range layoutNames(s.Layout) {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	var res []*PanelDiff
+	for _, name := range names {
+		fromPanel, inFrom := GetPanel(from, name)
+		toPanel, inTo := GetPanel(to, name)
+		switch {
+		case inFrom && !inTo:
+			res = append(res, &PanelDiff{Name: name, PanelType: fromPanel.PanelType(), Title: fromPanel.Title(), Change: PanelRemoved})
+		case !inFrom && inTo:
+			res = append(res, &PanelDiff{Name: name, PanelType: toPanel.PanelType(), Title: toPanel.Title(), Change: PanelAdded})
+		case inFrom && inTo:
+			if details := diffPanel(fromPanel, toPanel); len(details) > 0 {
+				res = append(res, &PanelDiff{Name: name, PanelType: toPanel.PanelType(), Title: toPanel.Title(), Change: PanelModified, Details: details})
+			}
+		}
+	}
+	return res, nil
+}
+
+func diffPanel(from, to Panel) []string {
+	var details []string
+	for _, property := range []string{"title", "status", "error"} {
+		if a, b := from.stringProperty(property), to.stringProperty(property); a != b {
+			details = append(details, fmt.Sprintf("%s: '%s' → '%s'", property, a, b))
+		}
+	}
+
+	// status counts of benchmarks and controls
+	fromSummary, toSummary := from.StatusSummary(), to.StatusSummary()
+	for _, status := range []string{"ok", "alarm", "info", "skip", "error", "suppressed"} {
+		if a, b := fromSummary[status], toSummary[status]; a != b {
+			details = append(details, fmt.Sprintf("%s: %d → %d", status, a, b))
+		}
+	}
+
+	switch from.PanelType() {
+	case schema.BlockTypeBenchmark:
+		// benchmark results are reported by their controls
+	case schema.BlockTypeControl:
+		details = append(details, diffControlRows(from, to)...)
+	default:
+		details = append(details, diffRows(from, to)...)
+	}
+	return details
+}
+
+// diffControlRows compares control results by resource and dimensions, reporting status changes
+func diffControlRows(from, to Panel) []string {
+	_, fromRows := from.Data()
+	_, toRows := to.Data()
+	fromStatus := make(map[string]string)
+	for _, row := range fromRows {
+		fromStatus[rowKey(controlRowIdentity(row))] = formatValue(row["status"])
+	}
+	var details []string
+	seen := make(map[string]struct{})
+	for _, row := range toRows {
+		key, status := rowKey(controlRowIdentity(row)), formatValue(row["status"])
+		seen[key] = struct{}{}
+		previous, ok := fromStatus[key]
+		switch {
+		case !ok:
+			details = append(details, fmt.Sprintf("+ %s: %s", controlRowLabel(row), status))
+		case previous != status:
+			details = append(details, fmt.Sprintf("~ %s: %s → %s", controlRowLabel(row), previous, status))
+		}
+	}
+	for _, row := range fromRows {
+		key := rowKey(controlRowIdentity(row))
+		if _, ok := seen[key]; !ok {
+			details = append(details, fmt.Sprintf("- %s: %s", controlRowLabel(row), formatValue(row["status"])))
+			seen[key] = struct{}{}
+		}
+	}
+	return details
+}
+
+// controlRowIdentity returns the columns which identify a control result, i.e. the resource and dimensions
+// the status and reason are excluded as they change with the result
+func controlRowIdentity(row map[string]any) map[string]any {
+	res := maps.Clone(row)
+	delete(res, "status")
+	delete(res, "reason")
+	return res
+}
+
+// controlRowLabel returns the resource of a control result, followed by its dimensions, if any
+func controlRowLabel(row map[string]any) string {
+	identity := controlRowIdentity(row)
+	delete(identity, "resource")
+	if len(identity) == 0 {
+		return formatValue(row["resource"])
+	}
+	var dimensions []string
+	for _, k := range slices.Sorted(maps.Keys(identity)) {
+		dimensions = append(dimensions, fmt.Sprintf("%s=%s", k, formatValue(identity[k])))
+	}
+	return fmt.Sprintf("%s (%s)", formatValue(row["resource"]), strings.Join(dimensions, ", "))
+}
+
+// diffRows compares the data of leaf panels, reporting column changes and the number of rows added and removed
+func diffRows(from, to Panel) []string {
+	fromColumns, fromRows := from.Data()
+	toColumns, toRows := to.Data()
+	var details []string
+	if !slices.Equal(fromColumns, toColumns) {
+		details = append(details, fmt.Sprintf("columns: %v → %v", fromColumns, toColumns))
+	}
+
+	// count each distinct row, so duplicate rows are compared correctly
+	counts := make(map[string]int)
+	for _, row := range fromRows {
+		counts[rowKey(row)]++
+	}
+	added := 0
+	for _, row := range toRows {
+		key := rowKey(row)
+		if counts[key] > 0 {
+			counts[key]--
+		} else {
+			added++
+		}
+	}
+	removed := 0
+	for _, c := range counts {
+		removed += c
+	}
+	if added > 0 || removed > 0 {
+		details = append(details, fmt.Sprintf("rows: %d added, %d removed (%d → %d)", added, removed, len(fromRows), len(toRows)))
+	}
+	return details
+}
+
+func rowKey(row map[string]any) string {
+	// json marshals map keys in sorted order
+	res, _ := json.Marshal(row)
+	return string(res)
+}
+
+// layoutNames returns the names of all nodes of the layout, depth first
+func layoutNames(node *steampipeconfig.SnapshotTreeNode) []string {
+	res := []string{node.Name}
+	for _, c := range node.Children {
+		res = append(res, layoutNames(c)...)
+	}
+	return res
+}
@@ -0,0 +1,55 @@
+package snapshot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a, err := Load(writeSnapshot(t, "a.pps", benchmarkSnapshotJson("alarm")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Load(writeSnapshot(t, "b.pps", benchmarkSnapshotJson("ok")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diffs, err := Diff(a, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("expected no differences comparing a snapshot with itself, got %d", len(diffs))
+	}
+
+	diffs, err = Diff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 1 || diffs[0].Name != "mod.control.c1" || diffs[0].Change != PanelModified {
+		t.Fatalf("expected the control to be modified, got %v", diffs)
+	}
+	details := strings.Join(diffs[0].Details, "\n")
+	if !strings.Contains(details, "~ arn2: alarm → ok") {
+		t.Errorf("expected the resource status change to be reported, got:\n%s", details)
+	}
+}
+
+func TestDiffControlRowsDimensions(t *testing.T) {
+	controlPanel := func(east, west string) Panel {
+		return Panel{"data": map[string]any{"rows": []any{
+			map[string]any{"reason": "r", "resource": "arn1", "region": "us-east-1", "status": east},
+			map[string]any{"reason": "r", "resource": "arn1", "region": "us-west-2", "status": west},
+		}}}
+	}
+
+	// rows with the same resource are distinguished by their dimensions
+	details := diffControlRows(controlPanel("ok", "alarm"), controlPanel("ok", "ok"))
+	if len(details) != 1 || details[0] != "~ arn1 (region=us-west-2): alarm → ok" {
+		t.Errorf("expected only the us-west-2 result to change, got %v", details)
+	}
+	if details := diffControlRows(controlPanel("ok", "alarm"), controlPanel("ok", "alarm")); len(details) != 0 {
+		t.Errorf("expected no differences, got %v", details)
+	}
+}
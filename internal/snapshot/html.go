@@ -0,0 +1,228 @@
+package snapshot
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+)
+
+//go:embed templates/dashboard.html.tmpl
+var htmlTemplate string
+
+// htmlNode is a panel of the layout, with the view data for its panel type
+type htmlNode struct {
+	Kind     string
+	Name     string
+	Title    string
+	Error    string
+	Width    int
+	Level    int
+	Children []*htmlNode
+
+	Card    *cardView
+	Table   *tableView
+	Summary []statusCount
+	Content template.HTML
+}
+
+type htmlPage struct {
+	Title     string
+	Root      *htmlNode
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// RenderHtml renders the snapshot as a single self-contained html page
+func RenderHtml(w io.Writer, snapshot *steampipeconfig.SteampipeSnapshot) error {
+	snapshotJson, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return RenderHtmlFromJson(w, snapshotJson, snapshot.Title)
+}
+
+// RenderHtmlFromJson renders the snapshot json as a single self-contained html page
+// if the title is empty, the title of the root panel is used
+func RenderHtmlFromJson(w io.Writer, snapshotJson []byte, title string) error {
+	snapshot, err := parseSnapshotView(snapshotJson)
+	if err != nil {
+		return err
+	}
+
+	root := snapshot.buildNode(snapshot.Layout, 1)
+	if root == nil {
+		return fmt.Errorf("the root panel '%s' is not displayed", snapshot.Layout.Name)
+	}
+	if title == "" {
+		title = root.Title
+	}
+	if title == "" {
+		title = root.Name
+	}
+
+	tmpl, err := template.New("dashboard").Parse(htmlTemplate)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, &htmlPage{
+		Title:     title,
+		Root:      root,
+		StartTime: snapshot.StartTime,
+		EndTime:   snapshot.EndTime,
+	})
+}
+
+// buildNode builds the view data for the layout node and its children
+// panels with display 'none' (and nodes with no panel) are omitted
+func (s *snapshotView) buildNode(layout *steampipeconfig.SnapshotTreeNode, level int) *htmlNode {
+	panel, ok := s.Panels[layout.Name]
+	if !ok || panel.Display == "none" {
+		return nil
+	}
+	node := &htmlNode{
+		Kind:  panel.PanelType,
+		Name:  panel.Name,
+		Title: panel.Title,
+		Error: panel.Error,
+		Width: panel.Width,
+		Level: min(level, 4),
+	}
+	if node.Width <= 0 || node.Width > 12 {
+		node.Width = 12
+	}
+	for _, c := range layout.Children {
+		if child := s.buildNode(c, level+1); child != nil {
+			node.Children = append(node.Children, child)
+		}
+	}
+
+	switch panel.PanelType {
+	case "dashboard", "container":
+		node.Kind = "container"
+	case "benchmark", "control", "detection_benchmark":
+		node.Kind = "check"
+		node.Summary = panel.statusSummary()
+		if panel.Data != nil && len(panel.Data.Rows) > 0 {
+			node.Table = panel.table()
+		}
+	case "card":
+		node.Card = panel.card()
+	case "table", "detection":
+		node.Kind = "table"
+		node.Table = panel.table()
+	case "chart":
+		node.Content = panel.chart()
+	case "text":
+		value := stringProperty(panel.Properties, "value")
+		if panel.DisplayType == "raw" {
+			node.Content = template.HTML("<pre>" + template.HTMLEscapeString(value) + "</pre>") //nolint:gosec // the value is escaped
+		} else {
+			node.Content = renderMarkdown(value)
+		}
+	case "image":
+		node.Content = panel.image()
+	case "input":
+		node.Content = s.input(panel)
+	case "with":
+		return nil
+	default:
+		// graph, flow and hierarchy panels require the dashboard UI to render
+		node.Kind = "unsupported"
+	}
+	return node
+}
+
+func (p *panelView) image() template.HTML {
+	src, alt := p.imageSource()
+	if src == "" {
+		return ""
+	}
+	//nolint:gosec // the attributes are escaped
+	return template.HTML(fmt.Sprintf(`<img src="%s" alt="%s">`, template.HTMLEscapeString(src), template.HTMLEscapeString(alt)))
+}
+
+// input renders the label and selected value of an input
+func (s *snapshotView) input(p *panelView) template.HTML {
+	label, valueString := s.inputValue(p)
+	//nolint:gosec // the values are escaped
+	return template.HTML(fmt.Sprintf(`<span class="input-label">%s</span> <span class="input-value">%s</span>`, template.HTMLEscapeString(label), template.HTMLEscapeString(valueString)))
+}
+
+var (
+	markdownHeading = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	markdownList    = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	markdownBold    = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	markdownCode    = regexp.MustCompile("`([^`]+)`")
+	markdownLink    = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+// renderMarkdown renders the subset of markdown commonly used in text panels
+// (headings, lists, paragraphs, bold, inline code and links) - all text is escaped
+func renderMarkdown(text string) template.HTML {
+	var b strings.Builder
+	var paragraph []string
+	inList := false
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			b.WriteString("<p>" + renderMarkdownInline(strings.Join(paragraph, " ")) + "</p>\n")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if inList {
+			b.WriteString("</ul>\n")
+			inList = false
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flushParagraph()
+			closeList()
+		case markdownHeading.MatchString(trimmed):
+			flushParagraph()
+			closeList()
+			m := markdownHeading.FindStringSubmatch(trimmed)
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", len(m[1]), renderMarkdownInline(m[2]), len(m[1]))
+		case markdownList.MatchString(line):
+			flushParagraph()
+			if !inList {
+				b.WriteString("<ul>\n")
+				inList = true
+			}
+			b.WriteString("<li>" + renderMarkdownInline(markdownList.FindStringSubmatch(line)[1]) + "</li>\n")
+		default:
+			closeList()
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flushParagraph()
+	closeList()
+	return template.HTML(b.String()) //nolint:gosec // the markdown text is escaped
+}
+
+func renderMarkdownInline(text string) string {
+	res := template.HTMLEscapeString(text)
+	res = markdownCode.ReplaceAllString(res, "<code>$1</code>")
+	res = markdownBold.ReplaceAllString(res, "<strong>$1</strong>")
+	res = markdownLink.ReplaceAllStringFunc(res, func(s string) string {
+		m := markdownLink.FindStringSubmatch(s)
+		href := m[2]
+		// only allow web links
+		if !strings.HasPrefix(href, "http://") && !strings.HasPrefix(href, "https://") {
+			return m[1]
+		}
+		return fmt.Sprintf(`<a href="%s">%s</a>`, href, m[1])
+	})
+	return res
+}
@@ -0,0 +1,186 @@
+package snapshot
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strings"
+)
+
+const (
+	chartWidth   = 600.0
+	chartHeight  = 300.0
+	chartPadding = 40.0
+)
+
+var chartColors = []string{"#3b82f6", "#f59e0b", "#10b981", "#ef4444", "#8b5cf6", "#ec4899", "#14b8a6", "#f97316", "#6366f1", "#84cc16"}
+
+// chart renders the chart data as an inline svg
+func (p *panelView) chart() template.HTML {
+	categories, series := p.chartSeries()
+	if len(series) == 0 {
+		return `<div class="empty">No data</div>`
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg viewBox="0 0 %g %g" role="img" xmlns="http://www.w3.org/2000/svg">`, chartWidth, chartHeight)
+	var legend []string
+	switch p.DisplayType {
+	case "pie", "donut":
+		writePieChart(&b, categories, series[0], p.DisplayType == "donut")
+		legend = categories
+	case "bar":
+		writeBarChart(&b, categories, series)
+	case "line", "area":
+		writeLineChart(&b, categories, series, p.DisplayType == "area")
+	default:
+		writeColumnChart(&b, categories, series)
+	}
+	b.WriteString("</svg>")
+	if legend == nil && len(series) > 1 {
+		for _, s := range series {
+			legend = append(legend, s.name)
+		}
+	}
+	writeChartLegend(&b, legend)
+
+	return template.HTML(b.String()) //nolint:gosec // all labels are escaped
+}
+
+// chartScale returns the range of the value axis, which always includes zero
+func chartScale(series []*chartSeries) (float64, float64) {
+	lo, hi := 0.0, 0.0
+	for _, s := range series {
+		for _, v := range s.values {
+			lo = math.Min(lo, v)
+			hi = math.Max(hi, v)
+		}
+	}
+	if lo == hi {
+		hi = lo + 1
+	}
+	return lo, hi
+}
+
+func writeColumnChart(b *strings.Builder, categories []string, series []*chartSeries) {
+	lo, hi := chartScale(series)
+	plotHeight := chartHeight - 2*chartPadding
+	y := func(v float64) float64 { return chartPadding + (hi-v)/(hi-lo)*plotHeight }
+	groupWidth := (chartWidth - 2*chartPadding) / float64(len(categories))
+	barWidth := groupWidth * 0.8 / float64(len(series))
+
+	writeAxis(b, y(0), hi, lo, y)
+	for i, category := range categories {
+		x := chartPadding + float64(i)*groupWidth + groupWidth*0.1
+		for j, s := range series {
+			top, bottom := y(math.Max(s.values[i], 0)), y(math.Min(s.values[i], 0))
+			fmt.Fprintf(b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s: %s</title></rect>`,
+				x+float64(j)*barWidth, top, barWidth, bottom-top, chartColor(j), escape(category), formatValue(s.values[i]))
+		}
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="middle" class="axis-label">%s</text>`, x+groupWidth*0.4, chartHeight-chartPadding/2, escape(category))
+	}
+}
+
+func writeBarChart(b *strings.Builder, categories []string, series []*chartSeries) {
+	lo, hi := chartScale(series)
+	labelWidth := chartWidth / 4
+	plotWidth := chartWidth - labelWidth - chartPadding
+	x := func(v float64) float64 { return labelWidth + (v-lo)/(hi-lo)*plotWidth }
+	groupHeight := (chartHeight - chartPadding) / float64(len(categories))
+	barHeight := groupHeight * 0.8 / float64(len(series))
+
+	fmt.Fprintf(b, `<line x1="%.1f" y1="0" x2="%.1f" y2="%.1f" class="axis"/>`, x(0), x(0), chartHeight-chartPadding)
+	for i, category := range categories {
+		y := float64(i)*groupHeight + groupHeight*0.1
+		for j, s := range series {
+			left, right := x(math.Min(s.values[i], 0)), x(math.Max(s.values[i], 0))
+			fmt.Fprintf(b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s: %s</title></rect>`,
+				left, y+float64(j)*barHeight, right-left, barHeight, chartColor(j), escape(category), formatValue(s.values[i]))
+		}
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="end" dominant-baseline="middle" class="axis-label">%s</text>`, labelWidth-5, y+groupHeight*0.4, escape(category))
+	}
+	fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="middle" class="axis-label">%s</text>`, x(hi), chartHeight-chartPadding/2, formatValue(hi))
+}
+
+func writeLineChart(b *strings.Builder, categories []string, series []*chartSeries, area bool) {
+	lo, hi := chartScale(series)
+	plotHeight := chartHeight - 2*chartPadding
+	y := func(v float64) float64 { return chartPadding + (hi-v)/(hi-lo)*plotHeight }
+	step := (chartWidth - 2*chartPadding) / math.Max(float64(len(categories)-1), 1)
+	x := func(i int) float64 { return chartPadding + float64(i)*step }
+
+	writeAxis(b, y(0), hi, lo, y)
+	for j, s := range series {
+		points := make([]string, len(s.values))
+		for i, v := range s.values {
+			points[i] = fmt.Sprintf("%.1f,%.1f", x(i), y(v))
+		}
+		if area {
+			fmt.Fprintf(b, `<polygon points="%.1f,%.1f %s %.1f,%.1f" fill="%s" fill-opacity="0.3"/>`,
+				x(0), y(0), strings.Join(points, " "), x(len(s.values)-1), y(0), chartColor(j))
+		}
+		fmt.Fprintf(b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`, strings.Join(points, " "), chartColor(j))
+	}
+	for i, category := range categories {
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="middle" class="axis-label">%s</text>`, x(i), chartHeight-chartPadding/2, escape(category))
+	}
+}
+
+func writePieChart(b *strings.Builder, categories []string, series *chartSeries, donut bool) {
+	total := 0.0
+	for _, v := range series.values {
+		total += math.Max(v, 0)
+	}
+	if total == 0 {
+		return
+	}
+	cx, cy, r := chartWidth/2, chartHeight/2, chartHeight/2-10
+	angle := -math.Pi / 2
+	for i, v := range series.values {
+		if v <= 0 {
+			continue
+		}
+		title := fmt.Sprintf("<title>%s: %s</title>", escape(categories[i]), formatValue(v))
+		if v == total {
+			fmt.Fprintf(b, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="%s">%s</circle>`, cx, cy, r, chartColor(i), title)
+			break
+		}
+		end := angle + v/total*2*math.Pi
+		largeArc := 0
+		if end-angle > math.Pi {
+			largeArc = 1
+		}
+		fmt.Fprintf(b, `<path d="M%.1f,%.1f L%.1f,%.1f A%.1f,%.1f 0 %d 1 %.1f,%.1f Z" fill="%s">%s</path>`,
+			cx, cy, cx+r*math.Cos(angle), cy+r*math.Sin(angle), r, r, largeArc, cx+r*math.Cos(end), cy+r*math.Sin(end), chartColor(i), title)
+		angle = end
+	}
+	if donut {
+		fmt.Fprintf(b, `<circle cx="%.1f" cy="%.1f" r="%.1f" class="donut-hole"/>`, cx, cy, r/2)
+	}
+}
+
+func writeAxis(b *strings.Builder, zeroY, hi, lo float64, y func(float64) float64) {
+	fmt.Fprintf(b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" class="axis"/>`, chartPadding, zeroY, chartWidth-chartPadding, zeroY)
+	for _, v := range []float64{lo, hi} {
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="end" dominant-baseline="middle" class="axis-label">%s</text>`, chartPadding-5, y(v), formatValue(v))
+	}
+}
+
+func writeChartLegend(b *strings.Builder, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	b.WriteString(`<div class="legend">`)
+	for i, name := range names {
+		fmt.Fprintf(b, `<span><i style="background:%s"></i>%s</span>`, chartColor(i), escape(name))
+	}
+	b.WriteString(`</div>`)
+}
+
+func chartColor(i int) string {
+	return chartColors[i%len(chartColors)]
+}
+
+func escape(s string) string {
+	return template.HTMLEscapeString(s)
+}
@@ -0,0 +1,36 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/turbot/pipe-fittings/v2/constants"
+	"github.com/turbot/pipe-fittings/v2/export"
+	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+)
+
+// HtmlExporter exports a dashboard snapshot as a single self-contained html report
+type HtmlExporter struct {
+	export.ExporterBase
+}
+
+func (e *HtmlExporter) Export(_ context.Context, input export.ExportSourceData, filePath string) error {
+	snapshot, ok := input.(*steampipeconfig.SteampipeSnapshot)
+	if !ok {
+		return fmt.Errorf("HtmlExporter input must be a SteampipeSnapshot")
+	}
+	var b bytes.Buffer
+	if err := RenderHtml(&b, snapshot); err != nil {
+		return err
+	}
+	return export.Write(filePath, &b)
+}
+
+func (e *HtmlExporter) FileExtension() string {
+	return ".html"
+}
+
+func (e *HtmlExporter) Name() string {
+	return constants.OutputFormatHTML
+}
@@ -0,0 +1,79 @@
+package snapshot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const testSnapshotJson = `{
+  "schema_version": "20240607",
+  "panels": {
+    "mod.dashboard.d1": {"name": "mod.dashboard.d1", "panel_type": "dashboard", "title": "Bucket Report"},
+    "mod.container.c1": {"name": "mod.container.c1", "panel_type": "container", "title": "Overview"},
+    "mod.card.total": {"name": "mod.card.total", "panel_type": "card", "width": 3,
+      "data": {"columns": [{"name": "Buckets", "data_type": "INT8"}], "rows": [{"Buckets": 12}]}},
+    "mod.card.public": {"name": "mod.card.public", "panel_type": "card", "width": 3,
+      "data": {"columns": [{"name": "label"}, {"name": "value"}, {"name": "type"}], "rows": [{"label": "Public", "value": 2, "type": "alert"}]}},
+    "mod.table.buckets": {"name": "mod.table.buckets", "panel_type": "table", "title": "Buckets",
+      "properties": {"columns": {"arn": {"name": "arn", "display": "none"}}},
+      "data": {"columns": [{"name": "name"}, {"name": "arn"}, {"name": "region"}], "rows": [{"name": "<logs>", "arn": "arn:aws:s3:::logs", "region": null}]}},
+    "mod.chart.by_region": {"name": "mod.chart.by_region", "panel_type": "chart", "display_type": "bar",
+      "data": {"columns": [{"name": "region"}, {"name": "total"}], "rows": [{"region": "us-east-1", "total": 8}, {"region": "eu-west-1", "total": 4}]}},
+    "mod.text.intro": {"name": "mod.text.intro", "panel_type": "text", "properties": {"value": "## Notes\n- uses **bold**\n- <script>"}},
+    "mod.graph.g1": {"name": "mod.graph.g1", "panel_type": "graph", "title": "Relationships"},
+    "mod.card.hidden": {"name": "mod.card.hidden", "panel_type": "card", "display": "none", "properties": {"label": "Hidden"}}
+  },
+  "layout": {"name": "mod.dashboard.d1", "panel_type": "dashboard", "children": [
+    {"name": "mod.container.c1", "panel_type": "container", "children": [
+      {"name": "mod.card.total", "panel_type": "card"},
+      {"name": "mod.card.public", "panel_type": "card"},
+      {"name": "mod.card.hidden", "panel_type": "card"}
+    ]},
+    {"name": "mod.table.buckets", "panel_type": "table"},
+    {"name": "mod.chart.by_region", "panel_type": "chart"},
+    {"name": "mod.text.intro", "panel_type": "text"},
+    {"name": "mod.graph.g1", "panel_type": "graph"}
+  ]}
+}`
+
+func TestRenderHtmlFromJson(t *testing.T) {
+	var b bytes.Buffer
+	if err := RenderHtmlFromJson(&b, []byte(testSnapshotJson), ""); err != nil {
+		t.Fatal(err)
+	}
+	html := b.String()
+
+	for _, expected := range []string{
+		"<title>Bucket Report</title>",
+		"<h2>Overview</h2>",
+		`<div class="card-label">Buckets</div>`,
+		`<div class="card-value">12</div>`,
+		`card-alert`,
+		`<div class="card-value">2</div>`,
+		"<th>name</th><th>region</th>",
+		"<td>&lt;logs&gt;</td><td>null</td>",
+		"<svg",
+		"us-east-1",
+		"<h2>Notes</h2>",
+		"<li>uses <strong>bold</strong></li>",
+		"<li>&lt;script&gt;</li>",
+		"not supported in HTML output",
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("expected html to contain %q", expected)
+		}
+	}
+	for _, unexpected := range []string{"arn:aws:s3:::logs", "Hidden", "<script>"} {
+		if strings.Contains(html, unexpected) {
+			t.Errorf("expected html not to contain %q", unexpected)
+		}
+	}
+}
+
+func TestRenderHtmlFromJsonInvalid(t *testing.T) {
+	var b bytes.Buffer
+	if err := RenderHtmlFromJson(&b, []byte(`{"panels": {}}`), ""); err == nil {
+		t.Error("expected an error for a snapshot with no layout")
+	}
+}
@@ -0,0 +1,162 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+)
+
+// Panel is a snapshot panel loaded from a snapshot file
+// panels are deserialized as maps as the run type of each panel is not known
+type Panel map[string]any
+
+// IsSnapshotPanel implements SnapshotPanel
+func (Panel) IsSnapshotPanel() {}
+
+// PanelType returns the panel_type of the panel
+func (p Panel) PanelType() string {
+	return p.stringProperty("panel_type")
+}
+
+// Title returns the title of the panel
+func (p Panel) Title() string {
+	return p.stringProperty("title")
+}
+
+// Data returns the columns and rows of the panel data, if any
+func (p Panel) Data() ([]string, []map[string]any) {
+	data, ok := p["data"].(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+	var columns []string
+	if cols, ok := data["columns"].([]any); ok {
+		for _, c := range cols {
+			if col, ok := c.(map[string]any); ok {
+				if name, ok := col["name"].(string); ok {
+					columns = append(columns, name)
+				}
+			}
+		}
+	}
+	var rows []map[string]any
+	if r, ok := data["rows"].([]any); ok {
+		for _, row := range r {
+			if m, ok := row.(map[string]any); ok {
+				rows = append(rows, m)
+			}
+		}
+	}
+	return columns, rows
+}
+
+// StatusSummary returns the control status counts of a benchmark or control panel
+// a benchmark summary has the counts in a 'status' property, a control summary has them at the top level
+func (p Panel) StatusSummary() map[string]int {
+	summary, ok := p["summary"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	if status, ok := summary["status"].(map[string]any); ok {
+		summary = status
+	}
+	res := make(map[string]int)
+	for k, v := range summary {
+		if f, ok := v.(float64); ok {
+			res[k] = int(f)
+		}
+	}
+	return res
+}
+
+func (p Panel) stringProperty(key string) string {
+	s, _ := p[key].(string)
+	return s
+}
+
+// snapshotHeader is used to convert the properties of a snapshot other than its panels
+type snapshotHeader struct {
+	SchemaVersion string                            `json:"schema_version"`
+	Inputs        map[string]any                    `json:"inputs"`
+	Variables     map[string]string                 `json:"variables"`
+	SearchPath    []string                          `json:"search_path"`
+	StartTime     time.Time                         `json:"start_time"`
+	EndTime       time.Time                         `json:"end_time"`
+	Layout        *steampipeconfig.SnapshotTreeNode `json:"layout"`
+	Metadata      map[string]any                    `json:"metadata"`
+}
+
+// ReadFile reads and deserializes a snapshot file as an interface map
+// we cannot deserialize into a SteampipeSnapshot struct
+// (without custom deserialisation code) as the Panels property is an interface
+func ReadFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	snap := map[string]any{}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot '%s': %w", path, err)
+	}
+	return snap, nil
+}
+
+// Load loads a snapshot file - the panels of the snapshot are of type Panel
+func Load(path string) (*steampipeconfig.SteampipeSnapshot, error) {
+	snap, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	panels, _ := snap["panels"].(map[string]any)
+
+	// convert the remaining properties to their types by round tripping them through json
+	properties := maps.Clone(snap)
+	delete(properties, "panels")
+	var header snapshotHeader
+	data, err := json.Marshal(properties)
+	if err == nil {
+		err = json.Unmarshal(data, &header)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot '%s': %w", path, err)
+	}
+	if header.Layout == nil || panels == nil {
+		return nil, fmt.Errorf("'%s' is not a valid snapshot - no layout or panels found", path)
+	}
+
+	res := &steampipeconfig.SteampipeSnapshot{
+		SchemaVersion: header.SchemaVersion,
+		Panels:        make(map[string]steampipeconfig.SnapshotPanel, len(panels)),
+		Inputs:        header.Inputs,
+		Variables:     header.Variables,
+		SearchPath:    header.SearchPath,
+		StartTime:     header.StartTime,
+		EndTime:       header.EndTime,
+		Layout:        header.Layout,
+		Metadata:      header.Metadata,
+		FileNameRoot:  strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+	}
+	for name, p := range panels {
+		panel, ok := p.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("'%s' is not a valid snapshot - panel '%s' is not an object", path, name)
+		}
+		res.Panels[name] = Panel(panel)
+	}
+	if root, ok := GetPanel(res, header.Layout.Name); ok {
+		res.Title = root.Title()
+	}
+	return res, nil
+}
+
+// GetPanel returns the panel with the given name, if the snapshot was loaded by Load
+func GetPanel(snapshot *steampipeconfig.SteampipeSnapshot, name string) (Panel, bool) {
+	panel, ok := snapshot.Panels[name].(Panel)
+	return panel, ok
+}
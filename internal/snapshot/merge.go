@@ -0,0 +1,166 @@
+package snapshot
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/turbot/pipe-fittings/v2/schema"
+	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+)
+
+// Merge combines snapshots loaded by Load into a single snapshot
+//
+// the snapshots must have the same root panel (e.g. runs of the same benchmark against different connections)
+// the layouts are combined - the result rows of each control are appended and the benchmark summaries are summed
+func Merge(snapshots ...*steampipeconfig.SteampipeSnapshot) (*steampipeconfig.SteampipeSnapshot, error) {
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("no snapshots to merge")
+	}
+	first := snapshots[0]
+	// panels are merged by name, so the same panel under different roots would be counted more than once
+	for _, s := range snapshots[1:] {
+		if s.Layout.Name != first.Layout.Name {
+			return nil, fmt.Errorf("snapshots are not of the same dashboard or benchmark: '%s' and '%s'", first.Layout.Name, s.Layout.Name)
+		}
+	}
+	res := &steampipeconfig.SteampipeSnapshot{
+		SchemaVersion: first.SchemaVersion,
+		Panels:        make(map[string]steampipeconfig.SnapshotPanel),
+		Inputs:        make(map[string]any),
+		Variables:     make(map[string]string),
+		SearchPath:    first.SearchPath,
+		StartTime:     first.StartTime,
+		EndTime:       first.EndTime,
+		Layout:        cloneLayout(first.Layout),
+		Title:         first.Title,
+		FileNameRoot:  first.FileNameRoot,
+		Metadata:      first.Metadata,
+	}
+
+	for i, s := range snapshots {
+		for name, p := range s.Panels {
+			panel, ok := p.(Panel)
+			if !ok {
+				return nil, fmt.Errorf("snapshot %d has an invalid panel '%s'", i+1, name)
+			}
+			existing, ok := res.Panels[name].(Panel)
+			if !ok {
+				res.Panels[name] = maps.Clone(panel)
+				continue
+			}
+			mergePanel(existing, panel)
+		}
+		for k, v := range s.Inputs {
+			if _, ok := res.Inputs[k]; !ok {
+				res.Inputs[k] = v
+			}
+		}
+		for k, v := range s.Variables {
+			if _, ok := res.Variables[k]; !ok {
+				res.Variables[k] = v
+			}
+		}
+		if s.StartTime.Before(res.StartTime) {
+			res.StartTime = s.StartTime
+		}
+		if s.EndTime.After(res.EndTime) {
+			res.EndTime = s.EndTime
+		}
+
+		if i > 0 {
+			mergeLayout(res.Layout, s.Layout)
+		}
+	}
+	return res, nil
+}
+
+// mergePanel combines the results of a panel which exists in more than one snapshot
+// the result rows of controls are appended and the summaries of benchmarks and controls are summed
+// for other panel types the first panel is retained
+func mergePanel(target, source Panel) {
+	switch target.PanelType() {
+	case schema.BlockTypeControl:
+		mergePanelData(target, source)
+		target["summary"] = sumCounts(target["summary"], source["summary"])
+	case schema.BlockTypeBenchmark:
+		if summary, ok := target["summary"].(map[string]any); ok {
+			summary = maps.Clone(summary)
+			if sourceSummary, ok := source["summary"].(map[string]any); ok {
+				summary["status"] = sumCounts(summary["status"], sourceSummary["status"])
+			}
+			target["summary"] = summary
+		}
+	}
+}
+
+func mergePanelData(target, source Panel) {
+	targetColumns, targetRows := target.Data()
+	sourceColumns, sourceRows := source.Data()
+	data := map[string]any{}
+	if d, ok := target["data"].(map[string]any); ok {
+		data = maps.Clone(d)
+	}
+
+	// add any columns (i.e. dimensions) which only exist in the source
+	columns, _ := data["columns"].([]any)
+	columns = slices.Clone(columns)
+	if sourceData, ok := source["data"].(map[string]any); ok {
+		sourceColumnDefs, _ := sourceData["columns"].([]any)
+		for i, c := range sourceColumns {
+			if !slices.Contains(targetColumns, c) && i < len(sourceColumnDefs) {
+				columns = append(columns, sourceColumnDefs[i])
+			}
+		}
+	}
+	rows := make([]any, 0, len(targetRows)+len(sourceRows))
+	for _, r := range targetRows {
+		rows = append(rows, r)
+	}
+	for _, r := range sourceRows {
+		rows = append(rows, r)
+	}
+	data["columns"] = columns
+	data["rows"] = rows
+	target["data"] = data
+}
+
+// sumCounts sums the numeric properties of two summary maps
+func sumCounts(a, b any) map[string]any {
+	res := make(map[string]any)
+	for _, summary := range []any{a, b} {
+		m, ok := summary.(map[string]any)
+		if !ok {
+			continue
+		}
+		for k, v := range m {
+			f, ok := v.(float64)
+			if !ok {
+				continue
+			}
+			existing, _ := res[k].(float64)
+			res[k] = existing + f
+		}
+	}
+	return res
+}
+
+// mergeLayout adds children of the source layout which do not exist in the target layout
+func mergeLayout(target, source *steampipeconfig.SnapshotTreeNode) {
+	for _, sourceChild := range source.Children {
+		idx := slices.IndexFunc(target.Children, func(c *steampipeconfig.SnapshotTreeNode) bool { return c.Name == sourceChild.Name })
+		if idx == -1 {
+			target.Children = append(target.Children, cloneLayout(sourceChild))
+			continue
+		}
+		mergeLayout(target.Children[idx], sourceChild)
+	}
+}
+
+func cloneLayout(node *steampipeconfig.SnapshotTreeNode) *steampipeconfig.SnapshotTreeNode {
+	res := &steampipeconfig.SnapshotTreeNode{Name: node.Name, NodeType: node.NodeType}
+	for _, c := range node.Children {
+		res.Children = append(res.Children, cloneLayout(c))
+	}
+	return res
+}
@@ -0,0 +1,92 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func benchmarkSnapshotJson(alarmStatus string) string {
+	return `{"schema_version": "20240607", "panels": {
+  "mod.benchmark.b1": {"name": "mod.benchmark.b1", "panel_type": "benchmark", "title": "B1", "summary": {"status": {"ok": 1, "alarm": 1}}},
+  "mod.control.c1": {"name": "mod.control.c1", "panel_type": "control", "title": "C1", "summary": {"ok": 1, "alarm": 1},
+    "data": {"columns": [{"name": "reason"}, {"name": "resource"}, {"name": "status"}],
+      "rows": [{"reason": "r1", "resource": "arn1", "status": "ok"}, {"reason": "r2", "resource": "arn2", "status": "` + alarmStatus + `"}]}}
+  },
+  "layout": {"name": "mod.benchmark.b1", "panel_type": "benchmark", "children": [{"name": "mod.control.c1", "panel_type": "control"}]}
+}`
+}
+
+func writeSnapshot(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	s, err := Load(writeSnapshot(t, "b1.pps", benchmarkSnapshotJson("alarm")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Title != "B1" || s.FileNameRoot != "b1" {
+		t.Errorf("expected title 'B1' and file name root 'b1', got '%s' and '%s'", s.Title, s.FileNameRoot)
+	}
+	control, ok := GetPanel(s, "mod.control.c1")
+	if !ok {
+		t.Fatal("expected control panel to be loaded")
+	}
+	if summary := control.StatusSummary(); summary["alarm"] != 1 {
+		t.Errorf("expected 1 alarm, got %v", summary)
+	}
+
+	if _, err := Load(writeSnapshot(t, "invalid.pps", `{"panels": {}}`)); err == nil {
+		t.Error("expected an error loading a snapshot with no layout")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a, err := Load(writeSnapshot(t, "a.pps", benchmarkSnapshotJson("alarm")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Load(writeSnapshot(t, "b.pps", benchmarkSnapshotJson("ok")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merged, err := Merge(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Layout.Name != "mod.benchmark.b1" || len(merged.Layout.Children) != 1 {
+		t.Errorf("expected the benchmark layouts to be combined, got %s with %d children", merged.Layout.Name, len(merged.Layout.Children))
+	}
+	control, _ := GetPanel(merged, "mod.control.c1")
+	if _, rows := control.Data(); len(rows) != 4 {
+		t.Errorf("expected 4 control rows, got %d", len(rows))
+	}
+	if summary := control.StatusSummary(); summary["ok"] != 2 || summary["alarm"] != 2 {
+		t.Errorf("expected control summary ok: 2, alarm: 2, got %v", summary)
+	}
+	benchmark, _ := GetPanel(merged, "mod.benchmark.b1")
+	if summary := benchmark.StatusSummary(); summary["ok"] != 2 || summary["alarm"] != 2 {
+		t.Errorf("expected benchmark summary ok: 2, alarm: 2, got %v", summary)
+	}
+	// the source snapshots are not modified
+	source, _ := GetPanel(a, "mod.control.c1")
+	if _, rows := source.Data(); len(rows) != 2 {
+		t.Errorf("expected the source snapshot to be unchanged, got %d rows", len(rows))
+	}
+
+	// snapshots with different roots are rejected
+	c, err := Load(writeSnapshot(t, "c.pps", strings.ReplaceAll(benchmarkSnapshotJson("ok"), "benchmark.b1", "benchmark.b2")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Merge(a, c); err == nil {
+		t.Error("expected an error merging snapshots with different roots")
+	}
+}
@@ -0,0 +1,84 @@
+package snapshot
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/v2/querydisplay"
+	"github.com/turbot/pipe-fittings/v2/schema"
+	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+)
+
+// Show writes the panel tree of a snapshot loaded by Load, including the data of each panel as a table
+func Show(w io.Writer, snapshot *steampipeconfig.SteampipeSnapshot) error {
+	if !snapshot.EndTime.IsZero() {
+		if _, err := fmt.Fprintf(w, "Snapshot taken %s\n\n", snapshot.EndTime.Format("2006-01-02 15:04:05 MST")); err != nil {
+			return err
+		}
+	}
+	return showNode(w, snapshot, snapshot.Layout, 0)
+}
+
+func showNode(w io.Writer, snapshot *steampipeconfig.SteampipeSnapshot, node *steampipeconfig.SnapshotTreeNode, depth int) error {
+	panel, ok := GetPanel(snapshot, node.Name)
+	if !ok {
+		return fmt.Errorf("snapshot has no panel '%s'", node.Name)
+	}
+	indent := strings.Repeat("  ", depth)
+	heading := fmt.Sprintf("%s%s: %s", indent, panel.PanelType(), node.Name)
+	if title := panel.Title(); title != "" {
+		heading += fmt.Sprintf(" (%s)", title)
+	}
+	if summary := panel.StatusSummary(); summary != nil {
+		heading += "  " + summaryString(summary)
+	}
+	if _, err := fmt.Fprintln(w, heading); err != nil {
+		return err
+	}
+	if e := panel.stringProperty("error"); e != "" {
+		if _, err := fmt.Fprintf(w, "%s  error: %s\n", indent, e); err != nil {
+			return err
+		}
+	}
+
+	switch panel.PanelType() {
+	case schema.BlockTypeDashboard, schema.BlockTypeContainer, schema.BlockTypeBenchmark:
+	case schema.BlockTypeText:
+		properties, _ := panel["properties"].(map[string]any)
+		for _, line := range strings.Split(stringProperty(properties, "value"), "\n") {
+			if _, err := fmt.Fprintf(w, "%s  %s\n", indent, line); err != nil {
+				return err
+			}
+		}
+	default:
+		columns, rows := panel.Data()
+		if len(columns) > 0 {
+			tableRows := make([][]string, len(rows))
+			for i, row := range rows {
+				tableRows[i] = make([]string, len(columns))
+				for j, c := range columns {
+					tableRows[i][j] = formatValue(row[c])
+				}
+			}
+			querydisplay.ShowWrappedTable(columns, tableRows, &querydisplay.ShowWrappedTableOptions{OutputMirror: w})
+		}
+	}
+
+	for _, c := range node.Children {
+		if err := showNode(w, snapshot, c, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func summaryString(summary map[string]int) string {
+	var parts []string
+	for _, status := range []string{"ok", "alarm", "info", "skip", "error", "suppressed"} {
+		if count := summary[status]; count > 0 || status == "ok" || status == "alarm" {
+			parts = append(parts, fmt.Sprintf("%s: %d", status, count))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
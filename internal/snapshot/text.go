@@ -0,0 +1,241 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/turbot/pipe-fittings/v2/querydisplay"
+	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+)
+
+const textBarWidth = 40
+
+var sparklineChars = []rune("▁▂▃▄▅▆▇█")
+
+// RenderText renders the snapshot as text for display in a terminal
+// the panels are written in layout order - cards as label/value pairs, tables as tables,
+// text panels with the markdown stripped and charts as bar charts or sparklines
+func RenderText(w io.Writer, snapshot *steampipeconfig.SteampipeSnapshot) error {
+	snapshotJson, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return RenderTextFromJson(w, snapshotJson)
+}
+
+// RenderTextFromJson renders the snapshot json as text
+func RenderTextFromJson(w io.Writer, snapshotJson []byte) error {
+	view, err := parseSnapshotView(snapshotJson)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	view.writeTextNode(&b, view.Layout, 1)
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func (s *snapshotView) writeTextNode(b *strings.Builder, layout *steampipeconfig.SnapshotTreeNode, level int) {
+	panel, ok := s.Panels[layout.Name]
+	if !ok || panel.Display == "none" || panel.PanelType == "with" {
+		return
+	}
+
+	switch {
+	case level == 1:
+		title := panel.Title
+		if title == "" {
+			title = panel.Name
+		}
+		fmt.Fprintf(b, "%s\n%s\n\n", title, strings.Repeat("=", len([]rune(title))))
+	case panel.PanelType == "container" && panel.Title != "":
+		fmt.Fprintf(b, "%s\n%s\n\n", panel.Title, strings.Repeat("-", len([]rune(panel.Title))))
+	case panel.Title != "" && panel.PanelType != "card":
+		fmt.Fprintf(b, "%s\n", panel.Title)
+	}
+
+	if panel.Error != "" {
+		fmt.Fprintf(b, "Error: %s\n\n", panel.Error)
+	} else {
+		s.writeTextPanel(b, panel)
+	}
+
+	for _, c := range layout.Children {
+		s.writeTextNode(b, c, level+1)
+	}
+}
+
+func (s *snapshotView) writeTextPanel(b *strings.Builder, panel *panelView) {
+	switch panel.PanelType {
+	case "dashboard", "container":
+		return
+	case "benchmark", "control", "detection_benchmark":
+		if summary := panel.statusSummary(); len(summary) > 0 {
+			parts := make([]string, len(summary))
+			for i, c := range summary {
+				parts[i] = fmt.Sprintf("%s: %d", c.Status, c.Count)
+			}
+			fmt.Fprintf(b, "%s\n", strings.Join(parts, ", "))
+		}
+		if panel.Data != nil && len(panel.Data.Rows) > 0 {
+			writeTextTable(b, panel.table())
+		}
+	case "card":
+		card := panel.card()
+		label := card.Label
+		if label == "" {
+			label = panel.Title
+		}
+		fmt.Fprintf(b, "%s: %s", label, card.Value)
+		if card.Type == "alert" || card.Type == "ok" || card.Type == "info" {
+			fmt.Fprintf(b, " (%s)", card.Type)
+		}
+		b.WriteString("\n")
+	case "table", "detection":
+		writeTextTable(b, panel.table())
+	case "chart":
+		writeTextChart(b, panel)
+	case "text":
+		value := stringProperty(panel.Properties, "value")
+		if panel.DisplayType != "raw" {
+			value = stripMarkdown(value)
+		}
+		b.WriteString(strings.TrimRight(value, "\n") + "\n")
+	case "image":
+		src, alt := panel.imageSource()
+		fmt.Fprintf(b, "[image: %s] %s\n", alt, src)
+	case "input":
+		label, value := s.inputValue(panel)
+		fmt.Fprintf(b, "%s: %s\n", label, value)
+	default:
+		fmt.Fprintf(b, "(%s panels cannot be displayed as text)\n", panel.PanelType)
+	}
+	b.WriteString("\n")
+}
+
+func writeTextTable(b *strings.Builder, table *tableView) {
+	if len(table.Columns) == 0 {
+		return
+	}
+	if table.Truncated {
+		defer b.WriteString("(results truncated to the maximum number of rows)\n")
+	}
+	if len(table.Rows) == 0 {
+		b.WriteString("No rows\n")
+		return
+	}
+	if table.Line {
+		width := 0
+		for _, c := range table.Columns {
+			width = max(width, len(c))
+		}
+		for i, row := range table.Rows {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			for j, value := range row {
+				fmt.Fprintf(b, "%-*s | %s\n", width, table.Columns[j], value)
+			}
+		}
+		return
+	}
+	querydisplay.ShowWrappedTable(table.Columns, table.Rows, &querydisplay.ShowWrappedTableOptions{OutputMirror: b})
+}
+
+// writeTextChart renders a line or area chart as a sparkline for each series,
+// and other charts as a bar for each category
+func writeTextChart(b *strings.Builder, panel *panelView) {
+	categories, series := panel.chartSeries()
+	if len(series) == 0 {
+		b.WriteString("No data\n")
+		return
+	}
+
+	switch panel.DisplayType {
+	case "line", "area":
+		nameWidth := 0
+		for _, s := range series {
+			nameWidth = max(nameWidth, len(s.name))
+		}
+		for _, s := range series {
+			fmt.Fprintf(b, "%-*s %s (%s - %s)\n", nameWidth, s.name, sparkline(s.values), formatValue(categories[0]), formatValue(categories[len(categories)-1]))
+		}
+	case "pie", "donut":
+		total := 0.0
+		for _, v := range series[0].values {
+			total += math.Max(v, 0)
+		}
+		writeTextBars(b, categories, series[0].values, func(v float64) string {
+			if total == 0 {
+				return formatValue(v)
+			}
+			return fmt.Sprintf("%s (%.0f%%)", formatValue(v), v/total*100)
+		})
+	default:
+		for i, s := range series {
+			if len(series) > 1 {
+				if i > 0 {
+					b.WriteString("\n")
+				}
+				fmt.Fprintf(b, "%s\n", s.name)
+			}
+			writeTextBars(b, categories, s.values, func(v float64) string { return formatValue(v) })
+		}
+	}
+}
+
+func writeTextBars(b *strings.Builder, categories []string, values []float64, format func(float64) string) {
+	labelWidth := 0
+	maxValue := 0.0
+	for i, c := range categories {
+		labelWidth = max(labelWidth, len([]rune(c)))
+		maxValue = math.Max(maxValue, math.Abs(values[i]))
+	}
+	for i, c := range categories {
+		barLength := 0
+		if maxValue > 0 {
+			barLength = int(math.Round(math.Abs(values[i]) / maxValue * textBarWidth))
+		}
+		fmt.Fprintf(b, "%s%s %s %s\n", c, strings.Repeat(" ", labelWidth-len([]rune(c))), strings.Repeat("█", barLength), format(values[i]))
+	}
+}
+
+func sparkline(values []float64) string {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	res := make([]rune, len(values))
+	for i, v := range values {
+		idx := 0
+		if hi > lo {
+			idx = int((v - lo) / (hi - lo) * float64(len(sparklineChars)-1))
+		}
+		res[i] = sparklineChars[idx]
+	}
+	return string(res)
+}
+
+// stripMarkdown removes the markdown syntax from the subset of markdown rendered by renderMarkdown
+func stripMarkdown(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case markdownHeading.MatchString(trimmed):
+			line = markdownHeading.FindStringSubmatch(trimmed)[2]
+		case markdownList.MatchString(line):
+			line = "• " + markdownList.FindStringSubmatch(line)[1]
+		}
+		line = markdownCode.ReplaceAllString(line, "$1")
+		line = markdownBold.ReplaceAllString(line, "$1")
+		line = markdownLink.ReplaceAllString(line, "$1 ($2)")
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,71 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRenderTextFromJson(t *testing.T) {
+	var b bytes.Buffer
+	if err := RenderTextFromJson(&b, []byte(testSnapshotJson)); err != nil {
+		t.Fatal(err)
+	}
+	text := b.String()
+
+	for _, expected := range []string{
+		"Bucket Report\n=============\n",
+		"Overview\n--------\n",
+		"Buckets: 12\n",
+		"Public: 2 (alert)\n",
+		"<logs>",
+		"us-east-1 " + strings.Repeat("█", textBarWidth) + " 8\n",
+		"eu-west-1 " + strings.Repeat("█", textBarWidth/2) + " 4\n",
+		"Notes\n• uses bold\n• <script>\n",
+		"(graph panels cannot be displayed as text)",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("expected text to contain %q\n%s", expected, text)
+		}
+	}
+	for _, unexpected := range []string{"arn:aws:s3:::logs", "Hidden", "**", "##"} {
+		if strings.Contains(text, unexpected) {
+			t.Errorf("expected text not to contain %q\n%s", unexpected, text)
+		}
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]float64{1, 2, 3, 8}); got != "▁▂▃█" {
+		t.Errorf("expected ▁▂▃█, got %s", got)
+	}
+	if got := sparkline([]float64{5, 5}); got != "▁▁" {
+		t.Errorf("expected ▁▁, got %s", got)
+	}
+}
+
+func TestChartSeriesNonFiniteStrings(t *testing.T) {
+	var p panelView
+	if err := json.Unmarshal([]byte(`{"data": {
+		"columns": [{"name": "region"}, {"name": "count"}],
+		"rows": [{"region": "a", "count": "8"}, {"region": "b", "count": "Inf"}, {"region": "c", "count": "NaN"}]
+	}}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	categories, series := p.chartSeries()
+	if len(series) != 1 {
+		t.Fatalf("expected 1 series, got %d", len(series))
+	}
+	// non-finite values are not charted
+	for i, expected := range []float64{8, 0, 0} {
+		if series[0].values[i] != expected {
+			t.Errorf("expected value %d to be %v, got %v", i, expected, series[0].values[i])
+		}
+	}
+
+	// rendering the chart as text must not fail
+	var b strings.Builder
+	writeTextBars(&b, categories, series[0].values, func(f float64) string { return fmt.Sprintf("%v", f) })
+}
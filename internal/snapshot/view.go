@@ -0,0 +1,273 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/turbot/pipe-fittings/v2/steampipeconfig"
+)
+
+// snapshotView is the subset of the snapshot json used to render a snapshot as html or text
+// the snapshot is rendered from json so that loaded snapshot files can be rendered as well as executed dashboards
+type snapshotView struct {
+	Panels    map[string]*panelView             `json:"panels"`
+	Inputs    map[string]any                    `json:"inputs"`
+	StartTime time.Time                         `json:"start_time"`
+	EndTime   time.Time                         `json:"end_time"`
+	Layout    *steampipeconfig.SnapshotTreeNode `json:"layout"`
+}
+
+type panelView struct {
+	Name          string          `json:"name"`
+	PanelType     string          `json:"panel_type"`
+	Title         string          `json:"title"`
+	Description   string          `json:"description"`
+	Display       string          `json:"display"`
+	DisplayType   string          `json:"display_type"`
+	Width         int             `json:"width"`
+	Error         string          `json:"error"`
+	Properties    map[string]any  `json:"properties"`
+	Data          *panelData      `json:"data"`
+	Summary       json.RawMessage `json:"summary"`
+	Documentation string          `json:"documentation"`
+}
+
+type panelData struct {
+	Columns []struct {
+		Name string `json:"name"`
+	} `json:"columns"`
+	Rows      []map[string]any `json:"rows"`
+	Truncated bool             `json:"truncated"`
+}
+
+type cardView struct {
+	Label string
+	Value string
+	Type  string
+	Href  string
+}
+
+type tableView struct {
+	Columns []string
+	Rows    [][]string
+	// tables with display_type line render each row as a list of name/value pairs
+	Line bool
+	// set if the query result was truncated to the maximum number of rows
+	Truncated bool
+}
+
+type statusCount struct {
+	Status string
+	Count  int
+}
+
+func (p *panelView) card() *cardView {
+	card := &cardView{
+		Label: stringProperty(p.Properties, "label"),
+		Value: stringProperty(p.Properties, "value"),
+		Type:  p.DisplayType,
+		Href:  stringProperty(p.Properties, "href"),
+	}
+	if p.Data != nil && len(p.Data.Rows) > 0 {
+		row := p.Data.Rows[0]
+		// a card query returns either a single column (the column name is the label)
+		// or label, value and type columns
+		if len(p.Data.Columns) == 1 {
+			card.Label = p.Data.Columns[0].Name
+			card.Value = formatValue(row[card.Label])
+		} else {
+			if v, ok := row["label"]; ok {
+				card.Label = formatValue(v)
+			}
+			if v, ok := row["value"]; ok {
+				card.Value = formatValue(v)
+			}
+			if v, ok := row["type"].(string); ok {
+				card.Type = v
+			}
+		}
+	}
+	return card
+}
+
+func (p *panelView) table() *tableView {
+	table := &tableView{Line: p.DisplayType == "line"}
+	if p.Data == nil {
+		return table
+	}
+	table.Truncated = p.Data.Truncated
+	columnProperties, _ := p.Properties["columns"].(map[string]any)
+	for _, c := range p.Data.Columns {
+		if props, ok := columnProperties[c.Name].(map[string]any); ok && props["display"] == "none" {
+			continue
+		}
+		table.Columns = append(table.Columns, c.Name)
+	}
+	for _, row := range p.Data.Rows {
+		values := make([]string, len(table.Columns))
+		for i, c := range table.Columns {
+			values[i] = formatValue(row[c])
+		}
+		table.Rows = append(table.Rows, values)
+	}
+	return table
+}
+
+// statusSummary returns the control status counts for a benchmark or control
+// a benchmark summary has the counts in a 'status' property, a control summary has them at the top level
+func (p *panelView) statusSummary() []statusCount {
+	if len(p.Summary) == 0 {
+		return nil
+	}
+	var summary struct {
+		Status map[string]int `json:"status"`
+	}
+	var counts map[string]int
+	if err := json.Unmarshal(p.Summary, &summary); err == nil && summary.Status != nil {
+		counts = summary.Status
+	} else {
+		var flat map[string]any
+		if err := json.Unmarshal(p.Summary, &flat); err != nil {
+			return nil
+		}
+		counts = make(map[string]int)
+		for k, v := range flat {
+			if f, ok := v.(float64); ok {
+				counts[k] = int(f)
+			}
+		}
+	}
+	var res []statusCount
+	for _, status := range []string{"ok", "alarm", "info", "skip", "error", "suppressed"} {
+		if count, ok := counts[status]; ok {
+			res = append(res, statusCount{Status: status, Count: count})
+		}
+	}
+	return res
+}
+
+func stringProperty(properties map[string]any, key string) string {
+	v, ok := properties[key]
+	if !ok || v == nil {
+		return ""
+	}
+	return formatValue(v)
+}
+
+func formatValue(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		res, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Sprintf("%v", t)
+		}
+		return string(res)
+	}
+}
+
+func toFloat(v any) (float64, bool) {
+	var f float64
+	switch t := v.(type) {
+	case float64:
+		f = t
+	case string:
+		var err error
+		if f, err = strconv.ParseFloat(t, 64); err != nil {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	// NaN and infinite values cannot be charted
+	return f, !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+type chartSeries struct {
+	name   string
+	values []float64
+}
+
+// parseSnapshotView parses the snapshot json
+func parseSnapshotView(snapshotJson []byte) (*snapshotView, error) {
+	var res snapshotView
+	if err := json.Unmarshal(snapshotJson, &res); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %s", err.Error())
+	}
+	if res.Layout == nil {
+		return nil, fmt.Errorf("snapshot has no layout")
+	}
+	if _, ok := res.Panels[res.Layout.Name]; !ok {
+		return nil, fmt.Errorf("snapshot has no panel '%s'", res.Layout.Name)
+	}
+	return &res, nil
+}
+
+// inputValue returns the label and selected value of an input
+func (s *snapshotView) inputValue(p *panelView) (string, string) {
+	label := stringProperty(p.Properties, "label")
+	if label == "" {
+		label = p.Title
+	}
+	value, ok := s.Inputs[p.Name]
+	if !ok {
+		value, ok = s.Inputs[stringProperty(p.Properties, "unqualified_name")]
+	}
+	if !ok {
+		return label, "(not set)"
+	}
+	return label, formatValue(value)
+}
+
+// imageSource returns the src and alt of an image, from the query data if there is any
+func (p *panelView) imageSource() (string, string) {
+	src := stringProperty(p.Properties, "src")
+	alt := stringProperty(p.Properties, "alt")
+	if p.Data != nil && len(p.Data.Rows) > 0 {
+		if v, ok := p.Data.Rows[0]["src"]; ok {
+			src = formatValue(v)
+		}
+		if v, ok := p.Data.Rows[0]["alt"]; ok {
+			alt = formatValue(v)
+		}
+	}
+	return src, alt
+}
+
+// chartSeries returns the categories and series of the chart data
+// the first column of the data is the category, each remaining numeric column is a series
+func (p *panelView) chartSeries() ([]string, []*chartSeries) {
+	if p.Data == nil || len(p.Data.Columns) < 2 || len(p.Data.Rows) == 0 {
+		return nil, nil
+	}
+	categoryColumn := p.Data.Columns[0].Name
+	categories := make([]string, len(p.Data.Rows))
+	for i, row := range p.Data.Rows {
+		categories[i] = formatValue(row[categoryColumn])
+	}
+	var series []*chartSeries
+	for _, c := range p.Data.Columns[1:] {
+		s := &chartSeries{name: c.Name, values: make([]float64, len(p.Data.Rows))}
+		numeric := false
+		for i, row := range p.Data.Rows {
+			if f, ok := toFloat(row[c.Name]); ok {
+				s.values[i] = f
+				numeric = true
+			}
+		}
+		if numeric {
+			series = append(series, s)
+		}
+	}
+	return categories, series
+}
@@ -40,3 +40,32 @@ type WebhookRequestQuery struct {
 type PipelineRequestQuery struct {
 	ExecutionMode *string `json:"execution_mode" form:"execution_mode" binding:"omitempty,oneof=synchronous asynchronous"`
 }
+
+type ResourceRequestURI struct {
+	Name string `uri:"name" binding:"required"`
+}
+
+// CheckRunRequestBody defines the optional control filters for a benchmark or control run.
+// These behave the same as the '--where' and '--tag' args of 'powerpipe benchmark run'
+type CheckRunRequestBody struct {
+	Where string   `json:"where,omitempty"`
+	Tags  []string `json:"tag,omitempty"`
+}
+
+type ExecutionRequestURI struct {
+	ExecutionId string `uri:"execution_id" binding:"required" format:"^exec_[0-9a-v]{20}$"`
+}
+
+type ExecutionOutputRequestQuery struct {
+	Format *string `json:"format" form:"format" binding:"omitempty,oneof=json csv snapshot pps"`
+}
+
+type ResourceListRequestQuery struct {
+	// tag filters, in the same format as the '--tag' arg, e.g. 'service=aws'
+	Tags []string `json:"tag,omitempty" form:"tag" binding:"omitempty"`
+}
+
+type ListResponse[T any] struct {
+	Items     []T     `json:"items"`
+	NextToken *string `json:"next_token,omitempty"`
+}